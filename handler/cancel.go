@@ -0,0 +1,205 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/traP-jp/members_bot/model"
+	"github.com/traP-jp/members_bot/repository"
+	"github.com/traPtitech/traq-ws-bot/payload"
+)
+
+const cancelCommandUsage = "`@BOT_traP-jp /(cancel|取り消し) <申請メッセージのURL>|<GitHubID> [--revoke]`"
+
+func cancelCommandMessage(message string) string {
+	return fmt.Sprintf("%s\n%s", message, cancelCommandUsage)
+}
+
+// 取り消せる招待の状態
+var cancellableStatuses = []model.InvitationStatus{
+	model.InvitationStatusPending,
+	model.InvitationStatusSendFailed,
+	model.InvitationStatusSent,
+}
+
+// cancel は、招待の申請を取り消す。申請者かadminだけが取り消せる。
+// 既にGitHubの招待を送信している場合は、--revoke が指定されたときだけGitHubの招待も取り消す
+func (h *BotHandler) cancel(p *payload.MessageCreated) {
+	ctx := context.Background()
+
+	mentionRawText, _ := checkIfBotMentioned(p, h.botUser.ID())
+	splitText := regexp.MustCompile(`\s+`).Split(strings.TrimSpace(strings.Replace(p.Message.PlainText, mentionRawText, "", 1)), -1)
+
+	if len(splitText) > 1 && slices.Contains([]string{"-h", "-help", "--help"}, splitText[1]) {
+		_, err := h.traqClient.PostMessage(ctx, p.Message.ChannelID,
+			cancelCommandMessage("/cancel は、招待の申請を取り消すためのコマンドです。申請者とadminが使えます。"))
+		if err != nil {
+			logger.Println("failed to post message: ", err)
+		}
+		return
+	}
+
+	args := slices.DeleteFunc(slices.Clone(splitText[1:]), func(arg string) bool {
+		return arg == "--revoke"
+	})
+	revoke := len(args) < len(splitText)-1
+	if len(args) != 1 {
+		_, err := h.traqClient.PostMessage(ctx, p.Message.ChannelID, cancelCommandMessage("引数の数が合いません"))
+		if err != nil {
+			logger.Println("failed to post message: ", err)
+		}
+		return
+	}
+
+	invitations, err := h.cancellableInvitations(ctx, args[0])
+	if err != nil {
+		logger.Println("failed to get invitations: ", err)
+		return
+	}
+	if len(invitations) == 0 {
+		_, err := h.traqClient.PostMessage(ctx, p.Message.ChannelID, "取り消せる申請が見つかりません")
+		if err != nil {
+			logger.Println("failed to post message: ", err)
+		}
+		return
+	}
+
+	isRequester := !slices.ContainsFunc(invitations, func(inv *model.Invitation) bool {
+		return inv.Requester() == nil || inv.Requester().ID() != p.Message.User.ID
+	})
+	if !isRequester {
+		isAdmin, err := h.isAdmin(ctx, p.Message.User.ID)
+		if err != nil {
+			logger.Println("failed to check admin: ", err)
+			return
+		}
+		if !isAdmin {
+			_, err := h.traqClient.PostMessage(ctx, p.Message.ChannelID, "申請を取り消せるのは、申請者とadminのみです")
+			if err != nil {
+				logger.Println("failed to post message: ", err)
+			}
+			return
+		}
+	}
+
+	var gitHubInvitations []*model.GitHubInvitation
+	if revoke && slices.ContainsFunc(invitations, func(inv *model.Invitation) bool {
+		return inv.Status() == model.InvitationStatusSent
+	}) {
+		gitHubInvitations, err = h.githubClient.ListPendingInvitations(ctx)
+		if err != nil {
+			logger.Println("failed to list pending invitations: ", err)
+			return
+		}
+	}
+
+	var cancelledMessage, needRevokeMessage, notFoundMessage, revokeFailedMessage string
+	stampedMessageIDs := make([]string, 0, len(invitations))
+	for _, inv := range invitations {
+		if revoke && inv.Status() == model.InvitationStatusSent {
+			// GitHubの招待はユーザー名で照合するので、招待した後に変わったユーザー名を反映しておく
+			inv = h.refreshGitHubLogin(ctx, inv)
+		}
+		line := fmt.Sprintf("@%s (%s)", strings.TrimPrefix(inv.TraqID(), "@"), inv.GitHubID())
+
+		if inv.Status() == model.InvitationStatusSent {
+			if !revoke {
+				needRevokeMessage += line + "\n"
+				continue
+			}
+
+			idx := slices.IndexFunc(gitHubInvitations, func(gi *model.GitHubInvitation) bool {
+				return strings.EqualFold(gi.Login(), inv.GitHubID())
+			})
+			if idx < 0 {
+				// 既に承諾されたか、期限切れになっている
+				notFoundMessage += line + "\n"
+				continue
+			}
+
+			err := h.githubClient.CancelInvitation(ctx, gitHubInvitations[idx].ID())
+			if err != nil {
+				logger.Println("failed to cancel GitHub invitation: ", err)
+				revokeFailedMessage += line + "\n"
+				continue
+			}
+			line += " GitHubの招待も取り消しました"
+		}
+
+		err := h.ir.UpdateInvitationStatusByGitHubID(ctx, inv.MessageID(), inv.GitHubID(), model.InvitationStatusCancelled)
+		if err != nil {
+			logger.Println("failed to update invitation status: ", err)
+			continue
+		}
+		cancelledMessage += line + "\n"
+
+		// 判定されないように、申請中だった招待のメッセージには操作を終えたスタンプを押す
+		if inv.Status() == model.InvitationStatusPending && !slices.Contains(stampedMessageIDs, inv.MessageID()) {
+			stampedMessageIDs = append(stampedMessageIDs, inv.MessageID())
+			err := h.traqClient.AddStamp(ctx, inv.MessageID(), h.inactiveStampID, 1)
+			if err != nil {
+				logger.Println("failed to add stamp: ", err)
+			}
+			h.updateProgress(ctx, inv.MessageID(), "🚫 申請が取り消されました")
+		}
+	}
+
+	message := ""
+	if cancelledMessage != "" {
+		message += "招待の申請を取り消しました\n" + cancelledMessage
+	}
+	if needRevokeMessage != "" {
+		message += "GitHubの招待が既に送信されています。招待も取り消す場合は `--revoke` を付けてください\n" + needRevokeMessage
+	}
+	if notFoundMessage != "" {
+		message += "GitHubの招待が見つからないため、取り消せませんでした\n" + notFoundMessage
+	}
+	if revokeFailedMessage != "" {
+		message += "GitHubの招待を取り消せませんでした。もう一度試してください\n" + revokeFailedMessage
+	}
+	if message == "" {
+		message = "申請を取り消せませんでした"
+	}
+
+	_, err = h.traqClient.PostMessage(ctx, p.Message.ChannelID, message)
+	if err != nil {
+		logger.Println("failed to post message: ", err)
+	}
+}
+
+// cancellableInvitations は、申請メッセージのURLかGitHub IDで指定された、取り消せる招待を返す。
+// GitHub IDで指定された場合は、最後に申請されたものを返す
+func (h *BotHandler) cancellableInvitations(ctx context.Context, target string) ([]*model.Invitation, error) {
+	if matches := messageURLPattern.FindStringSubmatch(target); matches != nil {
+		invitations, err := h.ir.GetInvitations(ctx, strings.ToLower(matches[1]))
+		if errors.Is(err, repository.ErrRecordNotFound) {
+			return nil, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		return slices.DeleteFunc(invitations, func(inv *model.Invitation) bool {
+			return !slices.Contains(cancellableStatuses, inv.Status())
+		}), nil
+	}
+
+	invitations, err := h.ir.GetInvitationsByStatus(ctx, cancellableStatuses...)
+	if err != nil {
+		return nil, err
+	}
+
+	invitations, err = h.filterByGitHubUser(ctx, invitations, target)
+	if err != nil {
+		return nil, err
+	}
+	if len(invitations) == 0 {
+		return nil, nil
+	}
+
+	return invitations[len(invitations)-1:], nil
+}
@@ -0,0 +1,256 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/traP-jp/members_bot/model"
+	repomock "github.com/traP-jp/members_bot/repository/mock"
+	"github.com/traP-jp/members_bot/service/mock"
+	"github.com/traPtitech/traq-ws-bot/payload"
+)
+
+func TestCancel(t *testing.T) {
+	t.Parallel()
+
+	botUserID := uuid.NewString()
+	adminID := uuid.NewString()
+	requesterID := uuid.NewString()
+	messageID := uuid.NewString()
+	origin := model.WithOrigin(model.NewUser(requesterID, "requester"), uuid.NewString(), uuid.NewString())
+
+	type test struct {
+		plainText          string
+		userID             string
+		invitations        []*model.Invitation
+		pendingInvitations []*model.GitHubInvitation
+		cancelledGitHubIDs []string
+		revokedIDs         []int64
+		cancelErr          error
+		stamped            bool
+		postText           string
+	}
+
+	testCases := map[string]test{
+		"申請者が申請中の招待をメッセージのURLで取り消す": {
+			plainText: "@BOT_traP-jp /cancel https://q.trap.jp/messages/" + messageID,
+			userID:    requesterID,
+			invitations: []*model.Invitation{
+				model.NewInvitation(messageID, "@ikura-hamu", "ikura-hamu", origin),
+			},
+			cancelledGitHubIDs: []string{"ikura-hamu"},
+			stamped:            true,
+			postText:           "招待の申請を取り消しました\n@ikura-hamu (ikura-hamu)\n",
+		},
+		"adminがGitHub IDで取り消す": {
+			plainText: "@BOT_traP-jp /取り消し IKURA-HAMU",
+			userID:    adminID,
+			invitations: []*model.Invitation{
+				model.NewInvitation(messageID, "@ikura-hamu", "ikura-hamu", origin),
+			},
+			cancelledGitHubIDs: []string{"ikura-hamu"},
+			stamped:            true,
+			postText:           "招待の申請を取り消しました\n@ikura-hamu (ikura-hamu)\n",
+		},
+		"送信済みの招待は--revokeが必要": {
+			plainText: "@BOT_traP-jp /cancel ikura-hamu",
+			userID:    requesterID,
+			invitations: []*model.Invitation{
+				model.NewInvitation(messageID, "@ikura-hamu", "ikura-hamu", origin, model.WithStatus(model.InvitationStatusSent)),
+			},
+			postText: "GitHubの招待が既に送信されています。招待も取り消す場合は `--revoke` を付けてください\n@ikura-hamu (ikura-hamu)\n",
+		},
+		"送信済みの招待を--revokeで取り消す": {
+			plainText: "@BOT_traP-jp /cancel --revoke ikura-hamu",
+			userID:    requesterID,
+			invitations: []*model.Invitation{
+				model.NewInvitation(messageID, "@ikura-hamu", "ikura-hamu", origin, model.WithStatus(model.InvitationStatusSent)),
+			},
+			pendingInvitations: []*model.GitHubInvitation{
+				model.NewGitHubInvitation(1, "H1rono", time.Now(), time.Time{}, ""),
+				model.NewGitHubInvitation(2, "Ikura-Hamu", time.Now(), time.Time{}, ""),
+			},
+			cancelledGitHubIDs: []string{"ikura-hamu"},
+			revokedIDs:         []int64{2},
+			postText:           "招待の申請を取り消しました\n@ikura-hamu (ikura-hamu) GitHubの招待も取り消しました\n",
+		},
+		"ユーザー名が変わった人の送信済みの招待を取り消す": {
+			plainText: "@BOT_traP-jp /cancel --revoke ikura-hamu",
+			userID:    requesterID,
+			invitations: []*model.Invitation{
+				model.NewInvitation(messageID, "@ikura-hamu", "old-name", origin, model.WithStatus(model.InvitationStatusSent), model.WithGitHubUserID(1001)),
+			},
+			pendingInvitations: []*model.GitHubInvitation{
+				model.NewGitHubInvitation(3, "ikura-hamu", time.Now(), time.Time{}, ""),
+			},
+			cancelledGitHubIDs: []string{"ikura-hamu"},
+			revokedIDs:         []int64{3},
+			postText:           "招待の申請を取り消しました\n@ikura-hamu (ikura-hamu) GitHubの招待も取り消しました\n",
+		},
+		"GitHubの招待が見つからない": {
+			plainText: "@BOT_traP-jp /cancel ikura-hamu --revoke",
+			userID:    requesterID,
+			invitations: []*model.Invitation{
+				model.NewInvitation(messageID, "@ikura-hamu", "ikura-hamu", origin, model.WithStatus(model.InvitationStatusSent)),
+			},
+			postText: "GitHubの招待が見つからないため、取り消せませんでした\n@ikura-hamu (ikura-hamu)\n",
+		},
+		"GitHubの招待を取り消せない": {
+			plainText: "@BOT_traP-jp /cancel ikura-hamu --revoke",
+			userID:    requesterID,
+			invitations: []*model.Invitation{
+				model.NewInvitation(messageID, "@ikura-hamu", "ikura-hamu", origin, model.WithStatus(model.InvitationStatusSent)),
+			},
+			pendingInvitations: []*model.GitHubInvitation{
+				model.NewGitHubInvitation(3, "ikura-hamu", time.Now(), time.Time{}, ""),
+			},
+			revokedIDs: []int64{3},
+			cancelErr:  errors.New("cancel invitation error"),
+			postText:   "GitHubの招待を取り消せませんでした。もう一度試してください\n@ikura-hamu (ikura-hamu)\n",
+		},
+		"送信に失敗した招待は--revokeなしで取り消せる": {
+			plainText: "@BOT_traP-jp /cancel ikura-hamu",
+			userID:    requesterID,
+			invitations: []*model.Invitation{
+				model.NewInvitation(messageID, "@ikura-hamu", "ikura-hamu", origin, model.WithStatus(model.InvitationStatusSendFailed)),
+			},
+			cancelledGitHubIDs: []string{"ikura-hamu"},
+			postText:           "招待の申請を取り消しました\n@ikura-hamu (ikura-hamu)\n",
+		},
+		"申請者でもadminでもない": {
+			plainText: "@BOT_traP-jp /cancel ikura-hamu",
+			userID:    uuid.NewString(),
+			invitations: []*model.Invitation{
+				model.NewInvitation(messageID, "@ikura-hamu", "ikura-hamu", origin),
+			},
+			postText: "申請を取り消せるのは、申請者とadminのみです",
+		},
+		"判定済みの招待は取り消せない": {
+			plainText: "@BOT_traP-jp /cancel " + messageID,
+			userID:    requesterID,
+			invitations: []*model.Invitation{
+				model.NewInvitation(messageID, "@ikura-hamu", "ikura-hamu", origin, model.WithStatus(model.InvitationStatusRejected)),
+			},
+			postText: "取り消せる申請が見つかりません",
+		},
+		"引数の数が合わない": {
+			plainText: "@BOT_traP-jp /cancel --revoke",
+			userID:    requesterID,
+			postText:  cancelCommandMessage("引数の数が合いません"),
+		},
+	}
+
+	for name, test := range testCases {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			traqMock := &mock.TraqMock{
+				PostMessageFunc: func(context.Context, string, string) (string, error) {
+					return "", nil
+				},
+				GetGroupMemberIDsFunc: func(context.Context, string) ([]string, error) {
+					return []string{adminID}, nil
+				},
+				AddStampFunc: func(context.Context, string, string, int) error {
+					return nil
+				},
+				GetMessageFunc: func(context.Context, string) (string, error) {
+					return "申請", nil
+				},
+				EditMessageFunc: func(context.Context, string, string) error {
+					return nil
+				},
+			}
+			gitHubMock := &mock.GitHubMock{
+				ListPendingInvitationsFunc: func(context.Context) ([]*model.GitHubInvitation, error) {
+					return test.pendingInvitations, nil
+				},
+				CancelInvitationFunc: func(context.Context, int64) error {
+					return test.cancelErr
+				},
+				GetUserIDFunc: func(_ context.Context, userID string) (int64, error) {
+					return map[string]int64{"ikura-hamu": 1001}[userID], nil
+				},
+				GetUserLoginFunc: func(_ context.Context, gitHubUserID int64) (string, error) {
+					return map[int64]string{1001: "ikura-hamu"}[gitHubUserID], nil
+				},
+			}
+			invRepoMock := &repomock.InvitationMock{
+				GetInvitationsFunc: func(context.Context, string) ([]*model.Invitation, error) {
+					return test.invitations, nil
+				},
+				GetInvitationsByStatusFunc: func(_ context.Context, statuses ...model.InvitationStatus) ([]*model.Invitation, error) {
+					return test.invitations, nil
+				},
+				UpdateInvitationStatusByGitHubIDFunc: func(context.Context, string, string, model.InvitationStatus) error {
+					return nil
+				},
+				UpdateInvitationGitHubIDFunc: func(context.Context, int64, string) error {
+					return nil
+				},
+			}
+			alrMock := &repomock.AccountLinkMock{
+				UpdateAccountLinkGitHubLoginFunc: func(context.Context, int64, string) error {
+					return nil
+				},
+			}
+
+			bh := &BotHandler{
+				traqClient:   traqMock,
+				githubClient: gitHubMock,
+				ir:           invRepoMock,
+				alr:          alrMock,
+				botUser:      model.NewUser(botUserID, "BOT_traP-jp"),
+				Config: &Config{
+					botChannelID:    "botChannelID",
+					inactiveStampID: "inactiveStampID",
+					adminGroupID:    uuid.NewString(),
+				},
+			}
+
+			bh.cancel(&payload.MessageCreated{
+				Message: payload.Message{
+					PlainText: test.plainText,
+					ID:        uuid.NewString(),
+					ChannelID: "channelID",
+					Embedded:  []payload.EmbeddedInfo{{Type: "user", Raw: "@BOT_traP-jp", ID: botUserID}},
+					User:      payload.User{ID: test.userID},
+				},
+			})
+
+			updateCalls := invRepoMock.UpdateInvitationStatusByGitHubIDCalls()
+			require.Len(t, updateCalls, len(test.cancelledGitHubIDs))
+			for i, gitHubID := range test.cancelledGitHubIDs {
+				assert.Equal(t, messageID, updateCalls[i].InvitationID)
+				assert.Equal(t, gitHubID, updateCalls[i].GitHubID)
+				assert.Equal(t, model.InvitationStatusCancelled, updateCalls[i].Status)
+			}
+
+			cancelCalls := gitHubMock.CancelInvitationCalls()
+			require.Len(t, cancelCalls, len(test.revokedIDs))
+			for i, id := range test.revokedIDs {
+				assert.Equal(t, id, cancelCalls[i].InvitationID)
+			}
+
+			if test.stamped {
+				require.Len(t, traqMock.AddStampCalls(), 1)
+				assert.Equal(t, messageID, traqMock.AddStampCalls()[0].MessageID)
+				assert.Equal(t, "inactiveStampID", traqMock.AddStampCalls()[0].StampID)
+				require.Len(t, traqMock.EditMessageCalls(), 1)
+				assert.Equal(t, "申請\n\n---\n🚫 申請が取り消されました", traqMock.EditMessageCalls()[0].Text)
+			} else {
+				assert.Empty(t, traqMock.AddStampCalls())
+			}
+
+			postMessageCalls := traqMock.PostMessageCalls()
+			require.Len(t, postMessageCalls, 1)
+			assert.Equal(t, "channelID", postMessageCalls[0].ChannelID)
+			assert.Equal(t, test.postText, postMessageCalls[0].Text)
+		})
+	}
+}
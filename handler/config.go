@@ -2,8 +2,19 @@ package handler
 
 import (
 	"errors"
+	"fmt"
 	"os"
 	"strconv"
+	"time"
+)
+
+const (
+	// 申請中の招待を期限切れにするまでの期間のデフォルト値
+	defaultPendingExpiry = 30 * 24 * time.Hour
+	// 申請中の招待をadminにリマインドするまでの期間のデフォルト値
+	defaultReminderAfter = 3 * 24 * time.Hour
+	// 同じ招待をリマインドする間隔のデフォルト値
+	defaultReminderInterval = 24 * time.Hour
 )
 
 type Config struct {
@@ -15,6 +26,21 @@ type Config struct {
 	inactiveStampID      string
 	adminGroupID         string
 	adminGroupName       string
+	// 承認・却下の判定の条件のJSON。空の場合は閾値で判定する
+	approvalPolicy string
+	// メンバーから外す申請を承認するのに必要な承認スタンプの数
+	removalAcceptStampThreshold int
+	// 申請されてからこの期間が過ぎても判定されない招待は、期限切れにする
+	pendingExpiry time.Duration
+	// 申請されてからこの期間が過ぎても判定されない招待は、adminにリマインドする
+	reminderAfter time.Duration
+	// 同じ招待を続けてリマインドするときに空ける期間
+	reminderInterval time.Duration
+	// GitHubの招待が期限切れになったときに、自動で1回送り直すか
+	autoRenewInvitations bool
+	// GitHubのWebhookを受け取るアドレスと、署名の検証に使うsecret。アドレスが空の場合は受け取らない
+	gitHubWebhookAddr   string
+	gitHubWebhookSecret string
 }
 
 func loadConfig() (*Config, error) {
@@ -66,14 +92,77 @@ func loadConfig() (*Config, error) {
 		return nil, errors.New("ADMIN_GROUP_NAME is not set")
 	}
 
+	approvalPolicy := os.Getenv("APPROVAL_POLICY")
+
+	// 外す申請は招待より慎重に判定できるように、閾値を別に設定できる
+	removalAcceptStampThreshold := acceptStampThreshold
+	if removalAcceptStampThresholdStr, ok := os.LookupEnv("REMOVAL_ACCEPT_STAMP_THRESHOLD"); ok {
+		removalAcceptStampThreshold, err = strconv.Atoi(removalAcceptStampThresholdStr)
+		if err != nil || removalAcceptStampThreshold <= 0 {
+			return nil, errors.New("REMOVAL_ACCEPT_STAMP_THRESHOLD is not a positive number")
+		}
+	}
+
+	pendingExpiry, err := lookupDurationEnv("PENDING_EXPIRY", defaultPendingExpiry)
+	if err != nil {
+		return nil, err
+	}
+
+	reminderAfter, err := lookupDurationEnv("REMINDER_AFTER", defaultReminderAfter)
+	if err != nil {
+		return nil, err
+	}
+
+	reminderInterval, err := lookupDurationEnv("REMINDER_INTERVAL", defaultReminderInterval)
+	if err != nil {
+		return nil, err
+	}
+
+	autoRenewInvitations := false
+	if autoRenewInvitationsStr, ok := os.LookupEnv("AUTO_RENEW_INVITATIONS"); ok {
+		autoRenewInvitations, err = strconv.ParseBool(autoRenewInvitationsStr)
+		if err != nil {
+			return nil, errors.New("AUTO_RENEW_INVITATIONS is not a boolean")
+		}
+	}
+
+	gitHubWebhookAddr := os.Getenv("GITHUB_WEBHOOK_ADDR")
+	gitHubWebhookSecret := os.Getenv("GITHUB_WEBHOOK_SECRET")
+	if gitHubWebhookAddr != "" && gitHubWebhookSecret == "" {
+		return nil, errors.New("GITHUB_WEBHOOK_SECRET is not set")
+	}
+
 	return &Config{
-		botChannelID:         channelID,
-		acceptStampID:        acceptStampID,
-		acceptStampThreshold: acceptStampThreshold,
-		rejectStampID:        rejectStampID,
-		rejectStampThreshold: rejectStampThreshold,
-		inactiveStampID:      inactiveStampID,
-		adminGroupID:         adminGroupID,
-		adminGroupName:       adminGroupName,
+		botChannelID:                channelID,
+		acceptStampID:               acceptStampID,
+		acceptStampThreshold:        acceptStampThreshold,
+		rejectStampID:               rejectStampID,
+		rejectStampThreshold:        rejectStampThreshold,
+		inactiveStampID:             inactiveStampID,
+		adminGroupID:                adminGroupID,
+		adminGroupName:              adminGroupName,
+		approvalPolicy:              approvalPolicy,
+		removalAcceptStampThreshold: removalAcceptStampThreshold,
+		pendingExpiry:               pendingExpiry,
+		reminderAfter:               reminderAfter,
+		reminderInterval:            reminderInterval,
+		autoRenewInvitations:        autoRenewInvitations,
+		gitHubWebhookAddr:           gitHubWebhookAddr,
+		gitHubWebhookSecret:         gitHubWebhookSecret,
 	}, nil
 }
+
+// lookupDurationEnv は、環境変数keyを期間として読む。設定されていない場合はdefaultValueを返す
+func lookupDurationEnv(key string, defaultValue time.Duration) (time.Duration, error) {
+	value, ok := os.LookupEnv(key)
+	if !ok {
+		return defaultValue, nil
+	}
+
+	d, err := time.ParseDuration(value)
+	if err != nil || d <= 0 {
+		return 0, fmt.Errorf("%s is not a positive duration", key)
+	}
+
+	return d, nil
+}
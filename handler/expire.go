@@ -0,0 +1,66 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/traP-jp/members_bot/model"
+	"github.com/traP-jp/members_bot/repository"
+)
+
+// ExpireInvitations は、申請されてから pendingExpiry が過ぎても判定されていない招待を期限切れにする。
+// 期限切れにした招待のメッセージには:kan:を押して末尾の判定の状況を書き換え、申請者に通知する
+func (h *BotHandler) ExpireInvitations(ctx context.Context) {
+	invitations, err := h.ir.GetInvitationsCreatedBefore(ctx, model.InvitationStatusPending, time.Now().Add(-h.pendingExpiry))
+	if err != nil {
+		logger.Printf("failed to get invitations: %v", err)
+		return
+	}
+
+	// 同じメッセージの招待はまとめて期限切れにする
+	messageIDs := make([]string, 0, len(invitations))
+	invitationsByMessage := make(map[string][]*model.Invitation)
+	for _, inv := range invitations {
+		if _, ok := invitationsByMessage[inv.MessageID()]; !ok {
+			messageIDs = append(messageIDs, inv.MessageID())
+		}
+		invitationsByMessage[inv.MessageID()] = append(invitationsByMessage[inv.MessageID()], inv)
+	}
+
+	for _, messageID := range messageIDs {
+		err := h.ir.UpdateInvitationStatus(ctx, messageID, model.InvitationStatusExpired)
+		if errors.Is(err, repository.ErrInvalidStatusTransition) {
+			continue // 取得した後に判定された
+		}
+		if err != nil {
+			logger.Printf("failed to update invitation status: %v", err)
+			continue
+		}
+
+		err = h.traqClient.AddStamp(ctx, messageID, h.inactiveStampID, 1)
+		if err != nil {
+			logger.Printf("failed to add stamp: %v", err)
+		}
+		h.updateProgress(ctx, messageID, fmt.Sprintf("⌛ %sの間承認・却下されなかったため期限切れになりました", formatDuration(h.pendingExpiry)))
+
+		h.notifyRequester(ctx, invitationsByMessage[messageID], expirationMessage(h.pendingExpiry))
+	}
+}
+
+func expirationMessage(expiry time.Duration) string {
+	return fmt.Sprintf("招待の申請は、%sの間承認・却下されなかったため期限切れになりました。必要であれば申請し直してください", formatDuration(expiry))
+}
+
+// formatDuration は、期間を日または時間の単位で表す
+func formatDuration(d time.Duration) string {
+	if d >= 24*time.Hour && d%(24*time.Hour) == 0 {
+		return fmt.Sprintf("%d日", d/(24*time.Hour))
+	}
+	if d >= time.Hour && d%time.Hour == 0 {
+		return fmt.Sprintf("%d時間", d/time.Hour)
+	}
+
+	return d.String()
+}
@@ -0,0 +1,129 @@
+package handler
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/traP-jp/members_bot/model"
+	"github.com/traP-jp/members_bot/repository"
+	repomock "github.com/traP-jp/members_bot/repository/mock"
+	"github.com/traP-jp/members_bot/service/mock"
+)
+
+func TestExpireInvitations(t *testing.T) {
+	t.Parallel()
+
+	inactiveStampID := uuid.NewString()
+	originChannelID := uuid.NewString()
+	originMessageID := uuid.NewString()
+	origin := model.WithOrigin(model.NewUser(uuid.NewString(), "requester"), originChannelID, originMessageID)
+	messageIDs := []string{uuid.NewString(), uuid.NewString()}
+
+	type test struct {
+		invitations               []*model.Invitation
+		UpdateInvitationStatusErr error
+		expiredMessageIDs         []string
+		notifyTexts               []string
+	}
+
+	testCases := map[string]test{
+		"期限切れにして通知": {
+			invitations: []*model.Invitation{
+				model.NewInvitation(messageIDs[0], "ikura-hamu", "ikura-hamu", origin),
+				model.NewInvitation(messageIDs[1], "H1rono_K", "H1rono"),
+			},
+			expiredMessageIDs: messageIDs,
+			notifyTexts: []string{
+				"@requester 招待の申請は、30日の間承認・却下されなかったため期限切れになりました。必要であれば申請し直してください\n@ikura-hamu (ikura-hamu)\nhttps://q.trap.jp/messages/" + originMessageID,
+			},
+		},
+		"同じメッセージの招待はまとめて期限切れにする": {
+			invitations: []*model.Invitation{
+				model.NewInvitation(messageIDs[0], "ikura-hamu", "ikura-hamu", origin),
+				model.NewInvitation(messageIDs[0], "H1rono_K", "H1rono", origin),
+			},
+			expiredMessageIDs: messageIDs[:1],
+			notifyTexts: []string{
+				"@requester 招待の申請は、30日の間承認・却下されなかったため期限切れになりました。必要であれば申請し直してください\n@ikura-hamu (ikura-hamu)\n@H1rono_K (H1rono)\nhttps://q.trap.jp/messages/" + originMessageID,
+			},
+		},
+		"既に判定されていた": {
+			invitations: []*model.Invitation{
+				model.NewInvitation(messageIDs[0], "ikura-hamu", "ikura-hamu", origin),
+			},
+			UpdateInvitationStatusErr: repository.ErrInvalidStatusTransition,
+		},
+		"期限切れの招待がない": {},
+	}
+
+	for name, test := range testCases {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			traqMock := &mock.TraqMock{
+				AddStampFunc: func(context.Context, string, string, int) error {
+					return nil
+				},
+				PostMessageFunc: func(context.Context, string, string) (string, error) {
+					return uuid.NewString(), nil
+				},
+				GetMessageFunc: func(context.Context, string) (string, error) {
+					return "申請" + progressSeparator + "承認 1 (@admin) / 却下 0\n条件: 承認スタンプ2つ以上", nil
+				},
+				EditMessageFunc: func(context.Context, string, string) error {
+					return nil
+				},
+			}
+			irMock := &repomock.InvitationMock{
+				GetInvitationsCreatedBeforeFunc: func(ctx context.Context, status model.InvitationStatus, cutoff time.Time) ([]*model.Invitation, error) {
+					return test.invitations, nil
+				},
+				UpdateInvitationStatusFunc: func(ctx context.Context, invitationID string, status model.InvitationStatus) error {
+					return test.UpdateInvitationStatusErr
+				},
+			}
+
+			bh := &BotHandler{
+				traqClient: traqMock,
+				ir:         irMock,
+				Config: &Config{
+					inactiveStampID: inactiveStampID,
+					pendingExpiry:   defaultPendingExpiry,
+				},
+			}
+
+			bh.ExpireInvitations(context.Background())
+
+			getCalls := irMock.GetInvitationsCreatedBeforeCalls()
+			require.Len(t, getCalls, 1)
+			assert.Equal(t, model.InvitationStatusPending, getCalls[0].Status)
+			assert.WithinDuration(t, time.Now().Add(-defaultPendingExpiry), getCalls[0].Cutoff, time.Second)
+
+			addStampCalls := traqMock.AddStampCalls()
+			require.Len(t, addStampCalls, len(test.expiredMessageIDs))
+			for i, messageID := range test.expiredMessageIDs {
+				assert.Equal(t, messageID, addStampCalls[i].MessageID)
+				assert.Equal(t, inactiveStampID, addStampCalls[i].StampID)
+			}
+
+			// 申請メッセージの末尾の判定の状況を、期限切れになったことに書き換える
+			editCalls := traqMock.EditMessageCalls()
+			require.Len(t, editCalls, len(test.expiredMessageIDs))
+			for i, messageID := range test.expiredMessageIDs {
+				assert.Equal(t, messageID, editCalls[i].MessageID)
+				assert.Equal(t, "申請"+progressSeparator+"⌛ 30日の間承認・却下されなかったため期限切れになりました", editCalls[i].Text)
+			}
+
+			postCalls := traqMock.PostMessageCalls()
+			require.Len(t, postCalls, len(test.notifyTexts))
+			for i, text := range test.notifyTexts {
+				assert.Equal(t, originChannelID, postCalls[i].ChannelID)
+				assert.Equal(t, text, postCalls[i].Text)
+			}
+		})
+	}
+}
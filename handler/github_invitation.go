@@ -0,0 +1,115 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/traP-jp/members_bot/model"
+	"github.com/traP-jp/members_bot/repository"
+)
+
+// GitHubのOrganizationへの招待の有効期間
+const gitHubInvitationExpiry = 7 * 24 * time.Hour
+
+// CheckGitHubInvitations は、招待を送信した人がOrganizationに参加したか、招待が期限切れになったかを確認し、
+// 招待の状態を更新してbotのチャンネルに知らせる
+func (h *BotHandler) CheckGitHubInvitations(ctx context.Context) {
+	invitations, err := h.ir.GetInvitationsByStatus(ctx, model.InvitationStatusSent)
+	if err != nil {
+		logger.Printf("failed to get invitations: %v", err)
+		return
+	}
+	if len(invitations) == 0 {
+		return
+	}
+
+	pendingInvitations, err := h.githubClient.ListPendingInvitations(ctx)
+	if err != nil {
+		logger.Printf("failed to list pending invitations: %v", err)
+		return
+	}
+	failedInvitations, err := h.githubClient.ListFailedInvitations(ctx)
+	if err != nil {
+		logger.Printf("failed to list failed invitations: %v", err)
+		return
+	}
+
+	pending := make(map[string]bool, len(pendingInvitations))
+	for _, inv := range pendingInvitations {
+		pending[strings.ToLower(inv.Login())] = true
+	}
+	failed := make(map[string]*model.GitHubInvitation, len(failedInvitations))
+	for _, inv := range failedInvitations {
+		failed[strings.ToLower(inv.Login())] = inv
+	}
+
+	message := ""
+	for _, inv := range invitations {
+		// 招待した後にユーザー名が変わっていても、参加したことや招待の状況を確認できるようにする
+		inv = h.refreshGitHubLogin(ctx, inv)
+
+		isMember, err := h.githubClient.CheckUserIsMember(ctx, inv.GitHubID())
+		if err != nil {
+			logger.Printf("failed to check user is member: %v", err)
+			continue
+		}
+
+		var (
+			status model.InvitationStatus
+			notice string
+		)
+		switch failedInvitation, ok := failed[strings.ToLower(inv.GitHubID())]; {
+		case isMember:
+			status = model.InvitationStatusAccepted
+			notice = fmt.Sprintf("%s に参加しました", h.githubClient.OrgName())
+		case pending[strings.ToLower(inv.GitHubID())]:
+			continue // まだ承諾されていない
+		case ok:
+			status = model.InvitationStatusExpired
+			notice = "GitHubの招待が失敗しました"
+			if failedInvitation.FailedReason() != "" {
+				notice += fmt.Sprintf(" (%s)", failedInvitation.FailedReason())
+			}
+		case time.Since(inv.TransitionedAt(model.InvitationStatusSent)) > gitHubInvitationExpiry:
+			// 失敗した招待の一覧から消えた後も、有効期間が過ぎていれば期限切れとする
+			status = model.InvitationStatusExpired
+			notice = "GitHubの招待が期限切れになりました"
+		default:
+			continue
+		}
+
+		err = h.ir.UpdateInvitationStatusByGitHubID(ctx, inv.MessageID(), inv.GitHubID(), status)
+		if errors.Is(err, repository.ErrInvalidStatusTransition) {
+			continue
+		}
+		if err != nil {
+			logger.Printf("failed to update invitation status: %v", err)
+			continue
+		}
+
+		// 自動で送り直すのは1回だけにするため、以前に期限切れになったことがある招待は送り直さない
+		if status == model.InvitationStatusExpired && h.autoRenewInvitations && inv.TransitionedAt(model.InvitationStatusExpired).IsZero() {
+			err := h.resendInvitation(ctx, inv)
+			if err != nil {
+				logger.Printf("failed to resend invitation: %v", err)
+				notice += "。招待を送り直せませんでした"
+			} else {
+				notice += "。招待を自動で送り直しました"
+			}
+		}
+
+		message += fmt.Sprintf("@%s (%s) %s\n", strings.TrimPrefix(inv.TraqID(), "@"), inv.GitHubID(), notice)
+	}
+
+	if message == "" {
+		return
+	}
+
+	_, err = h.traqClient.PostMessage(ctx, h.botChannelID, "GitHubの招待の状況が変わりました\n"+message)
+	if err != nil {
+		logger.Printf("failed to post message: %v", err)
+	}
+}
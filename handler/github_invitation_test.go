@@ -0,0 +1,201 @@
+package handler
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/traP-jp/members_bot/model"
+	"github.com/traP-jp/members_bot/repository"
+	repomock "github.com/traP-jp/members_bot/repository/mock"
+	"github.com/traP-jp/members_bot/service/mock"
+)
+
+func TestCheckGitHubInvitations(t *testing.T) {
+	t.Parallel()
+
+	messageID := uuid.NewString()
+	sent := model.WithStatus(model.InvitationStatusSent)
+
+	type test struct {
+		invitation                *model.Invitation
+		isMember                  bool
+		pendingInvitations        []*model.GitHubInvitation
+		failedInvitations         []*model.GitHubInvitation
+		UpdateInvitationStatusErr error
+		autoRenew                 bool
+		status                    model.InvitationStatus
+		resent                    bool
+		postText                  string
+	}
+
+	testCases := map[string]test{
+		"参加した": {
+			invitation: model.NewInvitation(messageID, "@ikura-hamu", "ikura-hamu", sent,
+				model.WithTransitionedAt(model.InvitationStatusSent, time.Now().Add(-time.Hour))),
+			isMember: true,
+			status:   model.InvitationStatusAccepted,
+			postText: "GitHubの招待の状況が変わりました\n@ikura-hamu (ikura-hamu) traP-jp に参加しました\n",
+		},
+		"招待した後にユーザー名を変えた人が参加した": {
+			invitation: model.NewInvitation(messageID, "@ikura-hamu", "old-name", sent, model.WithGitHubUserID(1001),
+				model.WithTransitionedAt(model.InvitationStatusSent, time.Now().Add(-time.Hour))),
+			isMember: true,
+			status:   model.InvitationStatusAccepted,
+			postText: "GitHubの招待の状況が変わりました\n@ikura-hamu (ikura-hamu) traP-jp に参加しました\n",
+		},
+		"まだ承諾されていない": {
+			invitation: model.NewInvitation(messageID, "@ikura-hamu", "ikura-hamu", sent,
+				model.WithTransitionedAt(model.InvitationStatusSent, time.Now().Add(-8*24*time.Hour))),
+			pendingInvitations: []*model.GitHubInvitation{
+				model.NewGitHubInvitation(1, "Ikura-Hamu", time.Now(), time.Time{}, ""),
+			},
+		},
+		"招待が失敗した": {
+			invitation: model.NewInvitation(messageID, "@ikura-hamu", "ikura-hamu", sent,
+				model.WithTransitionedAt(model.InvitationStatusSent, time.Now().Add(-time.Hour))),
+			failedInvitations: []*model.GitHubInvitation{
+				model.NewGitHubInvitation(1, "ikura-hamu", time.Now(), time.Now(), "Invitation expired"),
+			},
+			status:   model.InvitationStatusExpired,
+			postText: "GitHubの招待の状況が変わりました\n@ikura-hamu (ikura-hamu) GitHubの招待が失敗しました (Invitation expired)\n",
+		},
+		"有効期間が過ぎた": {
+			invitation: model.NewInvitation(messageID, "@ikura-hamu", "ikura-hamu", sent,
+				model.WithTransitionedAt(model.InvitationStatusSent, time.Now().Add(-8*24*time.Hour))),
+			status:   model.InvitationStatusExpired,
+			postText: "GitHubの招待の状況が変わりました\n@ikura-hamu (ikura-hamu) GitHubの招待が期限切れになりました\n",
+		},
+		"期限切れになったら自動で送り直す": {
+			invitation: model.NewInvitation(messageID, "@ikura-hamu", "ikura-hamu", sent,
+				model.WithTransitionedAt(model.InvitationStatusSent, time.Now().Add(-8*24*time.Hour))),
+			autoRenew: true,
+			status:    model.InvitationStatusExpired,
+			resent:    true,
+			postText:  "GitHubの招待の状況が変わりました\n@ikura-hamu (ikura-hamu) GitHubの招待が期限切れになりました。招待を自動で送り直しました\n",
+		},
+		"自動で送り直すのは1回だけ": {
+			invitation: model.NewInvitation(messageID, "@ikura-hamu", "ikura-hamu", sent,
+				model.WithTransitionedAt(model.InvitationStatusSent, time.Now().Add(-8*24*time.Hour)),
+				model.WithTransitionedAt(model.InvitationStatusExpired, time.Now().Add(-9*24*time.Hour))),
+			autoRenew: true,
+			status:    model.InvitationStatusExpired,
+			postText:  "GitHubの招待の状況が変わりました\n@ikura-hamu (ikura-hamu) GitHubの招待が期限切れになりました\n",
+		},
+		"招待の一覧になくても有効期間内なら何もしない": {
+			invitation: model.NewInvitation(messageID, "@ikura-hamu", "ikura-hamu", sent,
+				model.WithTransitionedAt(model.InvitationStatusSent, time.Now().Add(-time.Hour))),
+		},
+		"他で状態が変わっていた": {
+			invitation: model.NewInvitation(messageID, "@ikura-hamu", "ikura-hamu", sent,
+				model.WithTransitionedAt(model.InvitationStatusSent, time.Now().Add(-time.Hour))),
+			isMember:                  true,
+			UpdateInvitationStatusErr: repository.ErrInvalidStatusTransition,
+			status:                    model.InvitationStatusAccepted,
+		},
+	}
+
+	for name, test := range testCases {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			traqMock := &mock.TraqMock{
+				PostMessageFunc: func(context.Context, string, string) (string, error) {
+					return uuid.NewString(), nil
+				},
+			}
+			gitHubMock := &mock.GitHubMock{
+				CheckUserIsMemberFunc: func(ctx context.Context, userID string) (bool, error) {
+					return test.isMember, nil
+				},
+				ListPendingInvitationsFunc: func(ctx context.Context) ([]*model.GitHubInvitation, error) {
+					return test.pendingInvitations, nil
+				},
+				ListFailedInvitationsFunc: func(ctx context.Context) ([]*model.GitHubInvitation, error) {
+					return test.failedInvitations, nil
+				},
+				OrgNameFunc: func() string {
+					return "traP-jp"
+				},
+				SendInvitationsFunc: func(ctx context.Context, invitations []*model.Invitation) []*model.SendResult {
+					return []*model.SendResult{model.NewSendResult(invitations[0], model.SendResultStatusSent, nil)}
+				},
+				GetUserLoginFunc: func(ctx context.Context, gitHubUserID int64) (string, error) {
+					return "ikura-hamu", nil
+				},
+			}
+			irMock := &repomock.InvitationMock{
+				GetInvitationsByStatusFunc: func(ctx context.Context, statuses ...model.InvitationStatus) ([]*model.Invitation, error) {
+					return []*model.Invitation{test.invitation}, nil
+				},
+				UpdateInvitationStatusByGitHubIDFunc: func(ctx context.Context, invitationID string, gitHubID string, status model.InvitationStatus) error {
+					if status == model.InvitationStatusSending || status == model.InvitationStatusSent {
+						return nil // 送り直し
+					}
+					return test.UpdateInvitationStatusErr
+				},
+				UpdateInvitationGitHubIDFunc: func(ctx context.Context, gitHubUserID int64, gitHubID string) error {
+					return nil
+				},
+			}
+			alrMock := &repomock.AccountLinkMock{
+				UpdateAccountLinkGitHubLoginFunc: func(ctx context.Context, gitHubUserID int64, login string) error {
+					return nil
+				},
+			}
+
+			bh := &BotHandler{
+				traqClient:   traqMock,
+				githubClient: gitHubMock,
+				ir:           irMock,
+				alr:          alrMock,
+				Config: &Config{
+					botChannelID:         "botChannelID",
+					autoRenewInvitations: test.autoRenew,
+				},
+			}
+
+			bh.CheckGitHubInvitations(context.Background())
+
+			getCalls := irMock.GetInvitationsByStatusCalls()
+			require.Len(t, getCalls, 1)
+			assert.Equal(t, []model.InvitationStatus{model.InvitationStatusSent}, getCalls[0].Statuses)
+
+			// 現在のユーザー名で、参加したかを確認する
+			memberCalls := gitHubMock.CheckUserIsMemberCalls()
+			require.Len(t, memberCalls, 1)
+			assert.Equal(t, "ikura-hamu", memberCalls[0].UserID)
+
+			// 同じメッセージで申請された他の人の招待を変えないように、GitHubのユーザー名を指定して更新する
+			updateCalls := irMock.UpdateInvitationStatusByGitHubIDCalls()
+			if test.status == "" {
+				assert.Empty(t, updateCalls)
+			} else if test.resent {
+				require.Len(t, updateCalls, 3)
+				assert.Equal(t, test.status, updateCalls[0].Status)
+				// 送信中の状態にしてから送り直す
+				assert.Equal(t, model.InvitationStatusSending, updateCalls[1].Status)
+				assert.Equal(t, model.InvitationStatusSent, updateCalls[2].Status)
+				assert.Len(t, gitHubMock.SendInvitationsCalls(), 1)
+			} else {
+				require.Len(t, updateCalls, 1)
+				assert.Equal(t, messageID, updateCalls[0].InvitationID)
+				assert.Equal(t, "ikura-hamu", updateCalls[0].GitHubID)
+				assert.Equal(t, test.status, updateCalls[0].Status)
+				assert.Empty(t, gitHubMock.SendInvitationsCalls())
+			}
+
+			postCalls := traqMock.PostMessageCalls()
+			if test.postText == "" {
+				assert.Empty(t, postCalls)
+			} else {
+				require.Len(t, postCalls, 1)
+				assert.Equal(t, "botChannelID", postCalls[0].ChannelID)
+				assert.Equal(t, test.postText, postCalls[0].Text)
+			}
+		})
+	}
+}
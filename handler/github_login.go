@@ -0,0 +1,119 @@
+package handler
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/traP-jp/members_bot/model"
+)
+
+// RefreshGitHubLogins は、記録しているGitHubのユーザーIDから現在のユーザー名を取得し、
+// ユーザー名が変わっていたら招待とアカウントの対応を更新してbotのチャンネルに知らせる
+func (h *BotHandler) RefreshGitHubLogins(ctx context.Context) {
+	logins, err := h.ir.GetLatestGitHubLogins(ctx)
+	if err != nil {
+		logger.Printf("failed to get GitHub logins: %v", err)
+		return
+	}
+
+	message := ""
+	for _, l := range logins {
+		gitHubUserID := l.GitHubUserID
+		login, err := h.githubClient.GetUserLogin(ctx, gitHubUserID)
+		if err != nil {
+			logger.Printf("failed to get GitHub user login: %v", err)
+			continue
+		}
+		if login == "" || strings.EqualFold(login, l.Login) {
+			continue
+		}
+
+		err = h.renameGitHubLogin(ctx, gitHubUserID, login)
+		if err != nil {
+			logger.Printf("failed to rename GitHub login: %v", err)
+			continue
+		}
+
+		message += fmt.Sprintf("%s → %s\n", l.Login, login)
+	}
+
+	if message == "" {
+		return
+	}
+
+	_, err = h.traqClient.PostMessage(ctx, h.botChannelID, "GitHubのユーザー名の変更を反映しました\n"+message)
+	if err != nil {
+		logger.Printf("failed to post message: %v", err)
+	}
+}
+
+// renameGitHubLogin は、GitHubのユーザーIDがgitHubUserIDの招待とアカウントの対応のユーザー名をloginに更新する
+func (h *BotHandler) renameGitHubLogin(ctx context.Context, gitHubUserID int64, login string) error {
+	err := h.ir.UpdateInvitationGitHubID(ctx, gitHubUserID, login)
+	if err != nil {
+		return fmt.Errorf("failed to update invitation GitHub ID: %w", err)
+	}
+	err = h.alr.UpdateAccountLinkGitHubLogin(ctx, gitHubUserID, login)
+	if err != nil {
+		logger.Printf("failed to update account link GitHub login: %v", err)
+	}
+
+	return nil
+}
+
+// refreshGitHubLogin は、招待したGitHubユーザーの現在のユーザー名にした招待を返す。
+// GitHubの招待の一覧はユーザー名でしか照合できないので、GitHubに問い合わせる前に使う。
+// ユーザーIDが分からないか、現在のユーザー名を取得できない場合は、記録しているユーザー名のまま返す
+func (h *BotHandler) refreshGitHubLogin(ctx context.Context, inv *model.Invitation) *model.Invitation {
+	if inv.GitHubUserID() == 0 {
+		return inv
+	}
+
+	login, err := h.githubClient.GetUserLogin(ctx, inv.GitHubUserID())
+	if err != nil {
+		logger.Printf("failed to get GitHub user login: %v", err)
+		return inv
+	}
+	if login == "" || strings.EqualFold(login, inv.GitHubID()) {
+		return inv
+	}
+
+	err = h.renameGitHubLogin(ctx, inv.GitHubUserID(), login)
+	if err != nil {
+		logger.Printf("failed to rename GitHub login: %v", err)
+		return inv
+	}
+
+	return inv.WithGitHubID(login)
+}
+
+// filterByGitHubUser は、invitationsのうち、GitHubのユーザー名がloginのユーザーへの招待を返す。
+// ユーザー名で見つからない場合は、招待した後にユーザー名が変わった可能性があるので、ユーザーIDで探す
+func (h *BotHandler) filterByGitHubUser(ctx context.Context, invitations []*model.Invitation, login string) ([]*model.Invitation, error) {
+	var filtered []*model.Invitation
+	for _, inv := range invitations {
+		if strings.EqualFold(inv.GitHubID(), login) {
+			filtered = append(filtered, inv)
+		}
+	}
+	if len(filtered) > 0 {
+		return filtered, nil
+	}
+
+	gitHubUserID, err := h.githubClient.GetUserID(ctx, login)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get GitHub user ID: %w", err)
+	}
+	if gitHubUserID == 0 {
+		return nil, nil
+	}
+
+	for _, inv := range invitations {
+		if inv.GitHubUserID() == gitHubUserID {
+			filtered = append(filtered, inv)
+		}
+	}
+
+	return filtered, nil
+}
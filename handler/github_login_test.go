@@ -0,0 +1,127 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/traP-jp/members_bot/repository"
+	repomock "github.com/traP-jp/members_bot/repository/mock"
+	"github.com/traP-jp/members_bot/service/mock"
+)
+
+func TestRefreshGitHubLogins(t *testing.T) {
+	t.Parallel()
+
+	type test struct {
+		gitHubLogins    []*repository.GitHubLogin
+		logins          map[int64]string
+		GetUserLoginErr error
+		// GetUserLoginが呼ばれるユーザーID
+		lookedUpIDs []int64
+		// ユーザー名を更新するユーザーIDと新しいユーザー名
+		updatedIDs    []int64
+		updatedLogins []string
+		postText      string
+	}
+
+	testCases := map[string]test{
+		"ユーザー名が変わっていたら更新して知らせる": {
+			gitHubLogins: []*repository.GitHubLogin{
+				{GitHubUserID: 1001, Login: "ikura-hamu"},
+				{GitHubUserID: 1002, Login: "H1rono"},
+			},
+			logins:        map[int64]string{1001: "ikura-hamu-new", 1002: "H1rono"},
+			lookedUpIDs:   []int64{1001, 1002},
+			updatedIDs:    []int64{1001},
+			updatedLogins: []string{"ikura-hamu-new"},
+			postText:      "GitHubのユーザー名の変更を反映しました\nikura-hamu → ikura-hamu-new\n",
+		},
+		"大文字と小文字の違いは変更とみなさない": {
+			gitHubLogins: []*repository.GitHubLogin{
+				{GitHubUserID: 1002, Login: "h1rono"},
+			},
+			logins:      map[int64]string{1002: "H1rono"},
+			lookedUpIDs: []int64{1002},
+		},
+		"ユーザー名を記録した招待がない": {},
+		"ユーザー名を取得できない": {
+			gitHubLogins: []*repository.GitHubLogin{
+				{GitHubUserID: 1001, Login: "ikura-hamu"},
+			},
+			GetUserLoginErr: errors.New("not found"),
+			lookedUpIDs:     []int64{1001},
+		},
+	}
+
+	for name, test := range testCases {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			traqMock := &mock.TraqMock{
+				PostMessageFunc: func(context.Context, string, string) (string, error) {
+					return uuid.NewString(), nil
+				},
+			}
+			gitHubMock := &mock.GitHubMock{
+				GetUserLoginFunc: func(ctx context.Context, gitHubUserID int64) (string, error) {
+					return test.logins[gitHubUserID], test.GetUserLoginErr
+				},
+			}
+			irMock := &repomock.InvitationMock{
+				GetLatestGitHubLoginsFunc: func(context.Context) ([]*repository.GitHubLogin, error) {
+					return test.gitHubLogins, nil
+				},
+				UpdateInvitationGitHubIDFunc: func(context.Context, int64, string) error {
+					return nil
+				},
+			}
+			alrMock := &repomock.AccountLinkMock{
+				UpdateAccountLinkGitHubLoginFunc: func(context.Context, int64, string) error {
+					return nil
+				},
+			}
+
+			bh := &BotHandler{
+				traqClient:   traqMock,
+				githubClient: gitHubMock,
+				ir:           irMock,
+				alr:          alrMock,
+				Config: &Config{
+					botChannelID: "botChannelID",
+				},
+			}
+
+			bh.RefreshGitHubLogins(context.Background())
+
+			getLoginCalls := gitHubMock.GetUserLoginCalls()
+			require.Len(t, getLoginCalls, len(test.lookedUpIDs))
+			for i, id := range test.lookedUpIDs {
+				assert.Equal(t, id, getLoginCalls[i].GitHubUserID)
+			}
+
+			updateInvitationCalls := irMock.UpdateInvitationGitHubIDCalls()
+			updateLinkCalls := alrMock.UpdateAccountLinkGitHubLoginCalls()
+			require.Len(t, updateInvitationCalls, len(test.updatedIDs))
+			require.Len(t, updateLinkCalls, len(test.updatedIDs))
+			for i, id := range test.updatedIDs {
+				assert.Equal(t, id, updateInvitationCalls[i].GitHubUserID)
+				assert.Equal(t, test.updatedLogins[i], updateInvitationCalls[i].GitHubID)
+				assert.Equal(t, id, updateLinkCalls[i].GitHubUserID)
+				assert.Equal(t, test.updatedLogins[i], updateLinkCalls[i].Login)
+			}
+
+			postCalls := traqMock.PostMessageCalls()
+			if test.postText == "" {
+				assert.Empty(t, postCalls)
+				return
+			}
+			require.Len(t, postCalls, 1)
+			assert.Equal(t, "botChannelID", postCalls[0].ChannelID)
+			assert.Equal(t, test.postText, postCalls[0].Text)
+		})
+	}
+}
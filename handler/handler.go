@@ -4,12 +4,13 @@ import (
 	"context"
 	"fmt"
 	"log"
-	"strconv"
+	"slices"
 	"strings"
 	"text/template"
 
 	"github.com/traP-jp/members_bot/docs"
 	"github.com/traP-jp/members_bot/model"
+	"github.com/traP-jp/members_bot/policy"
 	"github.com/traP-jp/members_bot/repository"
 	"github.com/traP-jp/members_bot/service"
 )
@@ -18,13 +19,23 @@ type BotHandler struct {
 	traqClient   service.Traq
 	githubClient service.GitHub
 	ir           repository.Invitation
-	botUser      *model.User
+	dr           repository.Decision
+	rr           repository.Removal
+	alr          repository.AccountLink
+	tx           repository.Transaction
+	policy       policy.Policy
+	// メンバーから外す申請の判定の条件
+	removalPolicy policy.Policy
+	botUser       *model.User
+	progressLocks progressLocks
+	// 招待できるユーザーだけで申請するかの確認を待っている申請
+	inviteConfirmations inviteConfirmations
 	*Config
 }
 
 var logger = log.New(nil, "", log.LstdFlags)
 
-func NewBotHandler(traqClient service.Traq, gitHubClient service.GitHub, ir repository.Invitation) (*BotHandler, error) {
+func NewBotHandler(traqClient service.Traq, gitHubClient service.GitHub, ir repository.Invitation, dr repository.Decision, rr repository.Removal, alr repository.AccountLink, tx repository.Transaction) (*BotHandler, error) {
 	ctx := context.Background()
 	botUserID, err := traqClient.GetBotUser(ctx)
 	if err != nil {
@@ -38,12 +49,23 @@ func NewBotHandler(traqClient service.Traq, gitHubClient service.GitHub, ir repo
 
 	logger.SetOutput(traqClient.NewWriter(conf.botChannelID))
 
+	p, err := loadPolicy(conf, traqClient)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load policy: %w", err)
+	}
+
 	h := &BotHandler{
-		traqClient:   traqClient,
-		githubClient: gitHubClient,
-		ir:           ir,
-		botUser:      botUserID,
-		Config:       conf,
+		traqClient:    traqClient,
+		githubClient:  gitHubClient,
+		ir:            ir,
+		dr:            dr,
+		rr:            rr,
+		alr:           alr,
+		tx:            tx,
+		policy:        p,
+		removalPolicy: loadRemovalPolicy(conf, traqClient),
+		botUser:       botUserID,
+		Config:        conf,
 	}
 
 	helpDoc, err = generateHelpDoc(h)
@@ -59,15 +81,85 @@ func NewBotHandler(traqClient service.Traq, gitHubClient service.GitHub, ir repo
 	return h, nil
 }
 
+// loadPolicy は、承認・却下の判定の条件を作る。
+// APPROVAL_POLICY が設定されていない場合は、adminのスタンプの数で判定する
+func loadPolicy(conf *Config, traqClient service.Traq) (policy.Policy, error) {
+	if conf.approvalPolicy == "" {
+		return policy.NewThreshold(traqClient, conf.adminGroupID, conf.adminGroupName,
+			conf.acceptStampID, conf.acceptStampThreshold, conf.rejectStampID, conf.rejectStampThreshold), nil
+	}
+
+	return policy.Parse([]byte(conf.approvalPolicy), traqClient, policy.Stamps{
+		AcceptStampID: conf.acceptStampID,
+		RejectStampID: conf.rejectStampID,
+	})
+}
+
+// loadRemovalPolicy は、メンバーから外す申請の判定の条件を作る。
+// 招待とは別の閾値を使うので、APPROVAL_POLICY には従わない
+func loadRemovalPolicy(conf *Config, traqClient service.Traq) policy.Policy {
+	return policy.NewThreshold(traqClient, conf.adminGroupID, conf.adminGroupName,
+		conf.acceptStampID, conf.removalAcceptStampThreshold, conf.rejectStampID, conf.rejectStampThreshold)
+}
+
+// isAdmin は、userIDのユーザーがadminのグループに所属しているかを返す
+func (h *BotHandler) isAdmin(ctx context.Context, userID string) (bool, error) {
+	adminIDs, err := h.traqClient.GetGroupMemberIDs(ctx, h.adminGroupID)
+	if err != nil {
+		return false, fmt.Errorf("failed to get group member IDs: %w", err)
+	}
+
+	return slices.Contains(adminIDs, userID), nil
+}
+
+// notifyRequester は、申請のメッセージが投稿されたチャンネルで申請者と招待される人にメンションし、申請の結果を通知する。
+// 申請元が記録されていない場合は何もしない
+func (h *BotHandler) notifyRequester(ctx context.Context, invitations []*model.Invitation, message string) {
+	if len(invitations) == 0 || invitations[0].OriginChannelID() == "" {
+		return
+	}
+
+	text := message + "\n"
+	if requester := invitations[0].Requester(); requester != nil {
+		text = fmt.Sprintf("@%s %s", requester.Name(), text)
+	}
+	for _, inv := range invitations {
+		text += fmt.Sprintf("@%s (%s)\n", h.inviteeName(ctx, inv), inv.GitHubID())
+	}
+	text += fmt.Sprintf("https://q.trap.jp/messages/%s", invitations[0].OriginMessageID())
+
+	_, err := h.traqClient.PostMessage(ctx, invitations[0].OriginChannelID(), text)
+	if err != nil {
+		logger.Printf("failed to post message: %v", err)
+	}
+}
+
+// inviteeName は、招待される人にメンションするためのtraQのユーザー名を返す。
+// traQのUUIDを記録している場合は、申請で入力された名前ではなく、traQに登録されている名前を使う
+func (h *BotHandler) inviteeName(ctx context.Context, inv *model.Invitation) string {
+	name := strings.TrimPrefix(inv.TraqID(), "@")
+	if inv.TraqUserID() == "" {
+		return name
+	}
+
+	user, err := h.traqClient.GetUser(ctx, inv.TraqUserID())
+	if err != nil {
+		logger.Printf("failed to get user: %v", err)
+		return name
+	}
+
+	return user.Name()
+}
+
 func generateHelpDoc(h *BotHandler) (string, error) {
 	helpDoc := &strings.Builder{}
 	err := template.Must(template.New("help").
 		Parse(docs.HelpTemplate)).
 		Execute(helpDoc, map[string]string{
-			"ORG_NAME":               h.githubClient.OrgName(),
-			"BOT_NAME":               h.botUser.Name(),
-			"ACCEPT_STAMP_THRESHOLD": strconv.Itoa(h.acceptStampThreshold),
-			"REJECT_STAMP_THRESHOLD": strconv.Itoa(h.rejectStampThreshold),
+			"ORG_NAME":        h.githubClient.OrgName(),
+			"BOT_NAME":        h.botUser.Name(),
+			"APPROVAL_POLICY": h.policy.Description(),
+			"REMOVAL_POLICY":  h.removalPolicy.Description(),
 		})
 
 	if err != nil {
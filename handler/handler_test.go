@@ -0,0 +1,103 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"io"
+	"os"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/traP-jp/members_bot/model"
+	repomock "github.com/traP-jp/members_bot/repository/mock"
+	"github.com/traP-jp/members_bot/service/mock"
+)
+
+func TestMain(m *testing.M) {
+	// 本番ではtraQに投稿されるログを捨てる
+	logger.SetOutput(io.Discard)
+
+	os.Exit(m.Run())
+}
+
+// newTransactionMock は、fnをそのまま実行するトランザクションのモックを返す
+func newTransactionMock() *repomock.TransactionMock {
+	return &repomock.TransactionMock{
+		RunInTxFunc: func(ctx context.Context, fn func(ctx context.Context) error) error {
+			return fn(ctx)
+		},
+	}
+}
+
+func TestNotifyRequester(t *testing.T) {
+	t.Parallel()
+
+	originChannelID := uuid.NewString()
+	originMessageID := uuid.NewString()
+	traqUserID := uuid.NewString()
+	origin := model.WithOrigin(model.NewUser(uuid.NewString(), "requester"), originChannelID, originMessageID)
+
+	type test struct {
+		invitations []*model.Invitation
+		GetUserErr  error
+		postText    string
+	}
+
+	testCases := map[string]test{
+		"申請者と招待される人にメンションする": {
+			invitations: []*model.Invitation{
+				model.NewInvitation(uuid.NewString(), "@ikura-hamu", "ikura-hamu", origin),
+				model.NewInvitation(uuid.NewString(), "H1rono", "H1rono", origin),
+			},
+			postText: "@requester 招待が承認されました\n@ikura-hamu (ikura-hamu)\n@H1rono (H1rono)\nhttps://q.trap.jp/messages/" + originMessageID,
+		},
+		"traQのUUIDを記録している場合はtraQに登録されている名前でメンションする": {
+			invitations: []*model.Invitation{
+				model.NewInvitation(uuid.NewString(), "Ikura-Hamu", "ikura-hamu", origin, model.WithTraqUserID(traqUserID)),
+			},
+			postText: "@requester 招待が承認されました\n@ikura-hamu (ikura-hamu)\nhttps://q.trap.jp/messages/" + originMessageID,
+		},
+		"traQのユーザーを取得できない場合は申請で入力された名前でメンションする": {
+			invitations: []*model.Invitation{
+				model.NewInvitation(uuid.NewString(), "Ikura-Hamu", "ikura-hamu", origin, model.WithTraqUserID(traqUserID)),
+			},
+			GetUserErr: errors.New("get user error"),
+			postText:   "@requester 招待が承認されました\n@Ikura-Hamu (ikura-hamu)\nhttps://q.trap.jp/messages/" + originMessageID,
+		},
+		"申請元が記録されていない場合は通知しない": {
+			invitations: []*model.Invitation{
+				model.NewInvitation(uuid.NewString(), "@ikura-hamu", "ikura-hamu"),
+			},
+		},
+	}
+
+	for name, test := range testCases {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			traqMock := &mock.TraqMock{
+				PostMessageFunc: func(context.Context, string, string) (string, error) {
+					return uuid.NewString(), nil
+				},
+				GetUserFunc: func(_ context.Context, userID string) (*model.User, error) {
+					return model.NewUser(userID, "ikura-hamu"), test.GetUserErr
+				},
+			}
+
+			bh := &BotHandler{traqClient: traqMock}
+
+			bh.notifyRequester(context.Background(), test.invitations, "招待が承認されました")
+
+			postCalls := traqMock.PostMessageCalls()
+			if test.postText == "" {
+				assert.Empty(t, postCalls)
+				return
+			}
+			require.Len(t, postCalls, 1)
+			assert.Equal(t, originChannelID, postCalls[0].ChannelID)
+			assert.Equal(t, test.postText, postCalls[0].Text)
+		})
+	}
+}
@@ -0,0 +1,230 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/traP-jp/members_bot/model"
+	"github.com/traP-jp/members_bot/repository"
+	"github.com/traPtitech/traq-ws-bot/payload"
+)
+
+const (
+	historyCommandUsage = "`@BOT_traP-jp /(history|履歴) [--traq <traQID>] [--github <GitHubID>] [--since <YYYY-MM-DD>] [--until <YYYY-MM-DD>] [--page <ページ>]`"
+	historyPageSize     = 10
+)
+
+var jst = time.FixedZone("Asia/Tokyo", 9*60*60)
+
+var invitationStatusLabels = map[model.InvitationStatus]string{
+	model.InvitationStatusPending:    "申請中",
+	model.InvitationStatusApproved:   "承認",
+	model.InvitationStatusRejected:   "却下",
+	model.InvitationStatusSent:       "承認(招待送信済み)",
+	model.InvitationStatusSendFailed: "承認(招待送信失敗)",
+	model.InvitationStatusSending:    "承認(招待送信中)",
+	model.InvitationStatusAccepted:   "承認(参加済み)",
+	model.InvitationStatusExpired:    "期限切れ",
+	model.InvitationStatusCancelled:  "取り消し",
+}
+
+func historyCommandMessage(message string) string {
+	return fmt.Sprintf("%s\n%s", message, historyCommandUsage)
+}
+
+func (h *BotHandler) history(p *payload.MessageCreated) {
+	ctx := context.Background()
+
+	mentionRawText, _ := checkIfBotMentioned(p, h.botUser.ID())
+	splitText := regexp.MustCompile(`\s+`).Split(strings.TrimSpace(strings.Replace(p.Message.PlainText, mentionRawText, "", 1)), -1)
+
+	if len(splitText) > 1 && slices.Contains([]string{"-h", "-help", "--help"}, splitText[1]) {
+		_, err := h.traqClient.PostMessage(ctx, p.Message.ChannelID,
+			historyCommandMessage("/history は、判定済みの招待の履歴を表示するためのコマンドです。"))
+		if err != nil {
+			logger.Println("failed to post message: ", err)
+		}
+		return
+	}
+
+	query, page, err := parseHistoryArgs(splitText[1:])
+	if err != nil {
+		_, err := h.traqClient.PostMessage(ctx, p.Message.ChannelID, historyCommandMessage(err.Error()))
+		if err != nil {
+			logger.Println("failed to post message: ", err)
+		}
+		return
+	}
+
+	// 次のページがあるか調べるために1件多く取得する
+	query.Limit = historyPageSize + 1
+	query.Offset = (page - 1) * historyPageSize
+	invitations, err := h.ir.GetInvitationHistory(ctx, query)
+	if err != nil {
+		logger.Println("failed to get invitation history: ", err)
+		return
+	}
+
+	hasNext := len(invitations) > historyPageSize
+	if hasNext {
+		invitations = invitations[:historyPageSize]
+	}
+
+	if len(invitations) == 0 {
+		_, err := h.traqClient.PostMessage(ctx, p.Message.ChannelID, "該当する招待はありません")
+		if err != nil {
+			logger.Println("failed to post message: ", err)
+		}
+		return
+	}
+
+	userNames := make(map[string]string)
+	deciders := make(map[string]string)
+
+	message := fmt.Sprintf("招待履歴 (%dページ目)\n\n", page)
+	message += "| 日時 | 結果 | 申請者 | traQ | GitHub | 判定者 | 申請 |\n"
+	message += "| --- | --- | --- | --- | --- | --- | --- |\n"
+	for _, inv := range invitations {
+		d, ok := deciders[inv.MessageID()]
+		if !ok {
+			d, err = h.decidersText(ctx, inv.MessageID(), userNames)
+			if err != nil {
+				logger.Println("failed to get deciders: ", err)
+				return
+			}
+			deciders[inv.MessageID()] = d
+		}
+
+		requesterName := "-"
+		if inv.Requester() != nil {
+			requesterName = inv.Requester().Name()
+		}
+
+		message += fmt.Sprintf("| %s | %s | %s | %s | %s | %s | https://q.trap.jp/messages/%s |\n",
+			formatTime(decidedAt(inv)), invitationStatusLabels[inv.Status()], requesterName,
+			strings.TrimPrefix(inv.TraqID(), "@"), inv.GitHubID(), d, inv.MessageID())
+	}
+
+	if hasNext {
+		message += fmt.Sprintf("\n続きは `--page %d` で表示できます", page+1)
+	}
+
+	_, err = h.traqClient.PostMessage(ctx, p.Message.ChannelID, message)
+	if err != nil {
+		logger.Println("failed to post message: ", err)
+	}
+}
+
+func parseHistoryArgs(args []string) (repository.InvitationHistoryQuery, int, error) {
+	var (
+		query                repository.InvitationHistoryQuery
+		sinceText, untilText string
+		page                 int
+	)
+
+	fs := flag.NewFlagSet("history", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	fs.StringVar(&query.TraqID, "traq", "", "traQ ID")
+	fs.StringVar(&query.GitHubID, "github", "", "GitHub ID")
+	fs.StringVar(&sinceText, "since", "", "この日以降に判定された招待")
+	fs.StringVar(&untilText, "until", "", "この日までに判定された招待")
+	fs.IntVar(&page, "page", 1, "ページ")
+
+	if err := fs.Parse(args); err != nil {
+		return query, 0, errors.New("引数が不正です")
+	}
+	if fs.NArg() > 0 {
+		return query, 0, fmt.Errorf("不明な引数があります: %s", strings.Join(fs.Args(), " "))
+	}
+	if page < 1 {
+		return query, 0, errors.New("ページは1以上を指定してください")
+	}
+
+	if sinceText != "" {
+		since, err := time.ParseInLocation(time.DateOnly, sinceText, jst)
+		if err != nil {
+			return query, 0, fmt.Errorf("日付の形式が不正です: %s", sinceText)
+		}
+		query.Since = since
+	}
+	if untilText != "" {
+		until, err := time.ParseInLocation(time.DateOnly, untilText, jst)
+		if err != nil {
+			return query, 0, fmt.Errorf("日付の形式が不正です: %s", untilText)
+		}
+		// その日の終わりまでを含める
+		query.Until = until.AddDate(0, 0, 1)
+	}
+
+	return query, page, nil
+}
+
+// decidersText は、判定に記録されたスタンプのうち、判定結果の側のスタンプを押したユーザーの名前を返す。
+// 却下にはvetoのスタンプも使われるので、承認スタンプ以外のスタンプを却下の側として扱う
+func (h *BotHandler) decidersText(ctx context.Context, messageID string, userNames map[string]string) (string, error) {
+	decision, err := h.dr.GetDecision(ctx, model.DecisionKindInvitation, messageID)
+	if errors.Is(err, repository.ErrRecordNotFound) {
+		return "-", nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to get decision: %w", err)
+	}
+
+	accepted := decision.Result() != model.DecisionResultRejected
+
+	names := make([]string, 0, len(decision.Votes()))
+	for _, vote := range decision.Votes() {
+		if (vote.StampID() == h.acceptStampID) != accepted {
+			continue
+		}
+
+		name, ok := userNames[vote.UserID()]
+		if !ok {
+			user, err := h.traqClient.GetUser(ctx, vote.UserID())
+			if err != nil {
+				return "", fmt.Errorf("failed to get user: %w", err)
+			}
+			name = user.Name()
+			userNames[vote.UserID()] = name
+		}
+
+		names = append(names, name)
+	}
+
+	if len(names) == 0 {
+		return "-", nil
+	}
+
+	return strings.Join(names, ", "), nil
+}
+
+// decidedAt は、招待が判定された日時を返す
+func decidedAt(inv *model.Invitation) time.Time {
+	for _, status := range []model.InvitationStatus{
+		model.InvitationStatusApproved,
+		model.InvitationStatusRejected,
+		model.InvitationStatusExpired,
+		model.InvitationStatusCancelled,
+	} {
+		if t := inv.TransitionedAt(status); !t.IsZero() {
+			return t
+		}
+	}
+
+	return time.Time{}
+}
+
+func formatTime(t time.Time) string {
+	if t.IsZero() {
+		return "-"
+	}
+
+	return t.In(jst).Format("2006/01/02 15:04")
+}
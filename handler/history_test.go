@@ -0,0 +1,192 @@
+package handler
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/traP-jp/members_bot/model"
+	"github.com/traP-jp/members_bot/repository"
+	repomock "github.com/traP-jp/members_bot/repository/mock"
+	"github.com/traP-jp/members_bot/service/mock"
+	"github.com/traPtitech/traq-ws-bot/payload"
+)
+
+func TestHistory(t *testing.T) {
+	t.Parallel()
+
+	botUserID := uuid.NewString()
+	acceptStampID := uuid.NewString()
+	rejectStampID := uuid.NewString()
+	vetoStampID := uuid.NewString()
+	adminID1 := uuid.NewString()
+	adminID2 := uuid.NewString()
+	messageID1 := uuid.NewString()
+	messageID2 := uuid.NewString()
+	decidedAt := time.Date(2024, 8, 22, 12, 3, 0, 0, jst)
+
+	invitations := []*model.Invitation{
+		model.NewInvitation(messageID1, "@ikura-hamu", "ikura-hamu",
+			model.WithStatus(model.InvitationStatusSent),
+			model.WithOrigin(model.NewUser(uuid.NewString(), "requester"), uuid.NewString(), uuid.NewString()),
+			model.WithTransitionedAt(model.InvitationStatusApproved, decidedAt)),
+		model.NewInvitation(messageID2, "H1rono_K", "H1rono",
+			model.WithStatus(model.InvitationStatusRejected),
+			model.WithTransitionedAt(model.InvitationStatusRejected, decidedAt.Add(-time.Hour))),
+	}
+	decisions := map[string]*model.Decision{
+		messageID1: model.NewDecision(messageID1, model.DecisionKindInvitation, model.DecisionResultApproved, []*model.Vote{
+			model.NewVote(adminID1, acceptStampID, decidedAt),
+			model.NewVote(adminID2, rejectStampID, decidedAt),
+			model.NewVote(adminID2, acceptStampID, decidedAt),
+		}, decidedAt),
+		// vetoのスタンプで却下された
+		messageID2: model.NewDecision(messageID2, model.DecisionKindInvitation, model.DecisionResultRejected, []*model.Vote{
+			model.NewVote(adminID2, acceptStampID, decidedAt.Add(-time.Hour)),
+			model.NewVote(adminID1, vetoStampID, decidedAt.Add(-time.Hour)),
+		}, decidedAt.Add(-time.Hour)),
+	}
+	userNames := map[string]string{adminID1: "admin1", adminID2: "admin2"}
+
+	type test struct {
+		plainText     string
+		invitations   []*model.Invitation
+		expectedQuery *repository.InvitationHistoryQuery
+		postText      string
+	}
+
+	testCases := map[string]test{
+		"特に問題なし": {
+			plainText:   "@BOT_traP-jp /history",
+			invitations: invitations,
+			expectedQuery: &repository.InvitationHistoryQuery{
+				Limit: historyPageSize + 1,
+			},
+			postText: fmt.Sprintf(`招待履歴 (1ページ目)
+
+| 日時 | 結果 | 申請者 | traQ | GitHub | 判定者 | 申請 |
+| --- | --- | --- | --- | --- | --- | --- |
+| 2024/08/22 12:03 | 承認(招待送信済み) | requester | ikura-hamu | ikura-hamu | admin1, admin2 | https://q.trap.jp/messages/%s |
+| 2024/08/22 11:03 | 却下 | - | H1rono_K | H1rono | admin1 | https://q.trap.jp/messages/%s |
+`, messageID1, messageID2),
+		},
+		"絞り込みとページ指定": {
+			plainText:   "@BOT_traP-jp /履歴 --traq @ikura-hamu --github ikura-hamu --since 2024-08-01 --until 2024-08-31 --page 2",
+			invitations: []*model.Invitation{},
+			expectedQuery: &repository.InvitationHistoryQuery{
+				TraqID:   "@ikura-hamu",
+				GitHubID: "ikura-hamu",
+				Since:    time.Date(2024, 8, 1, 0, 0, 0, 0, jst),
+				Until:    time.Date(2024, 9, 1, 0, 0, 0, 0, jst),
+				Limit:    historyPageSize + 1,
+				Offset:   historyPageSize,
+			},
+			postText: "該当する招待はありません",
+		},
+		"次のページがある": {
+			plainText: "@BOT_traP-jp /history",
+			invitations: func() []*model.Invitation {
+				invs := make([]*model.Invitation, 0, historyPageSize+1)
+				for range historyPageSize + 1 {
+					invs = append(invs, invitations[1])
+				}
+				return invs
+			}(),
+			expectedQuery: &repository.InvitationHistoryQuery{
+				Limit: historyPageSize + 1,
+			},
+		},
+		"日付の形式が不正": {
+			plainText: "@BOT_traP-jp /history --since 2024/08/01",
+			postText:  historyCommandMessage("日付の形式が不正です: 2024/08/01"),
+		},
+		"不明な引数": {
+			plainText: "@BOT_traP-jp /history ikura-hamu",
+			postText:  historyCommandMessage("不明な引数があります: ikura-hamu"),
+		},
+		"ヘルプ": {
+			plainText: "@BOT_traP-jp /history -h",
+			postText:  historyCommandMessage("/history は、判定済みの招待の履歴を表示するためのコマンドです。"),
+		},
+	}
+
+	for name, test := range testCases {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			traqMock := &mock.TraqMock{
+				PostMessageFunc: func(context.Context, string, string) (string, error) {
+					return "", nil
+				},
+				GetUserFunc: func(_ context.Context, userID string) (*model.User, error) {
+					return model.NewUser(userID, userNames[userID]), nil
+				},
+			}
+			invRepoMock := &repomock.InvitationMock{
+				GetInvitationHistoryFunc: func(context.Context, repository.InvitationHistoryQuery) ([]*model.Invitation, error) {
+					return test.invitations, nil
+				},
+			}
+			decisionRepoMock := &repomock.DecisionMock{
+				GetDecisionFunc: func(_ context.Context, kind model.DecisionKind, messageID string) (*model.Decision, error) {
+					decision, ok := decisions[messageID]
+					if kind != model.DecisionKindInvitation {
+						ok = false
+					}
+					if !ok {
+						return nil, repository.ErrRecordNotFound
+					}
+					return decision, nil
+				},
+			}
+
+			bh := &BotHandler{
+				traqClient: traqMock,
+				ir:         invRepoMock,
+				dr:         decisionRepoMock,
+				botUser:    model.NewUser(botUserID, "BOT_traP-jp"),
+				Config: &Config{
+					acceptStampID: acceptStampID,
+					rejectStampID: rejectStampID,
+				},
+			}
+
+			payload := &payload.MessageCreated{
+				Message: payload.Message{
+					PlainText: test.plainText,
+					ID:        uuid.NewString(),
+					ChannelID: uuid.NewString(),
+					Embedded:  []payload.EmbeddedInfo{{Type: "user", Raw: "@BOT_traP-jp", ID: botUserID}},
+					CreatedAt: time.Now(),
+					UpdatedAt: time.Now(),
+				},
+				Base: payload.Base{EventTime: time.Now()},
+			}
+			bh.history(payload)
+
+			if test.expectedQuery != nil {
+				assert.Len(t, invRepoMock.GetInvitationHistoryCalls(), 1)
+				query := invRepoMock.GetInvitationHistoryCalls()[0].Query
+				assert.Equal(t, test.expectedQuery.TraqID, query.TraqID)
+				assert.Equal(t, test.expectedQuery.GitHubID, query.GitHubID)
+				assert.True(t, test.expectedQuery.Since.Equal(query.Since))
+				assert.True(t, test.expectedQuery.Until.Equal(query.Until))
+				assert.Equal(t, test.expectedQuery.Limit, query.Limit)
+				assert.Equal(t, test.expectedQuery.Offset, query.Offset)
+			} else {
+				assert.Len(t, invRepoMock.GetInvitationHistoryCalls(), 0)
+			}
+
+			assert.Len(t, traqMock.PostMessageCalls(), 1)
+			assert.Equal(t, payload.Message.ChannelID, traqMock.PostMessageCalls()[0].ChannelID)
+			if test.postText != "" {
+				assert.Equal(t, test.postText, traqMock.PostMessageCalls()[0].Text)
+			} else {
+				assert.Contains(t, traqMock.PostMessageCalls()[0].Text, "続きは `--page 2` で表示できます")
+			}
+		})
+	}
+}
@@ -0,0 +1,138 @@
+package handler
+
+import (
+	"context"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/traPtitech/traq-ws-bot/payload"
+)
+
+// 招待できるユーザーだけで申請するかの確認を待つ時間
+const inviteConfirmationTimeout = 30 * time.Minute
+
+// inviteConfirmation は、招待できるユーザーだけで申請するかの確認を待っている申請
+type inviteConfirmation struct {
+	p         *payload.MessageCreated
+	invitees  []*invitee
+	expiresAt time.Time
+}
+
+// inviteConfirmations は、確認を求めたメッセージのIDごとに、確認を待っている申請を持つ。
+// botが再起動すると失われるが、その場合は申請し直してもらう
+type inviteConfirmations struct {
+	mu sync.Mutex
+	m  map[string]*inviteConfirmation
+}
+
+func (c *inviteConfirmations) add(messageID string, confirmation *inviteConfirmation) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.m == nil {
+		c.m = make(map[string]*inviteConfirmation)
+	}
+	// スタンプが押されないまま期限が切れた申請が残り続けないように、追加するときに消す
+	for id, conf := range c.m {
+		if time.Now().After(conf.expiresAt) {
+			delete(c.m, id)
+		}
+	}
+	c.m[messageID] = confirmation
+}
+
+func (c *inviteConfirmations) get(messageID string) (*inviteConfirmation, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	confirmation, ok := c.m[messageID]
+	return confirmation, ok
+}
+
+// remove は、確認を待っている申請を消す。他のイベントで既に消されていた場合はfalseを返す
+func (c *inviteConfirmations) remove(messageID string) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	_, ok := c.m[messageID]
+	delete(c.m, messageID)
+	return ok
+}
+
+// reportInvalidInvitees は、招待できないユーザーと確認できなかったユーザーを申請したチャンネルで伝える。
+// 招待できるユーザーがいれば、そのユーザーだけで申請するかを、報告を見た申請者にスタンプで確認する。
+// 確認できなかったユーザーがいる場合は、招待できるかどうか分からないので申請しない
+func (h *BotHandler) reportInvalidInvitees(ctx context.Context, p *payload.MessageCreated, invitees []*invitee, validInvitees []*invitee) {
+	confirm := len(validInvitees) > 0 && !slices.ContainsFunc(invitees, func(inv *invitee) bool { return !inv.checked() })
+
+	messageID, err := h.traqClient.PostMessage(ctx, p.Message.ChannelID, invalidInviteesMessage(invitees, confirm))
+	if err != nil {
+		logger.Println("failed to post message: ", err)
+		return
+	}
+	if !confirm {
+		return
+	}
+
+	h.inviteConfirmations.add(messageID, &inviteConfirmation{
+		p:         p,
+		invitees:  validInvitees,
+		expiresAt: time.Now().Add(inviteConfirmationTimeout),
+	})
+
+	err = h.traqClient.AddStamp(ctx, messageID, h.acceptStampID, 1)
+	if err != nil {
+		logger.Println("failed to add stamp: ", err)
+		return
+	}
+	err = h.traqClient.AddStamp(ctx, messageID, h.rejectStampID, 1)
+	if err != nil {
+		logger.Println("failed to add stamp: ", err)
+		return
+	}
+}
+
+// confirmInvite は、messageIDが招待できるユーザーだけで申請するかの確認のメッセージの場合に、
+// 申請者のスタンプに従って申請し、trueを返す。申請者以外のスタンプは無視する
+func (h *BotHandler) confirmInvite(ctx context.Context, messageID string, stamps []payload.MessageStamp) bool {
+	confirmation, ok := h.inviteConfirmations.get(messageID)
+	if !ok {
+		return false
+	}
+
+	requesterID := confirmation.p.Message.User.ID
+	stamped := func(stampID string) bool {
+		return slices.ContainsFunc(stamps, func(stamp payload.MessageStamp) bool {
+			return stamp.UserID == requesterID && stamp.StampID == stampID
+		})
+	}
+	accepted, rejected := stamped(h.acceptStampID), stamped(h.rejectStampID)
+	if !accepted && !rejected {
+		return true
+	}
+
+	// 同じメッセージへのイベントが同時に届いても、1度だけ申請する
+	if !h.inviteConfirmations.remove(messageID) {
+		return true
+	}
+
+	channelID := confirmation.p.Message.ChannelID
+	var text string
+	switch {
+	case time.Now().After(confirmation.expiresAt):
+		text = "確認の期限が切れたため、申請しませんでした。もう一度 /invite で申請してください"
+	case rejected:
+		text = "申請しませんでした"
+	default:
+		h.requestInvitations(ctx, confirmation.p, confirmation.invitees)
+		text = "招待できるユーザーだけで申請しました"
+	}
+
+	_, err := h.traqClient.PostMessage(ctx, channelID, text)
+	if err != nil {
+		logger.Println("failed to post message: ", err)
+	}
+
+	return true
+}
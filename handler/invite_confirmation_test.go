@@ -0,0 +1,164 @@
+package handler
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/traP-jp/members_bot/model"
+	"github.com/traP-jp/members_bot/repository"
+	repomock "github.com/traP-jp/members_bot/repository/mock"
+	"github.com/traP-jp/members_bot/service/mock"
+	"github.com/traPtitech/traq-ws-bot/payload"
+)
+
+func TestConfirmInvite(t *testing.T) {
+	t.Parallel()
+
+	botUserID := uuid.NewString()
+	requesterID := uuid.NewString()
+	originChannelID := uuid.NewString()
+	originMessageID := uuid.NewString()
+	confirmMessageID := uuid.NewString()
+	invitationMessageID := uuid.NewString()
+	ikuraHamu := model.NewTraqUser(uuid.NewString(), "ikura-hamu", "いくらはむ", false, model.TraqUserStateActive)
+
+	type test struct {
+		messageID string
+		stamps    []payload.MessageStamp
+		expired   bool
+		// 確認を待っている申請が残るか
+		remaining bool
+		// 招待できるユーザーだけで申請するか
+		requested bool
+		// 確認のメッセージではなく、招待の申請として判定するか
+		decided  bool
+		postText string
+	}
+
+	testCases := map[string]test{
+		"申請者が承認スタンプを押すと、招待できるユーザーだけで申請する": {
+			messageID: confirmMessageID,
+			stamps: []payload.MessageStamp{
+				{StampID: "acceptStampID", UserID: botUserID},
+				{StampID: "acceptStampID", UserID: requesterID},
+			},
+			requested: true,
+			postText:  "招待できるユーザーだけで申請しました",
+		},
+		"申請者が却下スタンプを押すと申請しない": {
+			messageID: confirmMessageID,
+			stamps:    []payload.MessageStamp{{StampID: "rejectStampID", UserID: requesterID}},
+			postText:  "申請しませんでした",
+		},
+		"申請者以外のスタンプは無視する": {
+			messageID: confirmMessageID,
+			stamps: []payload.MessageStamp{
+				{StampID: "acceptStampID", UserID: botUserID},
+				{StampID: "acceptStampID", UserID: uuid.NewString()},
+			},
+			remaining: true,
+		},
+		"期限が切れていたら申請しない": {
+			messageID: confirmMessageID,
+			stamps:    []payload.MessageStamp{{StampID: "acceptStampID", UserID: requesterID}},
+			expired:   true,
+			postText:  "確認の期限が切れたため、申請しませんでした。もう一度 /invite で申請してください",
+		},
+		"確認のメッセージでなければ招待の申請として判定する": {
+			messageID: uuid.NewString(),
+			stamps:    []payload.MessageStamp{{StampID: "acceptStampID", UserID: requesterID}},
+			remaining: true,
+			decided:   true,
+		},
+	}
+
+	for name, test := range testCases {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			traqMock := &mock.TraqMock{
+				PostMessageFunc: func(context.Context, string, string) (string, error) {
+					return invitationMessageID, nil
+				},
+				AddStampFunc: func(context.Context, string, string, int) error {
+					return nil
+				},
+			}
+			invRepoMock := &repomock.InvitationMock{
+				CreateInvitationFunc: func(context.Context, []*model.Invitation) error {
+					return nil
+				},
+				GetInvitationsFunc: func(context.Context, string) ([]*model.Invitation, error) {
+					return nil, repository.ErrRecordNotFound
+				},
+			}
+
+			bh := &BotHandler{
+				traqClient: traqMock,
+				ir:         invRepoMock,
+				rr: &repomock.RemovalMock{
+					GetRemovalFunc: func(context.Context, string) (*model.Removal, error) {
+						return nil, repository.ErrRecordNotFound
+					},
+				},
+				botUser: model.NewUser(botUserID, "BOT_traP-jp"),
+				Config: &Config{
+					botChannelID:   "botChannelID",
+					adminGroupName: "GitHub_org_Admin",
+					acceptStampID:  "acceptStampID",
+					rejectStampID:  "rejectStampID",
+				},
+			}
+
+			expiresAt := time.Now().Add(inviteConfirmationTimeout)
+			if test.expired {
+				expiresAt = time.Now().Add(-time.Minute)
+			}
+			bh.inviteConfirmations.add(confirmMessageID, &inviteConfirmation{
+				p: &payload.MessageCreated{Message: payload.Message{
+					ID:        originMessageID,
+					ChannelID: originChannelID,
+					User:      payload.User{ID: requesterID, Name: "requester"},
+				}},
+				invitees:  []*invitee{{traQID: "@ikura-hamu", gitHubID: "ikura-hamu", traqUser: ikuraHamu, gitHubUserID: 1001}},
+				expiresAt: expiresAt,
+			})
+
+			bh.AcceptOrReject(&payload.BotMessageStampsUpdated{MessageID: test.messageID, Stamps: test.stamps})
+
+			_, remaining := bh.inviteConfirmations.get(confirmMessageID)
+			assert.Equal(t, test.remaining, remaining)
+
+			createCalls := invRepoMock.CreateInvitationCalls()
+			if test.requested {
+				require.Len(t, createCalls, 1)
+				expected := model.NewInvitation(invitationMessageID, "ikura-hamu", "ikura-hamu",
+					model.WithOrigin(model.NewUser(requesterID, "requester"), originChannelID, originMessageID),
+					model.WithGitHubUserID(1001), model.WithTraqUserID(ikuraHamu.ID()), model.WithTraqDisplayName("いくらはむ"))
+				assert.Equal(t, []*model.Invitation{expected}, createCalls[0].Invitations)
+			} else {
+				assert.Empty(t, createCalls)
+			}
+
+			if test.decided {
+				assert.Len(t, invRepoMock.GetInvitationsCalls(), 1)
+			} else {
+				assert.Empty(t, invRepoMock.GetInvitationsCalls())
+			}
+
+			postMessageCalls := traqMock.PostMessageCalls()
+			if test.postText == "" {
+				assert.Empty(t, postMessageCalls)
+				return
+			}
+			require.NotEmpty(t, postMessageCalls)
+			last := postMessageCalls[len(postMessageCalls)-1]
+			assert.Equal(t, originChannelID, last.ChannelID)
+			assert.Equal(t, test.postText, last.Text)
+		})
+	}
+}
@@ -0,0 +1,98 @@
+package handler
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/traP-jp/members_bot/model"
+	"github.com/traPtitech/traq-ws-bot/payload"
+)
+
+// 招待されるユーザーを同時に確認する数。traQとGitHubのAPIに負荷をかけすぎないようにする
+const inviteeCheckWorkers = 4
+
+// invitee は、/invite で指定された1人分のtraQユーザーとGitHubユーザーの確認結果
+type invitee struct {
+	traQID       string
+	gitHubID     string
+	traqUser     *model.TraqUser
+	gitHubUserID int64
+	// 招待できない理由。招待できる場合は空
+	problems []string
+	// traQやGitHubのAPIのエラーで確認できなかったこと。招待できない理由とは分けて伝える
+	checkErrors []string
+}
+
+func (i *invitee) valid() bool {
+	return len(i.problems) == 0 && i.checked()
+}
+
+// checked は、APIのエラーなく確認できたかを返す
+func (i *invitee) checked() bool {
+	return len(i.checkErrors) == 0
+}
+
+// checkInvitees は、traQIDとGitHubIDの組を同時に最大 inviteeCheckWorkers 人ずつ確認し、指定された順に結果を返す
+func (h *BotHandler) checkInvitees(ctx context.Context, p *payload.MessageCreated, traQIDs []string, gitHubIDs []string) []*invitee {
+	invitees := make([]*invitee, len(traQIDs))
+	indexes := make(chan int)
+
+	var wg sync.WaitGroup
+	for range min(inviteeCheckWorkers, len(traQIDs)) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range indexes {
+				invitees[i] = h.checkInvitee(ctx, p, traQIDs[i], gitHubIDs[i])
+			}
+		}()
+	}
+
+	for i := range traQIDs {
+		indexes <- i
+	}
+	close(indexes)
+	wg.Wait()
+
+	return invitees
+}
+
+// checkInvitee は、1人分のtraQユーザーとGitHubユーザーを招待できるか確認する。
+// 問題があっても、まとめて伝えられるように残りの確認を続ける
+func (h *BotHandler) checkInvitee(ctx context.Context, p *payload.MessageCreated, traQID string, gitHubID string) *invitee {
+	inv := &invitee{traQID: traQID, gitHubID: gitHubID}
+
+	traqUser, err := h.resolveTraqUser(ctx, p, traQID)
+	if err != nil {
+		logger.Println("failed to get traQ user: ", err)
+		inv.checkErrors = append(inv.checkErrors, fmt.Sprintf("traQユーザー %s を確認できませんでした", traQID))
+	} else if problem := traqUserProblem(traqUser); problem != "" {
+		inv.problems = append(inv.problems, fmt.Sprintf("traQユーザー %s %s", traQID, problem))
+	}
+	inv.traqUser = traqUser
+
+	// ユーザー名は変えられるので、変わらないユーザーIDも記録しておく
+	inv.gitHubUserID, err = h.githubClient.GetUserID(ctx, gitHubID)
+	if err != nil {
+		logger.Println("failed to get GitHub user ID: ", err)
+		inv.checkErrors = append(inv.checkErrors, fmt.Sprintf("GitHubユーザー %s を確認できませんでした", gitHubID))
+		return inv
+	}
+	if inv.gitHubUserID == 0 {
+		inv.problems = append(inv.problems, fmt.Sprintf("GitHubユーザー %s は存在しません", gitHubID))
+		return inv
+	}
+
+	inOrg, err := h.githubClient.CheckUserInOrg(ctx, gitHubID)
+	if err != nil {
+		logger.Println("failed to check user in org: ", err)
+		inv.checkErrors = append(inv.checkErrors, fmt.Sprintf("GitHubユーザー %s を確認できませんでした", gitHubID))
+		return inv
+	}
+	if inOrg {
+		inv.problems = append(inv.problems, fmt.Sprintf("GitHubユーザー %s は既に %s に所属しています", gitHubID, h.githubClient.OrgName()))
+	}
+
+	return inv
+}
@@ -0,0 +1,95 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/traP-jp/members_bot/model"
+	"github.com/traP-jp/members_bot/service/mock"
+	"github.com/traPtitech/traq-ws-bot/payload"
+)
+
+func TestCheckInvitees(t *testing.T) {
+	t.Parallel()
+
+	var (
+		mu         sync.Mutex
+		running    int
+		maxRunning int
+	)
+
+	traqMock := &mock.TraqMock{
+		GetTraqUserByNameFunc: func(ctx context.Context, name string) (*model.TraqUser, error) {
+			mu.Lock()
+			running++
+			maxRunning = max(maxRunning, running)
+			mu.Unlock()
+
+			time.Sleep(10 * time.Millisecond)
+
+			mu.Lock()
+			running--
+			mu.Unlock()
+
+			return model.NewTraqUser(name+"-id", name, name, false, model.TraqUserStateActive), nil
+		},
+	}
+	gitHubMock := &mock.GitHubMock{
+		GetUserIDFunc: func(ctx context.Context, userID string) (int64, error) {
+			if userID == "github-3" {
+				return 0, nil
+			}
+			if userID == "github-7" {
+				return 0, errors.New("GitHub API error")
+			}
+			return int64(len(userID)), nil
+		},
+		CheckUserInOrgFunc: func(ctx context.Context, userID string) (bool, error) {
+			return userID == "github-5", nil
+		},
+		OrgNameFunc: func() string {
+			return "traP-jp"
+		},
+	}
+
+	bh := &BotHandler{
+		traqClient:   traqMock,
+		githubClient: gitHubMock,
+	}
+
+	traQIDs := make([]string, 0, 10)
+	gitHubIDs := make([]string, 0, 10)
+	for i := range 10 {
+		traQIDs = append(traQIDs, fmt.Sprintf("traq-%d", i))
+		gitHubIDs = append(gitHubIDs, fmt.Sprintf("github-%d", i))
+	}
+
+	invitees := bh.checkInvitees(context.Background(), &payload.MessageCreated{}, traQIDs, gitHubIDs)
+
+	require.Len(t, invitees, 10)
+	for i, inv := range invitees {
+		assert.Equal(t, traQIDs[i], inv.traqUser.Name())
+		assert.Equal(t, gitHubIDs[i], inv.gitHubID)
+		switch i {
+		case 3:
+			assert.Equal(t, []string{"GitHubユーザー github-3 は存在しません"}, inv.problems)
+		case 5:
+			assert.Equal(t, []string{"GitHubユーザー github-5 は既に traP-jp に所属しています"}, inv.problems)
+		case 7:
+			// APIのエラーは、招待できない理由とは分ける
+			assert.Empty(t, inv.problems)
+			assert.Equal(t, []string{"GitHubユーザー github-7 を確認できませんでした"}, inv.checkErrors)
+			assert.False(t, inv.valid())
+		default:
+			assert.True(t, inv.valid())
+		}
+	}
+	assert.LessOrEqual(t, maxRunning, inviteeCheckWorkers)
+	assert.Greater(t, maxRunning, 1)
+}
@@ -0,0 +1,44 @@
+package handler
+
+import (
+	"context"
+	"time"
+)
+
+const (
+	// 申請中の招待を期限切れにするか確認する間隔
+	expireJobInterval = time.Hour
+	// 申請中の招待をリマインドするか確認する間隔
+	remindJobInterval = time.Hour
+	// GitHubの招待が承諾されたか確認する間隔
+	gitHubInvitationJobInterval = time.Hour
+	// GitHubのユーザー名が変わっていないか確認する間隔
+	gitHubLoginJobInterval = 24 * time.Hour
+	// 届かなかったスタンプのイベントを判定し直す間隔。接続したときにも判定し直すが、それを見逃した場合に備える
+	reconcileJobInterval = 10 * time.Minute
+)
+
+// StartJobs は、定期的に実行する処理を開始する。ctxがキャンセルされると終了する
+func (h *BotHandler) StartJobs(ctx context.Context) {
+	go runPeriodically(ctx, expireJobInterval, h.ExpireInvitations)
+	go runPeriodically(ctx, remindJobInterval, h.RemindInvitations)
+	go runPeriodically(ctx, gitHubInvitationJobInterval, h.CheckGitHubInvitations)
+	go runPeriodically(ctx, gitHubLoginJobInterval, h.RefreshGitHubLogins)
+	go runPeriodically(ctx, reconcileJobInterval, h.ReconcileInvitations)
+}
+
+// runPeriodically は、fnをすぐに1回実行し、その後intervalごとに実行する
+func runPeriodically(ctx context.Context, interval time.Duration, fn func(ctx context.Context)) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		fn(ctx)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
@@ -37,6 +37,55 @@ func (h *BotHandler) MessageCreated(p *payload.MessageCreated) {
 			},
 			fn: h.list,
 		},
+		{
+			filter: func(p *payload.MessageCreated) bool {
+				ok, _ := regexp.MatchString(`^/(reason|理由)$`, splitText[0])
+				return ok
+			},
+			fn: h.reason,
+		},
+		{
+			filter: func(p *payload.MessageCreated) bool {
+				ok, _ := regexp.MatchString(`^/(resend|再送)$`, splitText[0])
+				return ok
+			},
+			fn: h.resend,
+		},
+		{
+			filter: func(p *payload.MessageCreated) bool {
+				ok, _ := regexp.MatchString(`^/(cancel|取り消し)$`, splitText[0])
+				return ok
+			},
+			fn: h.cancel,
+		},
+		{
+			filter: func(p *payload.MessageCreated) bool {
+				ok, _ := regexp.MatchString(`^/(remove|除名)$`, splitText[0])
+				return ok
+			},
+			fn: h.remove,
+		},
+		{
+			filter: func(p *payload.MessageCreated) bool {
+				ok, _ := regexp.MatchString(`^/(retry|再試行)$`, splitText[0])
+				return ok
+			},
+			fn: h.retry,
+		},
+		{
+			filter: func(p *payload.MessageCreated) bool {
+				ok, _ := regexp.MatchString(`^/(whois|誰)$`, splitText[0])
+				return ok
+			},
+			fn: h.whois,
+		},
+		{
+			filter: func(p *payload.MessageCreated) bool {
+				ok, _ := regexp.MatchString(`^/(history|履歴)$`, splitText[0])
+				return ok
+			},
+			fn: h.history,
+		},
 		{
 			filter: func(p *payload.MessageCreated) bool {
 				ok, _ := regexp.MatchString(`^/(help|ヘルプ|助けて)$`, splitText[0])
@@ -83,12 +132,46 @@ func (h *BotHandler) MessageCreated(p *payload.MessageCreated) {
 const (
 	inviteCommandUsage = "`@BOT_traP-jp /(invite|招待) <traQID> <GitHubID> ...`"
 	listCommandUsage   = "`@BOT_traP-jp /(list|確認)`"
+	// 申請者本人や招待されるユーザー本人のスタンプは判定に数えない
+	selfVoteNote = "※申請者と招待されるユーザー本人のスタンプは数えません"
 )
 
 func inviteCommandMessage(message string) string {
 	return fmt.Sprintf("%s\n%s", message, inviteCommandUsage)
 }
 
+// invalidInviteesMessage は、招待できないユーザーとその理由をまとめたメッセージを返す。
+// APIのエラーで確認できなかったユーザーは、招待できないユーザーとは分けて伝える。
+// confirmがtrueの場合は、招待できるユーザーだけで申請するかをスタンプで確認することを伝える
+func invalidInviteesMessage(invitees []*invitee, confirm bool) string {
+	var problems, checkErrors string
+	for _, inv := range invitees {
+		for _, problem := range inv.problems {
+			problems += fmt.Sprintf("- %s\n", problem)
+		}
+		for _, checkErr := range inv.checkErrors {
+			checkErrors += fmt.Sprintf("- %s\n", checkErr)
+		}
+	}
+
+	var message string
+	switch {
+	case checkErrors != "":
+		message = "確認できなかったユーザーがいるため、申請しませんでした。時間をおいてもう一度試してください\n" + checkErrors
+		if problems != "" {
+			message += "招待できないユーザー\n" + problems
+		}
+	case confirm:
+		message = "招待できないユーザーがいます\n" + problems +
+			fmt.Sprintf("招待できるユーザーだけで申請する場合は、%d分以内にこのメッセージに承認スタンプを押してください。申請しない場合は却下スタンプを押してください\n",
+				int(inviteConfirmationTimeout.Minutes()))
+	default:
+		message = "招待できないユーザーがいるため、申請しませんでした\n" + problems
+	}
+
+	return strings.TrimSuffix(message, "\n")
+}
+
 func listCommandMessage(message string) string {
 	return fmt.Sprintf("%s\n%s", message, listCommandUsage)
 }
@@ -128,61 +211,62 @@ func (h *BotHandler) invite(p *payload.MessageCreated) {
 	traQIDs := make([]string, 0, len(splitText)/2)
 	gitHubIDs := make([]string, 0, len(splitText)/2)
 	for i := 0; i < len(splitText); i += 2 {
-		traQID := splitText[i]
-		gitHubID := splitText[i+1]
-
-		exist, err := h.githubClient.CheckUserExist(ctx, gitHubID)
-		if err != nil {
-			logger.Println("failed to check user exist: ", err)
-			return
-		}
-		if !exist {
-			_, err := h.traqClient.PostMessage(ctx, p.Message.ChannelID,
-				fmt.Sprintf("GitHubユーザー %s は存在しません", gitHubID))
-			if err != nil {
-				logger.Println("failed to post message: ", err)
-			}
+		traQIDs = append(traQIDs, splitText[i])
+		gitHubIDs = append(gitHubIDs, splitText[i+1])
+	}
 
-			return
-		}
+	invitees := h.checkInvitees(ctx, p, traQIDs, gitHubIDs)
+	validInvitees := slices.DeleteFunc(slices.Clone(invitees), func(inv *invitee) bool { return !inv.valid() })
+	if len(validInvitees) < len(invitees) {
+		h.reportInvalidInvitees(ctx, p, invitees, validInvitees)
+		return
+	}
 
-		inOrg, err := h.githubClient.CheckUserInOrg(ctx, gitHubID)
-		if err != nil {
-			logger.Println("failed to check user in org: ", err)
-			return
-		}
+	h.requestInvitations(ctx, p, validInvitees)
+}
 
-		if inOrg {
-			_, err := h.traqClient.PostMessage(ctx, p.Message.ChannelID,
-				fmt.Sprintf("GitHubユーザー %s は既に %s に所属しています", gitHubID, h.githubClient.OrgName()))
-			if err != nil {
-				logger.Println("failed to post message: ", err)
-			}
+// requestInvitations は、確認できたユーザーの招待の承認・却下を求めるメッセージを投稿する
+func (h *BotHandler) requestInvitations(ctx context.Context, p *payload.MessageCreated, validInvitees []*invitee) {
+	origin := model.WithOrigin(model.NewUser(p.Message.User.ID, p.Message.User.Name), p.Message.ChannelID, p.Message.ID)
 
-			return
-		}
-
-		traQIDs = append(traQIDs, traQID)
-		gitHubIDs = append(gitHubIDs, gitHubID)
+	if len(validInvitees) == 1 {
+		invitationMessage := fmt.Sprintf("@%s\n@%s https://github.com/%s\nhttps://q.trap.jp/messages/%s\n%s",
+			h.adminGroupName, validInvitees[0].traqUser.Name(), validInvitees[0].gitHubID, p.Message.ID, selfVoteNote)
+		h.requestInvitation(ctx, invitationMessage, validInvitees[0].traqUser, validInvitees[0].gitHubID,
+			origin, model.WithGitHubUserID(validInvitees[0].gitHubUserID))
+		return
 	}
 
-	invitationMessage := fmt.Sprintf("@%s\n", h.adminGroupName)
-	for i := range traQIDs {
-		invitationMessage += fmt.Sprintf("%s https://github.com/%s\n", traQIDs[i], gitHubIDs[i])
+	// 複数人の場合は、1人ずつ承認・却下できるように、まとめのメッセージの後に1人ずつメッセージを投稿する
+	summaryMessage := fmt.Sprintf("@%s\n%d人の招待の申請です。続くメッセージで1人ずつ承認・却下してください\n", h.adminGroupName, len(validInvitees))
+	for i := range validInvitees {
+		summaryMessage += fmt.Sprintf("@%s https://github.com/%s\n", validInvitees[i].traqUser.Name(), validInvitees[i].gitHubID)
 	}
-	invitationMessage += fmt.Sprintf("https://q.trap.jp/messages/%s", p.Message.ID)
+	summaryMessage += fmt.Sprintf("https://q.trap.jp/messages/%s\n%s", p.Message.ID, selfVoteNote)
 
-	messageID, err := h.traqClient.PostMessage(ctx, h.botChannelID, invitationMessage)
+	_, err := h.traqClient.PostMessage(ctx, h.botChannelID, summaryMessage)
 	if err != nil {
 		logger.Printf("failed to post message: %v", err)
+		return
+	}
+
+	for i := range validInvitees {
+		invitationMessage := fmt.Sprintf("@%s https://github.com/%s", validInvitees[i].traqUser.Name(), validInvitees[i].gitHubID)
+		h.requestInvitation(ctx, invitationMessage, validInvitees[i].traqUser, validInvitees[i].gitHubID,
+			origin, model.WithGitHubUserID(validInvitees[i].gitHubUserID))
 	}
+}
 
-	invitations := make([]*model.Invitation, 0, len(splitText)/2)
-	for i := range traQIDs {
-		invitations = append(invitations, model.NewInvitation(messageID, traQIDs[i], gitHubIDs[i]))
+// requestInvitation は、1人分の招待の承認・却下を求めるメッセージを投稿し、招待を記録する
+func (h *BotHandler) requestInvitation(ctx context.Context, message string, traqUser *model.TraqUser, gitHubID string, opts ...model.InvitationOption) {
+	messageID, err := h.traqClient.PostMessage(ctx, h.botChannelID, message)
+	if err != nil {
+		logger.Printf("failed to post message: %v", err)
+		return
 	}
 
-	err = h.ir.CreateInvitation(ctx, invitations)
+	opts = append(opts, model.WithTraqUserID(traqUser.ID()), model.WithTraqDisplayName(traqUser.DisplayName()))
+	err = h.ir.CreateInvitation(ctx, []*model.Invitation{model.NewInvitation(messageID, traqUser.Name(), gitHubID, opts...)})
 	if err != nil {
 		logger.Println("failed to create invitation: ", err)
 		return
@@ -198,7 +282,6 @@ func (h *BotHandler) invite(p *payload.MessageCreated) {
 		logger.Println("failed to add stamp: ", err)
 		return
 	}
-
 }
 
 func (h *BotHandler) list(p *payload.MessageCreated) {
@@ -216,7 +299,7 @@ func (h *BotHandler) list(p *payload.MessageCreated) {
 		return
 	}
 
-	invitations, err := h.ir.GetAllInvitations(ctx)
+	invitations, err := h.ir.GetInvitationsByStatus(ctx, model.InvitationStatusPending)
 	if err != nil {
 		logger.Println("failed to get invitations: ", err)
 		return
@@ -244,6 +327,33 @@ func (h *BotHandler) help(p *payload.MessageCreated) {
 	}
 }
 
+// resolveTraqUser は、traQIDのユーザーを返す。
+// メッセージ中でメンションされていればそのUUIDで、されていなければ名前で探す。ユーザーがいない場合はnil
+func (h *BotHandler) resolveTraqUser(ctx context.Context, p *payload.MessageCreated, traQID string) (*model.TraqUser, error) {
+	name := strings.TrimPrefix(traQID, "@")
+	for _, embed := range p.Message.Embedded {
+		if embed.Type == "user" && strings.TrimPrefix(embed.Raw, "@") == name {
+			return h.traqClient.GetTraqUser(ctx, embed.ID)
+		}
+	}
+
+	return h.traqClient.GetTraqUserByName(ctx, name)
+}
+
+// traqUserProblem は、traQユーザーを招待できない理由を返す。招待できる場合は空文字列
+func traqUserProblem(user *model.TraqUser) string {
+	switch {
+	case user == nil:
+		return "は存在しません"
+	case user.Bot():
+		return "はbotです"
+	case user.State() != model.TraqUserStateActive:
+		return "は利用できないアカウントです"
+	default:
+		return ""
+	}
+}
+
 func checkIfBotMentioned(p *payload.MessageCreated, botUserID string) (string, bool) {
 	for _, embed := range p.Message.Embedded {
 		if embed.Type == "user" && embed.ID == botUserID {
@@ -2,12 +2,15 @@ package handler
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"slices"
 	"testing"
 	"time"
 
 	"github.com/google/uuid"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 	"github.com/traP-jp/members_bot/model"
 	repomock "github.com/traP-jp/members_bot/repository/mock"
 	"github.com/traP-jp/members_bot/service/mock"
@@ -20,16 +23,40 @@ func TestInvite(t *testing.T) {
 	botUserID := uuid.New().String()
 	messageID := uuid.New().String()
 	botPostMessageID := uuid.New().String()
+	originChannelID := uuid.New().String()
+	requester := model.NewUser(uuid.New().String(), "requester")
+	origin := model.WithOrigin(requester, originChannelID, messageID)
+	ikuraHamuID := uuid.New().String()
+	h1ronoID := uuid.New().String()
+	gitHubUserIDs := map[string]int64{"ikura-hamu": 1001, "H1rono": 1002}
+	botID := uuid.New().String()
+	deactivatedID := uuid.New().String()
+	traqUsers := []*model.TraqUser{
+		model.NewTraqUser(ikuraHamuID, "ikura-hamu", "いくらはむ", false, model.TraqUserStateActive),
+		model.NewTraqUser(h1ronoID, "H1rono_K", "H1rono", false, model.TraqUserStateActive),
+		model.NewTraqUser(botID, "BOT_other", "他のbot", true, model.TraqUserStateActive),
+		model.NewTraqUser(deactivatedID, "graduated", "卒業生", false, model.TraqUserStateDeactivated),
+	}
+	ikuraHamu := []model.InvitationOption{model.WithTraqUserID(ikuraHamuID), model.WithTraqDisplayName("いくらはむ"), model.WithGitHubUserID(1001)}
+	h1rono := []model.InvitationOption{model.WithTraqUserID(h1ronoID), model.WithTraqDisplayName("H1rono"), model.WithGitHubUserID(1002)}
 
 	type test struct {
-		plainText        string
-		messageID        string
-		embedded         []payload.EmbeddedInfo
-		gitHubUserExist  bool
+		plainText       string
+		messageID       string
+		embedded        []payload.EmbeddedInfo
+		gitHubUserExist bool
+		// gitHubUserExistがtrueでも存在しないGitHubユーザー
+		missingGitHubIDs []string
 		belongToOrg      bool
-		postTextFunc     func(test) string
+		// GitHubのAPIのエラーで確認できないユーザー
+		gitHubErrorIDs []string
+		postTextFunc   func(test) string
+		// 招待できるユーザーだけで申請するか確認するか
+		confirm          bool
 		postToBotChannel bool
 		invitations      []*model.Invitation
+		// 複数人の場合に、1人ずつ投稿されるメッセージ
+		perInviteePostTexts []string
 	}
 
 	testCases := map[string]test{
@@ -38,52 +65,60 @@ func TestInvite(t *testing.T) {
 			messageID: messageID,
 			embedded: []payload.EmbeddedInfo{
 				{Type: "user", Raw: "@BOT_traP-jp", ID: botUserID},
-				{Type: "user", Raw: "@ikura-hamu", ID: uuid.New().String()},
+				{Type: "user", Raw: "@ikura-hamu", ID: ikuraHamuID},
 			},
 			gitHubUserExist: true,
 			postTextFunc: func(t test) string {
 				return fmt.Sprintf(`@GitHub_org_Admin
 @ikura-hamu https://github.com/ikura-hamu
-https://q.trap.jp/messages/%s`, t.messageID)
+https://q.trap.jp/messages/%s
+※申請者と招待されるユーザー本人のスタンプは数えません`, t.messageID)
 			},
 			postToBotChannel: true,
-			invitations:      []*model.Invitation{model.NewInvitation(botPostMessageID, "@ikura-hamu", "ikura-hamu")},
+			invitations:      []*model.Invitation{model.NewInvitation(botPostMessageID, "ikura-hamu", "ikura-hamu", append(ikuraHamu, origin)...)},
 		},
 		"「招待」でも問題なし": {
 			plainText: "@BOT_traP-jp /招待 @ikura-hamu ikura-hamu",
 			messageID: messageID,
 			embedded: []payload.EmbeddedInfo{
 				{Type: "user", Raw: "@BOT_traP-jp", ID: botUserID},
-				{Type: "user", Raw: "@ikura-hamu", ID: uuid.New().String()},
+				{Type: "user", Raw: "@ikura-hamu", ID: ikuraHamuID},
 			},
 			gitHubUserExist: true,
 			postTextFunc: func(t test) string {
 				return fmt.Sprintf(`@GitHub_org_Admin
 @ikura-hamu https://github.com/ikura-hamu
-https://q.trap.jp/messages/%s`, t.messageID)
+https://q.trap.jp/messages/%s
+※申請者と招待されるユーザー本人のスタンプは数えません`, t.messageID)
 			},
 			postToBotChannel: true,
-			invitations:      []*model.Invitation{model.NewInvitation(botPostMessageID, "@ikura-hamu", "ikura-hamu")},
+			invitations:      []*model.Invitation{model.NewInvitation(botPostMessageID, "ikura-hamu", "ikura-hamu", append(ikuraHamu, origin)...)},
 		},
-		"複数人でも問題なし": {
+		"複数人は1人ずつ申請": {
 			plainText: "@BOT_traP-jp /invite @ikura-hamu ikura-hamu @H1rono_K H1rono",
 			messageID: messageID,
 			embedded: []payload.EmbeddedInfo{
 				{Type: "user", Raw: "@BOT_traP-jp", ID: botUserID},
-				{Type: "user", Raw: "@ikura-hamu", ID: uuid.New().String()},
-				{Type: "user", Raw: "@H1rono_K", ID: uuid.New().String()},
+				{Type: "user", Raw: "@ikura-hamu", ID: ikuraHamuID},
+				{Type: "user", Raw: "@H1rono_K", ID: h1ronoID},
 			},
 			gitHubUserExist: true,
 			postTextFunc: func(t test) string {
 				return fmt.Sprintf(`@GitHub_org_Admin
+2人の招待の申請です。続くメッセージで1人ずつ承認・却下してください
 @ikura-hamu https://github.com/ikura-hamu
 @H1rono_K https://github.com/H1rono
-https://q.trap.jp/messages/%s`, t.messageID)
+https://q.trap.jp/messages/%s
+※申請者と招待されるユーザー本人のスタンプは数えません`, t.messageID)
+			},
+			perInviteePostTexts: []string{
+				"@ikura-hamu https://github.com/ikura-hamu",
+				"@H1rono_K https://github.com/H1rono",
 			},
 			postToBotChannel: true,
 			invitations: []*model.Invitation{
-				model.NewInvitation(botPostMessageID, "@ikura-hamu", "ikura-hamu"),
-				model.NewInvitation(botPostMessageID, "@H1rono_K", "H1rono"),
+				model.NewInvitation(botPostMessageID+"-1", "ikura-hamu", "ikura-hamu", append(ikuraHamu, origin)...),
+				model.NewInvitation(botPostMessageID+"-2", "H1rono_K", "H1rono", append(h1rono, origin)...),
 			},
 		},
 		"引数が足りないのでエラー": {
@@ -97,7 +132,7 @@ https://q.trap.jp/messages/%s`, t.messageID)
 			messageID: uuid.New().String(),
 			embedded: []payload.EmbeddedInfo{
 				{Type: "user", Raw: "@BOT_traP-jp", ID: botUserID},
-				{Type: "user", Raw: "@ikura-hamu", ID: uuid.New().String()},
+				{Type: "user", Raw: "@ikura-hamu", ID: ikuraHamuID},
 			},
 			postTextFunc: func(test) string { return inviteCommandMessage("引数の数が合いません") },
 		},
@@ -111,16 +146,113 @@ https://q.trap.jp/messages/%s`, t.messageID)
 				return inviteCommandMessage("/invite は、GitHubのOrganizationに招待するためのコマンドです。")
 			},
 		},
+		"メンションせずに@なしで指定しても問題なし": {
+			plainText: "@BOT_traP-jp /invite ikura-hamu ikura-hamu",
+			messageID: messageID,
+			embedded: []payload.EmbeddedInfo{
+				{Type: "user", Raw: "@BOT_traP-jp", ID: botUserID},
+			},
+			gitHubUserExist: true,
+			postTextFunc: func(t test) string {
+				return fmt.Sprintf(`@GitHub_org_Admin
+@ikura-hamu https://github.com/ikura-hamu
+https://q.trap.jp/messages/%s
+※申請者と招待されるユーザー本人のスタンプは数えません`, t.messageID)
+			},
+			postToBotChannel: true,
+			invitations:      []*model.Invitation{model.NewInvitation(botPostMessageID, "ikura-hamu", "ikura-hamu", append(ikuraHamu, origin)...)},
+		},
+		"traQユーザーが存在しない": {
+			plainText: "@BOT_traP-jp /invite @no-user ikura-hamu",
+			messageID: messageID,
+			embedded: []payload.EmbeddedInfo{
+				{Type: "user", Raw: "@BOT_traP-jp", ID: botUserID},
+			},
+			gitHubUserExist: true,
+			postTextFunc: func(test) string {
+				return "招待できないユーザーがいるため、申請しませんでした\n- traQユーザー @no-user は存在しません"
+			},
+		},
+		"traQユーザーがbot": {
+			plainText: "@BOT_traP-jp /invite @BOT_other ikura-hamu",
+			messageID: messageID,
+			embedded: []payload.EmbeddedInfo{
+				{Type: "user", Raw: "@BOT_traP-jp", ID: botUserID},
+				{Type: "user", Raw: "@BOT_other", ID: botID},
+			},
+			gitHubUserExist: true,
+			postTextFunc: func(test) string {
+				return "招待できないユーザーがいるため、申請しませんでした\n- traQユーザー @BOT_other はbotです"
+			},
+		},
+		"traQユーザーが利用できない": {
+			plainText: "@BOT_traP-jp /invite graduated ikura-hamu",
+			messageID: messageID,
+			embedded: []payload.EmbeddedInfo{
+				{Type: "user", Raw: "@BOT_traP-jp", ID: botUserID},
+			},
+			gitHubUserExist: true,
+			postTextFunc: func(test) string {
+				return "招待できないユーザーがいるため、申請しませんでした\n- traQユーザー graduated は利用できないアカウントです"
+			},
+		},
+		"招待できないユーザーをまとめて伝え、招待できるユーザーだけで申請するか確認する": {
+			plainText: "@BOT_traP-jp /invite @no-user no-user @ikura-hamu ikura-hamu",
+			messageID: messageID,
+			embedded: []payload.EmbeddedInfo{
+				{Type: "user", Raw: "@BOT_traP-jp", ID: botUserID},
+				{Type: "user", Raw: "@ikura-hamu", ID: ikuraHamuID},
+			},
+			gitHubUserExist:  true,
+			missingGitHubIDs: []string{"no-user"},
+			postTextFunc: func(test) string {
+				return "招待できないユーザーがいます\n" +
+					"- traQユーザー @no-user は存在しません\n" +
+					"- GitHubユーザー no-user は存在しません\n" +
+					"招待できるユーザーだけで申請する場合は、30分以内にこのメッセージに承認スタンプを押してください。申請しない場合は却下スタンプを押してください"
+			},
+			confirm: true,
+		},
+		"招待できるユーザーがいなければ確認しない": {
+			plainText: "@BOT_traP-jp /invite @no-user ikura-hamu",
+			messageID: messageID,
+			embedded: []payload.EmbeddedInfo{
+				{Type: "user", Raw: "@BOT_traP-jp", ID: botUserID},
+			},
+			gitHubUserExist: true,
+			postTextFunc: func(test) string {
+				return "招待できないユーザーがいるため、申請しませんでした\n- traQユーザー @no-user は存在しません"
+			},
+		},
+		"確認できなかったユーザーは招待できないユーザーと分けて伝え、確認しない": {
+			plainText: "@BOT_traP-jp /invite @no-user no-user @ikura-hamu ikura-hamu @H1rono_K H1rono",
+			messageID: messageID,
+			embedded: []payload.EmbeddedInfo{
+				{Type: "user", Raw: "@BOT_traP-jp", ID: botUserID},
+				{Type: "user", Raw: "@ikura-hamu", ID: ikuraHamuID},
+				{Type: "user", Raw: "@H1rono_K", ID: h1ronoID},
+			},
+			gitHubUserExist:  true,
+			missingGitHubIDs: []string{"no-user"},
+			gitHubErrorIDs:   []string{"H1rono"},
+			postTextFunc: func(test) string {
+				return "確認できなかったユーザーがいるため、申請しませんでした。時間をおいてもう一度試してください\n" +
+					"- GitHubユーザー H1rono を確認できませんでした\n" +
+					"招待できないユーザー\n" +
+					"- traQユーザー @no-user は存在しません\n" +
+					"- GitHubユーザー no-user は存在しません"
+			},
+		},
 		"GitHubユーザーが存在しない": {
 			plainText: "@BOT_traP-jp /invite @ikura-hamu no-user",
 			messageID: messageID,
 			embedded: []payload.EmbeddedInfo{
 				{Type: "user", Raw: "@BOT_traP-jp", ID: botUserID},
-				{Type: "user", Raw: "@ikura-hamu", ID: uuid.New().String()},
+				{Type: "user", Raw: "@ikura-hamu", ID: ikuraHamuID},
 			},
 			gitHubUserExist: false,
 			postTextFunc: func(test) string {
-				return "GitHubユーザー no-user は存在しません"
+				return "招待できないユーザーがいるため、申請しませんでした\n- GitHubユーザー no-user は存在しません"
 			},
 		},
 		"すでに所属している": {
@@ -132,7 +264,7 @@ https://q.trap.jp/messages/%s`, t.messageID)
 			gitHubUserExist: true,
 			belongToOrg:     true,
 			postTextFunc: func(test) string {
-				return fmt.Sprintf("GitHubユーザー ikura-hamu は既に traP-jp に所属しています")
+				return "招待できないユーザーがいるため、申請しませんでした\n- GitHubユーザー ikura-hamu は既に traP-jp に所属しています"
 			},
 		},
 	}
@@ -141,16 +273,37 @@ https://q.trap.jp/messages/%s`, t.messageID)
 		t.Run(name, func(t *testing.T) {
 			t.Parallel()
 
+			postCount := 0
 			traqMock := &mock.TraqMock{
 				GetBotUserFunc: func(context.Context) (*model.User, error) {
 					return model.NewUser(botUserID, "BOT_traP-jp"), nil
 				},
 				PostMessageFunc: func(ctx context.Context, channelID string, text string) (string, error) {
-					return botPostMessageID, nil
+					if len(test.perInviteePostTexts) == 0 {
+						return botPostMessageID, nil
+					}
+					postCount++
+					return fmt.Sprintf("%s-%d", botPostMessageID, postCount-1), nil
 				},
 				AddStampFunc: func(context.Context, string, string, int) error {
 					return nil
 				},
+				GetTraqUserFunc: func(ctx context.Context, userID string) (*model.TraqUser, error) {
+					for _, user := range traqUsers {
+						if user.ID() == userID {
+							return user, nil
+						}
+					}
+					return nil, nil
+				},
+				GetTraqUserByNameFunc: func(ctx context.Context, name string) (*model.TraqUser, error) {
+					for _, user := range traqUsers {
+						if user.Name() == name {
+							return user, nil
+						}
+					}
+					return nil, nil
+				},
 			}
 			repositoryMock := &repomock.InvitationMock{
 				CreateInvitationFunc: func(ctx context.Context, invitations []*model.Invitation) error {
@@ -158,8 +311,14 @@ https://q.trap.jp/messages/%s`, t.messageID)
 				},
 			}
 			gitHubMock := &mock.GitHubMock{
-				CheckUserExistFunc: func(ctx context.Context, userID string) (bool, error) {
-					return test.gitHubUserExist, nil
+				GetUserIDFunc: func(ctx context.Context, userID string) (int64, error) {
+					if slices.Contains(test.gitHubErrorIDs, userID) {
+						return 0, errors.New("GitHub API error")
+					}
+					if !test.gitHubUserExist || slices.Contains(test.missingGitHubIDs, userID) {
+						return 0, nil
+					}
+					return gitHubUserIDs[userID], nil
 				},
 				CheckUserInOrgFunc: func(ctx context.Context, userID string) (bool, error) {
 					return test.belongToOrg, nil
@@ -186,10 +345,10 @@ https://q.trap.jp/messages/%s`, t.messageID)
 				Message: payload.Message{
 					PlainText: test.plainText,
 					ID:        test.messageID,
-					ChannelID: uuid.New().String(),
+					ChannelID: originChannelID,
 					Text:      "現時点の実装では使われない",
 					Embedded:  test.embedded,
-					User:      payload.User{},
+					User:      payload.User{ID: requester.ID(), Name: requester.Name()},
 					CreatedAt: time.Now(),
 					UpdatedAt: time.Now(),
 				},
@@ -197,31 +356,52 @@ https://q.trap.jp/messages/%s`, t.messageID)
 			}
 			bh.invite(payload)
 
-			assert.Len(t, traqMock.PostMessageCalls(), 1)
-			assert.Equal(t, test.postTextFunc(test), traqMock.PostMessageCalls()[0].Text)
-			if test.postToBotChannel {
-				assert.Equal(t, "botChannelID", traqMock.PostMessageCalls()[0].ChannelID)
-			} else {
-				assert.Equal(t, payload.Message.ChannelID, traqMock.PostMessageCalls()[0].ChannelID)
+			postMessageCalls := traqMock.PostMessageCalls()
+			assert.Len(t, postMessageCalls, 1+len(test.perInviteePostTexts))
+			assert.Equal(t, test.postTextFunc(test), postMessageCalls[0].Text)
+			for i, text := range test.perInviteePostTexts {
+				assert.Equal(t, text, postMessageCalls[i+1].Text)
+			}
+			for _, call := range postMessageCalls {
+				if test.postToBotChannel {
+					assert.Equal(t, "botChannelID", call.ChannelID)
+				} else {
+					assert.Equal(t, payload.Message.ChannelID, call.ChannelID)
+				}
 			}
 
 			if test.invitations != nil {
-				assert.Len(t, repositoryMock.CreateInvitationCalls(), 1)
+				assert.Len(t, repositoryMock.CreateInvitationCalls(), len(test.invitations))
 				for i, inv := range test.invitations {
-					assert.Equal(t, inv, repositoryMock.CreateInvitationCalls()[0].Invitations[i])
+					assert.Equal(t, []*model.Invitation{inv}, repositoryMock.CreateInvitationCalls()[i].Invitations)
 				}
 			}
 
 			if test.postToBotChannel {
-				assert.Len(t, traqMock.AddStampCalls(), 2)
+				assert.Len(t, traqMock.AddStampCalls(), 2*len(test.invitations))
+
+				for i, inv := range test.invitations {
+					assert.Equal(t, inv.MessageID(), traqMock.AddStampCalls()[2*i].MessageID)
+					assert.Equal(t, "acceptStampID", traqMock.AddStampCalls()[2*i].StampID)
+					assert.Equal(t, 1, traqMock.AddStampCalls()[2*i].Count)
+
+					assert.Equal(t, inv.MessageID(), traqMock.AddStampCalls()[2*i+1].MessageID)
+					assert.Equal(t, "rejectStampID", traqMock.AddStampCalls()[2*i+1].StampID)
+					assert.Equal(t, 1, traqMock.AddStampCalls()[2*i+1].Count)
+				}
+			}
 
+			// 確認を求めた場合は、申請せずに報告に承認・却下のスタンプを押しておく
+			_, confirming := bh.inviteConfirmations.get(botPostMessageID)
+			assert.Equal(t, test.confirm, confirming)
+			if test.confirm {
+				assert.Empty(t, repositoryMock.CreateInvitationCalls())
+				require.Len(t, traqMock.AddStampCalls(), 2)
 				assert.Equal(t, botPostMessageID, traqMock.AddStampCalls()[0].MessageID)
 				assert.Equal(t, "acceptStampID", traqMock.AddStampCalls()[0].StampID)
-				assert.Equal(t, 1, traqMock.AddStampCalls()[0].Count)
-
-				assert.Equal(t, botPostMessageID, traqMock.AddStampCalls()[1].MessageID)
 				assert.Equal(t, "rejectStampID", traqMock.AddStampCalls()[1].StampID)
-				assert.Equal(t, 1, traqMock.AddStampCalls()[1].Count)
+			} else if !test.postToBotChannel {
+				assert.Empty(t, traqMock.AddStampCalls())
 			}
 		})
 	}
@@ -240,7 +420,7 @@ func TestList(t *testing.T) {
 			},
 		}
 		repositoryMock := &repomock.InvitationMock{
-			GetAllInvitationsFunc: func(context.Context) ([]*model.Invitation, error) {
+			GetInvitationsByStatusFunc: func(context.Context, ...model.InvitationStatus) ([]*model.Invitation, error) {
 				return []*model.Invitation{
 					model.NewInvitation(uuid.New().String(), "traq_id", "github_id"),
 					model.NewInvitation(uuid.New().String(), "traq_id2", "github_id2"),
@@ -269,6 +449,9 @@ func TestList(t *testing.T) {
 		}
 		bh.list(payload)
 
+		assert.Len(t, repositoryMock.GetInvitationsByStatusCalls(), 1)
+		assert.Equal(t, []model.InvitationStatus{model.InvitationStatusPending}, repositoryMock.GetInvitationsByStatusCalls()[0].Statuses)
+
 		assert.Len(t, traqMock.PostMessageCalls(), 1)
 		assert.Equal(t, "招待一覧\n@traq_id (github_id)\n@traq_id2 (github_id2)\n", traqMock.PostMessageCalls()[0].Text)
 	})
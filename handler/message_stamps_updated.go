@@ -3,101 +3,173 @@ package handler
 import (
 	"context"
 	"errors"
-	"fmt"
 	"slices"
+	"time"
 
+	"github.com/traP-jp/members_bot/model"
+	"github.com/traP-jp/members_bot/policy"
 	"github.com/traP-jp/members_bot/repository"
 	"github.com/traPtitech/traq-ws-bot/payload"
 )
 
 // スタンプが押されたとき、招待を承認するか却下するか判定する
 // :kan:が押されていたら何もしない
-// スタンプを時系列で前から見ていき、指定されたスタンプが閾値以上押されていたら招待を送信する
+// 承認するか却下するかは、設定された policy.Policy で判定する。承認されたら招待を送信する
+// 申請者と招待されるユーザー本人のスタンプは、判定に数えない。数えなかったスタンプを押した人は、判定の状況に表示する
+// 判定結果は招待の状態として記録し、判定に数えたスタンプも記録する
+// 申請メッセージの末尾には、スタンプの数や判定結果を表示し続ける
+// 招待できるユーザーだけで申請するかの確認のメッセージの場合は、申請者のスタンプに従って申請する
 func (h *BotHandler) AcceptOrReject(p *payload.BotMessageStampsUpdated) {
 	ctx := context.Background()
+	if h.confirmInvite(ctx, p.MessageID, p.Stamps) {
+		return
+	}
+
+	h.decide(ctx, p.MessageID, p.Stamps)
+}
 
-	for _, stamp := range p.Stamps {
+// decide は、messageIDのメッセージに押されているスタンプから、招待を承認するか却下するか判定する
+func (h *BotHandler) decide(ctx context.Context, messageID string, stamps []payload.MessageStamp) {
+	for _, stamp := range stamps {
 		if stamp.StampID == h.inactiveStampID && stamp.UserID == h.botUser.ID() {
 			return // :kan:押されてたら、何もしない
 		}
 	}
 
-	adminIDs, err := h.traqClient.GetGroupMemberIDs(ctx, h.adminGroupID)
+	invitations, err := h.ir.GetInvitations(ctx, messageID)
+	if errors.Is(err, repository.ErrRecordNotFound) {
+		h.decideRemoval(ctx, messageID, stamps) // 招待ではなく、メンバーから外す申請の場合
+		return
+	}
 	if err != nil {
-		logger.Printf("failed to get group member IDs: %v", err)
+		logger.Printf("failed to get invitations: %v", err)
 		return
 	}
 
-	invitations, err := h.ir.GetInvitations(ctx, p.MessageID)
-	if errors.Is(err, repository.ErrRecordNotFound) {
-		return
+	invitations = slices.DeleteFunc(invitations, func(inv *model.Invitation) bool {
+		return inv.Status() != model.InvitationStatusPending
+	})
+	if len(invitations) == 0 {
+		return // 判定済み
 	}
+
+	in, excluded := policyInput(invitations[0], stamps)
+	result, err := h.policy.Evaluate(ctx, in)
 	if err != nil {
-		logger.Printf("failed to get invitations: %v", err)
+		logger.Printf("failed to evaluate policy: %v", err)
 		return
 	}
 
-	slices.SortFunc(p.Stamps, func(i, j payload.MessageStamp) int { return int(i.CreatedAt.Sub(j.CreatedAt)) })
+	// 数えなかったスタンプを押した人は、判定の状況にも表示する
+	note, err := h.excludedVotersNote(ctx, excluded)
+	if err != nil {
+		logger.Printf("failed to make excluded voters note: %v", err)
+	}
 
-	acceptStampCount := 0
-	rejectStampCount := 0
-	accept, reject := false, false
-	for _, stamp := range p.Stamps {
-		if !slices.Contains(adminIDs, stamp.UserID) {
-			continue
-		}
-		if stamp.StampID == h.acceptStampID {
-			acceptStampCount++
-		} else if stamp.StampID == h.rejectStampID {
-			rejectStampCount++
+	if result.Decision != policy.Accept && result.Decision != policy.Reject {
+		// スタンプが外された場合も、数を更新する
+		progress, err := h.pendingProgressText(ctx, result)
+		if err != nil {
+			logger.Printf("failed to make progress text: %v", err)
+			return
 		}
+		h.updatePendingProgress(ctx, messageID, progress+note)
+		return
+	}
+	reject := result.Decision == policy.Reject
 
-		if acceptStampCount >= h.acceptStampThreshold {
-			accept = true
-			break
-		}
-		if rejectStampCount >= h.rejectStampThreshold {
-			reject = true
-			break
+	status, decisionResult := model.InvitationStatusApproved, model.DecisionResultApproved
+	if reject {
+		status, decisionResult = model.InvitationStatusRejected, model.DecisionResultRejected
+	}
+	// 同じメッセージへのイベントが同時に届くことがあるので、判定と記録は1度だけ行われるようにする
+	decision := model.NewDecision(messageID, model.DecisionKindInvitation, decisionResult, decisionVotes(result.Votes), time.Now())
+	err = h.tx.RunInTx(ctx, func(ctx context.Context) error {
+		err := h.ir.DecideInvitations(ctx, messageID, status)
+		if err != nil {
+			return err
 		}
+		return h.dr.CreateDecision(ctx, decision)
+	})
+	if errors.Is(err, repository.ErrInvalidStatusTransition) {
+		return // 他のイベントで判定済み
 	}
-
-	if !accept && !reject {
+	if err != nil {
+		logger.Printf("failed to decide invitations: %v", err)
 		return
 	}
 
-	err = h.traqClient.AddStamp(ctx, p.MessageID, h.inactiveStampID, 1)
+	err = h.traqClient.AddStamp(ctx, messageID, h.inactiveStampID, 1)
 	if err != nil {
 		logger.Printf("failed to add stamp: %v", err)
-		return
 	}
 
+	outcome := ""
 	if reject {
-		err := h.ir.DeleteInvitations(ctx, p.MessageID)
-		if err != nil {
-			logger.Printf("failed to delete invitations: %v", err)
-		}
-		return
+		h.notifyRequester(ctx, invitations, rejectionMessage(invitations[0].Reason()))
+	} else {
+		h.linkAccounts(ctx, invitations, decision.DecidedAt())
+		outcome = h.sendOutcomeText(h.sendInvitations(ctx, invitations))
 	}
 
-	err = h.githubClient.SendInvitations(ctx, invitations)
+	progress, err := h.decidedProgressText(ctx, decision, outcome)
 	if err != nil {
-		logger.Printf("failed to send invitations: %v", err)
+		logger.Printf("failed to make progress text: %v", err)
 		return
 	}
+	h.updateProgress(ctx, messageID, progress+note)
+}
+
+// decisionVotes は、判定に数えたスタンプを、判定の記録に残す票にする
+func decisionVotes(stamps []payload.MessageStamp) []*model.Vote {
+	votes := make([]*model.Vote, 0, len(stamps))
+	for _, stamp := range stamps {
+		votes = append(votes, model.NewVote(stamp.UserID, stamp.StampID, stamp.CreatedAt))
+	}
 
-	message := "招待を送信しました。確認してください\n"
+	return votes
+}
+
+// linkAccounts は、承認された招待から、traQとGitHubのアカウントの対応を記録する
+func (h *BotHandler) linkAccounts(ctx context.Context, invitations []*model.Invitation, linkedAt time.Time) {
 	for _, inv := range invitations {
-		message += fmt.Sprintf("@%s (%s)\n", inv.TraqID(), inv.GitHubID())
+		link := model.NewAccountLink(inv.TraqUserID(), inv.TraqID(), inv.GitHubUserID(), inv.GitHubID(), model.AccountLinkSourceInvitation, linkedAt)
+		err := h.alr.SaveAccountLink(ctx, link)
+		if err != nil {
+			logger.Printf("failed to save account link: %v", err)
+		}
 	}
+}
 
-	_, err = h.traqClient.PostMessage(ctx, h.botChannelID, message)
-	if err != nil {
-		logger.Printf("failed to post message: %v", err)
+// policyInput は、招待のメッセージに押されたスタンプから、判定に使う入力を作る。
+// 申請者と招待されるユーザー本人のスタンプは除き、除いたスタンプも返す
+func policyInput(invitation *model.Invitation, stamps []payload.MessageStamp) (*policy.Input, []payload.MessageStamp) {
+	in := &policy.Input{}
+	excludedUserIDs := make([]string, 0, 2)
+	if requester := invitation.Requester(); requester != nil {
+		in.RequesterID = requester.ID()
+		excludedUserIDs = append(excludedUserIDs, requester.ID())
+	}
+	if invitation.TraqUserID() != "" {
+		excludedUserIDs = append(excludedUserIDs, invitation.TraqUserID())
+	}
+	var excluded []payload.MessageStamp
+	for _, stamp := range stamps {
+		if slices.Contains(excludedUserIDs, stamp.UserID) {
+			excluded = append(excluded, stamp)
+			continue
+		}
+		in.Stamps = append(in.Stamps, stamp)
 	}
 
-	err = h.ir.DeleteInvitations(ctx, p.MessageID)
-	if err != nil {
-		logger.Printf("failed to delete invitations: %v", err)
+	return in, excluded
+}
+
+func rejectionMessage(reason string) string {
+	message := "招待の申請は却下されました"
+	if reason != "" {
+		message += "\n理由: " + reason
 	}
+
+	return message
 }
@@ -2,11 +2,13 @@ package handler
 
 import (
 	"context"
+	"errors"
 	"testing"
 	"time"
 
 	"github.com/google/uuid"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 	"github.com/traP-jp/members_bot/model"
 	"github.com/traP-jp/members_bot/repository"
 	repomock "github.com/traP-jp/members_bot/repository/mock"
@@ -21,32 +23,60 @@ func TestAcceptOrReject(t *testing.T) {
 	inactiveStampID := uuid.NewString()
 	adminIDs := []string{uuid.NewString(), uuid.NewString(), uuid.NewString()}
 	botUserID := uuid.NewString()
+	originChannelID := uuid.NewString()
+	originMessageID := uuid.NewString()
+	origin := model.WithOrigin(model.NewUser(uuid.NewString(), "requester"), originChannelID, originMessageID)
 
 	type testCase struct {
-		addStampThreshold        int
-		rejectStampThreshold     int
-		stamps                   []payload.MessageStamp
-		invitations              []*model.Invitation
-		GetInvitationsErr        error
-		executeAddStamp          bool
-		executePostMessage       bool
-		executeSendInvitations   bool
-		postMessageText          string
-		executeDeleteInvitations bool
+		addStampThreshold      int
+		rejectStampThreshold   int
+		stamps                 []payload.MessageStamp
+		invitations            []*model.Invitation
+		GetInvitationsErr      error
+		executeAddStamp        bool
+		executePostMessage     bool
+		executeSendInvitations bool
+		postMessageText        string
+		statusUpdates          []model.InvitationStatus
+		DecideInvitationsErr   error
+		decisionVoteCount      int
+		notifyText             string
+		// 1人ずつの送信結果。指定しない場合は全員送信できたとする
+		sendResultStatuses []model.SendResultStatus
 	}
 	testCases := map[string]testCase{
+		"申請者本人の承認は数えない": {
+			addStampThreshold:    1,
+			rejectStampThreshold: 1,
+			stamps: []payload.MessageStamp{
+				{StampID: acceptStampID, UserID: adminIDs[0], CreatedAt: time.Now()},
+			},
+			invitations: []*model.Invitation{model.NewInvitation(uuid.NewString(), "ikura-hamu", "ikura-hamu",
+				model.WithOrigin(model.NewUser(adminIDs[0], "admin"), originChannelID, originMessageID))},
+		},
+		"招待されるユーザー本人の承認は数えない": {
+			addStampThreshold:    2,
+			rejectStampThreshold: 2,
+			stamps: []payload.MessageStamp{
+				{StampID: acceptStampID, UserID: adminIDs[0], CreatedAt: time.Now()},
+				{StampID: acceptStampID, UserID: adminIDs[1], CreatedAt: time.Now()},
+			},
+			invitations: []*model.Invitation{model.NewInvitation(uuid.NewString(), "ikura-hamu", "ikura-hamu",
+				model.WithTraqUserID(adminIDs[1]))},
+		},
 		"承認": {
 			addStampThreshold:    1,
 			rejectStampThreshold: 1,
 			stamps: []payload.MessageStamp{
 				{StampID: acceptStampID, UserID: adminIDs[0], CreatedAt: time.Now()},
 			},
-			invitations:              []*model.Invitation{model.NewInvitation(uuid.NewString(), "ikura-hamu", "ikura-hamu")},
-			executeAddStamp:          true,
-			executePostMessage:       true,
-			executeSendInvitations:   true,
-			postMessageText:          "招待を送信しました。確認してください\n@ikura-hamu (ikura-hamu)\n",
-			executeDeleteInvitations: true,
+			invitations:            []*model.Invitation{model.NewInvitation(uuid.NewString(), "ikura-hamu", "ikura-hamu")},
+			executeAddStamp:        true,
+			executePostMessage:     true,
+			executeSendInvitations: true,
+			postMessageText:        "招待を送信しました。確認してください\n@ikura-hamu (ikura-hamu)\n",
+			statusUpdates:          []model.InvitationStatus{model.InvitationStatusApproved, model.InvitationStatusSent},
+			decisionVoteCount:      1,
 		},
 		"却下": {
 			addStampThreshold:    1,
@@ -54,9 +84,10 @@ func TestAcceptOrReject(t *testing.T) {
 			stamps: []payload.MessageStamp{
 				{StampID: rejectStampID, UserID: adminIDs[0], CreatedAt: time.Now()},
 			},
-			invitations:              []*model.Invitation{model.NewInvitation(uuid.NewString(), "ikura-hamu", "ikura-hamu")},
-			executeAddStamp:          true,
-			executeDeleteInvitations: true,
+			invitations:       []*model.Invitation{model.NewInvitation(uuid.NewString(), "ikura-hamu", "ikura-hamu")},
+			executeAddStamp:   true,
+			statusUpdates:     []model.InvitationStatus{model.InvitationStatusRejected},
+			decisionVoteCount: 1,
 		},
 		"複数人からの承認": {
 			addStampThreshold:    2,
@@ -65,12 +96,13 @@ func TestAcceptOrReject(t *testing.T) {
 				{StampID: acceptStampID, UserID: adminIDs[0], CreatedAt: time.Now()},
 				{StampID: acceptStampID, UserID: adminIDs[1], CreatedAt: time.Now()},
 			},
-			invitations:              []*model.Invitation{model.NewInvitation(uuid.NewString(), "ikura-hamu", "ikura-hamu")},
-			executeAddStamp:          true,
-			executePostMessage:       true,
-			executeSendInvitations:   true,
-			postMessageText:          "招待を送信しました。確認してください\n@ikura-hamu (ikura-hamu)\n",
-			executeDeleteInvitations: true,
+			invitations:            []*model.Invitation{model.NewInvitation(uuid.NewString(), "ikura-hamu", "ikura-hamu")},
+			executeAddStamp:        true,
+			executePostMessage:     true,
+			executeSendInvitations: true,
+			postMessageText:        "招待を送信しました。確認してください\n@ikura-hamu (ikura-hamu)\n",
+			statusUpdates:          []model.InvitationStatus{model.InvitationStatusApproved, model.InvitationStatusSent},
+			decisionVoteCount:      2,
 		},
 		"複数人からの却下": {
 			addStampThreshold:    2,
@@ -79,9 +111,10 @@ func TestAcceptOrReject(t *testing.T) {
 				{StampID: rejectStampID, UserID: adminIDs[0], CreatedAt: time.Now()},
 				{StampID: rejectStampID, UserID: adminIDs[1], CreatedAt: time.Now()},
 			},
-			invitations:              []*model.Invitation{model.NewInvitation(uuid.NewString(), "ikura-hamu", "ikura-hamu")},
-			executeAddStamp:          true,
-			executeDeleteInvitations: true,
+			invitations:       []*model.Invitation{model.NewInvitation(uuid.NewString(), "ikura-hamu", "ikura-hamu")},
+			executeAddStamp:   true,
+			statusUpdates:     []model.InvitationStatus{model.InvitationStatusRejected},
+			decisionVoteCount: 2,
 		},
 		"承認が先だった": {
 			addStampThreshold:    2,
@@ -92,12 +125,13 @@ func TestAcceptOrReject(t *testing.T) {
 				{StampID: acceptStampID, UserID: adminIDs[2], CreatedAt: time.Now().Add(-time.Minute)},
 				{StampID: rejectStampID, UserID: adminIDs[2], CreatedAt: time.Now()},
 			},
-			invitations:              []*model.Invitation{model.NewInvitation(uuid.NewString(), "ikura-hamu", "ikura-hamu")},
-			executeAddStamp:          true,
-			executePostMessage:       true,
-			executeSendInvitations:   true,
-			postMessageText:          "招待を送信しました。確認してください\n@ikura-hamu (ikura-hamu)\n",
-			executeDeleteInvitations: true,
+			invitations:            []*model.Invitation{model.NewInvitation(uuid.NewString(), "ikura-hamu", "ikura-hamu")},
+			executeAddStamp:        true,
+			executePostMessage:     true,
+			executeSendInvitations: true,
+			postMessageText:        "招待を送信しました。確認してください\n@ikura-hamu (ikura-hamu)\n",
+			statusUpdates:          []model.InvitationStatus{model.InvitationStatusApproved, model.InvitationStatusSent},
+			decisionVoteCount:      3,
 		},
 		"却下が先だった": {
 			addStampThreshold:    2,
@@ -108,9 +142,10 @@ func TestAcceptOrReject(t *testing.T) {
 				{StampID: acceptStampID, UserID: adminIDs[2], CreatedAt: time.Now()},
 				{StampID: rejectStampID, UserID: adminIDs[2], CreatedAt: time.Now().Add(-time.Minute)},
 			},
-			invitations:              []*model.Invitation{model.NewInvitation(uuid.NewString(), "ikura-hamu", "ikura-hamu")},
-			executeAddStamp:          true,
-			executeDeleteInvitations: true,
+			invitations:       []*model.Invitation{model.NewInvitation(uuid.NewString(), "ikura-hamu", "ikura-hamu")},
+			executeAddStamp:   true,
+			statusUpdates:     []model.InvitationStatus{model.InvitationStatusRejected},
+			decisionVoteCount: 3,
 		},
 		":kan:があるので何もしない": {
 			addStampThreshold:    1,
@@ -139,11 +174,138 @@ func TestAcceptOrReject(t *testing.T) {
 				model.NewInvitation(uuid.NewString(), "ikura-hamu", "ikura-hamu"),
 				model.NewInvitation(uuid.NewString(), "H1rono_K", "H1rono"),
 			},
-			executeAddStamp:          true,
-			executePostMessage:       true,
-			executeSendInvitations:   true,
-			postMessageText:          "招待を送信しました。確認してください\n@ikura-hamu (ikura-hamu)\n@H1rono_K (H1rono)\n",
-			executeDeleteInvitations: true,
+			executeAddStamp:        true,
+			executePostMessage:     true,
+			executeSendInvitations: true,
+			postMessageText:        "招待を送信しました。確認してください\n@ikura-hamu (ikura-hamu)\n@H1rono_K (H1rono)\n",
+			statusUpdates:          []model.InvitationStatus{model.InvitationStatusApproved, model.InvitationStatusSent, model.InvitationStatusSent},
+			decisionVoteCount:      1,
+		},
+		"判定済みなので何もしない": {
+			addStampThreshold:    1,
+			rejectStampThreshold: 1,
+			stamps: []payload.MessageStamp{
+				{StampID: acceptStampID, UserID: adminIDs[0], CreatedAt: time.Now()},
+			},
+			invitations: []*model.Invitation{
+				model.NewInvitation(uuid.NewString(), "ikura-hamu", "ikura-hamu", model.WithStatus(model.InvitationStatusSent)),
+			},
+		},
+		"他のイベントで判定済み": {
+			addStampThreshold:    1,
+			rejectStampThreshold: 1,
+			stamps: []payload.MessageStamp{
+				{StampID: acceptStampID, UserID: adminIDs[0], CreatedAt: time.Now()},
+			},
+			invitations:          []*model.Invitation{model.NewInvitation(uuid.NewString(), "ikura-hamu", "ikura-hamu")},
+			statusUpdates:        []model.InvitationStatus{model.InvitationStatusApproved},
+			DecideInvitationsErr: repository.ErrInvalidStatusTransition,
+			decisionVoteCount:    1,
+		},
+		"招待の送信に失敗": {
+			addStampThreshold:    1,
+			rejectStampThreshold: 1,
+			stamps: []payload.MessageStamp{
+				{StampID: acceptStampID, UserID: adminIDs[0], CreatedAt: time.Now()},
+			},
+			invitations:            []*model.Invitation{model.NewInvitation(uuid.NewString(), "ikura-hamu", "ikura-hamu")},
+			executeAddStamp:        true,
+			executePostMessage:     true,
+			executeSendInvitations: true,
+			postMessageText:        "招待を送信できませんでした。`/retry` で送り直せます\n@ikura-hamu (ikura-hamu) エラーが発生しました\n",
+			statusUpdates:          []model.InvitationStatus{model.InvitationStatusApproved, model.InvitationStatusSendFailed},
+			sendResultStatuses:     []model.SendResultStatus{model.SendResultStatusError},
+			decisionVoteCount:      1,
+		},
+		"一部の招待の送信に失敗": {
+			addStampThreshold:    1,
+			rejectStampThreshold: 1,
+			stamps: []payload.MessageStamp{
+				{StampID: acceptStampID, UserID: adminIDs[0], CreatedAt: time.Now()},
+			},
+			invitations: []*model.Invitation{
+				model.NewInvitation(uuid.NewString(), "ikura-hamu", "ikura-hamu"),
+				model.NewInvitation(uuid.NewString(), "H1rono_K", "H1rono"),
+				model.NewInvitation(uuid.NewString(), "kaitoyama", "kaitoyama"),
+				model.NewInvitation(uuid.NewString(), "cp20", "cp-20"),
+			},
+			executeAddStamp:        true,
+			executePostMessage:     true,
+			executeSendInvitations: true,
+			postMessageText: "招待を送信しました。確認してください\n@ikura-hamu (ikura-hamu)\n@cp20 (cp-20) 既に招待されています\n" +
+				"既に traP-jp のメンバーでした\n@kaitoyama (kaitoyama)\n" +
+				"招待を送信できませんでした。`/retry` で送り直せます\n@H1rono_K (H1rono) GitHubユーザーが見つかりません\n",
+			statusUpdates: []model.InvitationStatus{
+				model.InvitationStatusApproved,
+				model.InvitationStatusSent,
+				model.InvitationStatusSendFailed,
+				model.InvitationStatusAccepted,
+				model.InvitationStatusSent,
+			},
+			sendResultStatuses: []model.SendResultStatus{
+				model.SendResultStatusSent,
+				model.SendResultStatusUserNotFound,
+				model.SendResultStatusAlreadyMember,
+				model.SendResultStatusAlreadyInvited,
+			},
+			decisionVoteCount: 1,
+		},
+		"承認を申請したチャンネルに通知": {
+			addStampThreshold:    1,
+			rejectStampThreshold: 1,
+			stamps: []payload.MessageStamp{
+				{StampID: acceptStampID, UserID: adminIDs[0], CreatedAt: time.Now()},
+			},
+			invitations:            []*model.Invitation{model.NewInvitation(uuid.NewString(), "@ikura-hamu", "ikura-hamu", origin)},
+			executeAddStamp:        true,
+			executePostMessage:     true,
+			executeSendInvitations: true,
+			postMessageText:        "招待を送信しました。確認してください\n@ikura-hamu (ikura-hamu)\n",
+			statusUpdates:          []model.InvitationStatus{model.InvitationStatusApproved, model.InvitationStatusSent},
+			decisionVoteCount:      1,
+			notifyText:             "@requester 招待が承認され、GitHubから招待が送信されました。メールを確認してください\n@ikura-hamu (ikura-hamu)\nhttps://q.trap.jp/messages/" + originMessageID,
+		},
+		"却下を申請したチャンネルに通知": {
+			addStampThreshold:    1,
+			rejectStampThreshold: 1,
+			stamps: []payload.MessageStamp{
+				{StampID: rejectStampID, UserID: adminIDs[0], CreatedAt: time.Now()},
+			},
+			invitations:       []*model.Invitation{model.NewInvitation(uuid.NewString(), "ikura-hamu", "ikura-hamu", origin)},
+			executeAddStamp:   true,
+			statusUpdates:     []model.InvitationStatus{model.InvitationStatusRejected},
+			decisionVoteCount: 1,
+			notifyText:        "@requester 招待の申請は却下されました\n@ikura-hamu (ikura-hamu)\nhttps://q.trap.jp/messages/" + originMessageID,
+		},
+		"却下の理由を通知": {
+			addStampThreshold:    1,
+			rejectStampThreshold: 1,
+			stamps: []payload.MessageStamp{
+				{StampID: rejectStampID, UserID: adminIDs[0], CreatedAt: time.Now()},
+			},
+			invitations: []*model.Invitation{
+				model.NewInvitation(uuid.NewString(), "ikura-hamu", "ikura-hamu", origin, model.WithReason("GitHub IDが違います")),
+			},
+			executeAddStamp:   true,
+			statusUpdates:     []model.InvitationStatus{model.InvitationStatusRejected},
+			decisionVoteCount: 1,
+			notifyText:        "@requester 招待の申請は却下されました\n理由: GitHub IDが違います\n@ikura-hamu (ikura-hamu)\nhttps://q.trap.jp/messages/" + originMessageID,
+		},
+		"送信の失敗を申請したチャンネルに通知": {
+			addStampThreshold:    1,
+			rejectStampThreshold: 1,
+			stamps: []payload.MessageStamp{
+				{StampID: acceptStampID, UserID: adminIDs[0], CreatedAt: time.Now()},
+			},
+			invitations:            []*model.Invitation{model.NewInvitation(uuid.NewString(), "ikura-hamu", "ikura-hamu", origin)},
+			executeAddStamp:        true,
+			executePostMessage:     true,
+			executeSendInvitations: true,
+			postMessageText:        "招待を送信できませんでした。`/retry` で送り直せます\n@ikura-hamu (ikura-hamu) GitHub APIの利用制限に達しました\n",
+			statusUpdates:          []model.InvitationStatus{model.InvitationStatusApproved, model.InvitationStatusSendFailed},
+			sendResultStatuses:     []model.SendResultStatus{model.SendResultStatusRateLimited},
+			decisionVoteCount:      1,
+			notifyText:             "@requester 招待は承認されましたが、送信に失敗しました。adminが対応するまでお待ちください\n@ikura-hamu (ikura-hamu)\nhttps://q.trap.jp/messages/" + originMessageID,
 		},
 		"GetInvitationsがErrRecordNotFound": {
 			addStampThreshold:    1,
@@ -160,6 +322,11 @@ func TestAcceptOrReject(t *testing.T) {
 			t.Parallel()
 
 			invRepoMock := repomock.InvitationMock{}
+			accountLinkRepoMock := repomock.AccountLinkMock{
+				SaveAccountLinkFunc: func(context.Context, *model.AccountLink) error {
+					return nil
+				},
+			}
 			traqMock := mock.TraqMock{}
 			gitHubMock := mock.GitHubMock{}
 
@@ -168,19 +335,39 @@ func TestAcceptOrReject(t *testing.T) {
 				Stamps:    test.stamps,
 			}
 
+			conf := &Config{
+				acceptStampID:        acceptStampID,
+				rejectStampID:        rejectStampID,
+				inactiveStampID:      inactiveStampID,
+				acceptStampThreshold: test.addStampThreshold,
+				rejectStampThreshold: test.rejectStampThreshold,
+				adminGroupID:         uuid.New().String(),
+				adminGroupName:       "GitHub_org_Admin",
+			}
+			p, err := loadPolicy(conf, &traqMock)
+			require.NoError(t, err)
+
+			decisionRepoMock := &repomock.DecisionMock{
+				CreateDecisionFunc: func(context.Context, *model.Decision) error {
+					return nil
+				},
+			}
+
 			bh := &BotHandler{
 				traqClient:   &traqMock,
 				githubClient: &gitHubMock,
 				ir:           &invRepoMock,
-				botUser:      model.NewUser(botUserID, "BOT_traP-jp"),
-				Config: &Config{
-					acceptStampID:        acceptStampID,
-					rejectStampID:        rejectStampID,
-					inactiveStampID:      inactiveStampID,
-					acceptStampThreshold: test.addStampThreshold,
-					rejectStampThreshold: test.rejectStampThreshold,
-					adminGroupID:         uuid.New().String(),
+				dr:           decisionRepoMock,
+				alr:          &accountLinkRepoMock,
+				tx:           newTransactionMock(),
+				rr: &repomock.RemovalMock{
+					GetRemovalFunc: func(context.Context, string) (*model.Removal, error) {
+						return nil, repository.ErrRecordNotFound
+					},
 				},
+				policy:  p,
+				botUser: model.NewUser(botUserID, "BOT_traP-jp"),
+				Config:  conf,
 			}
 
 			traqMock.AddStampFunc = func(context.Context, string, string, int) error {
@@ -192,15 +379,42 @@ func TestAcceptOrReject(t *testing.T) {
 			traqMock.PostMessageFunc = func(context.Context, string, string) (string, error) {
 				return "", nil
 			}
-
-			gitHubMock.SendInvitationsFunc = func(context.Context, []*model.Invitation) error {
+			traqMock.GetUserFunc = func(_ context.Context, userID string) (*model.User, error) {
+				return model.NewUser(userID, "admin"), nil
+			}
+			traqMock.GetMessageFunc = func(context.Context, string) (string, error) {
+				return "message", nil
+			}
+			traqMock.EditMessageFunc = func(context.Context, string, string) error {
 				return nil
 			}
 
+			gitHubMock.OrgNameFunc = func() string {
+				return "traP-jp"
+			}
+			gitHubMock.SendInvitationsFunc = func(_ context.Context, invitations []*model.Invitation) []*model.SendResult {
+				results := make([]*model.SendResult, 0, len(invitations))
+				for i, inv := range invitations {
+					status := model.SendResultStatusSent
+					if i < len(test.sendResultStatuses) {
+						status = test.sendResultStatuses[i]
+					}
+					var err error
+					if status != model.SendResultStatusSent {
+						err = errors.New(string(status))
+					}
+					results = append(results, model.NewSendResult(inv, status, err))
+				}
+				return results
+			}
+
 			invRepoMock.GetInvitationsFunc = func(context.Context, string) ([]*model.Invitation, error) {
 				return test.invitations, test.GetInvitationsErr
 			}
-			invRepoMock.DeleteInvitationsFunc = func(ctx context.Context, invitationID string) error {
+			invRepoMock.DecideInvitationsFunc = func(context.Context, string, model.InvitationStatus) error {
+				return test.DecideInvitationsErr
+			}
+			invRepoMock.UpdateInvitationStatusByGitHubIDFunc = func(context.Context, string, string, model.InvitationStatus) error {
 				return nil
 			}
 
@@ -222,21 +436,74 @@ func TestAcceptOrReject(t *testing.T) {
 				assert.Len(t, gitHubMock.SendInvitationsCalls(), 0)
 			}
 
+			postMessageCalls := traqMock.PostMessageCalls()
+			expectedPostCount := 0
 			if test.executePostMessage {
-				assert.Len(t, traqMock.PostMessageCalls(), 1)
-				assert.Equal(t, bh.botChannelID, traqMock.PostMessageCalls()[0].ChannelID)
-				assert.Equal(t, test.postMessageText, traqMock.PostMessageCalls()[0].Text)
+				expectedPostCount++
+			}
+			if test.notifyText != "" {
+				expectedPostCount++
+			}
+			assert.Len(t, postMessageCalls, expectedPostCount)
+
+			if test.executePostMessage && len(postMessageCalls) > 0 {
+				assert.Equal(t, bh.botChannelID, postMessageCalls[0].ChannelID)
+				assert.Equal(t, test.postMessageText, postMessageCalls[0].Text)
+			}
+			if test.notifyText != "" && len(postMessageCalls) == expectedPostCount {
+				assert.Equal(t, originChannelID, postMessageCalls[expectedPostCount-1].ChannelID)
+				assert.Equal(t, test.notifyText, postMessageCalls[expectedPostCount-1].Text)
+			}
+
+			// 招待の状態と判定の記録は、同じトランザクションで書き込む
+			if len(test.statusUpdates) > 0 {
+				require.Len(t, invRepoMock.DecideInvitationsCalls(), 1)
+				assert.Equal(t, payload.MessageID, invRepoMock.DecideInvitationsCalls()[0].InvitationID)
+				assert.Len(t, bh.tx.(*repomock.TransactionMock).RunInTxCalls(), 1)
 			} else {
-				assert.Len(t, traqMock.PostMessageCalls(), 0)
+				assert.Len(t, invRepoMock.DecideInvitationsCalls(), 0)
+			}
+			if len(test.statusUpdates) > 0 && test.DecideInvitationsErr == nil {
+				require.Len(t, decisionRepoMock.CreateDecisionCalls(), 1)
+				decision := decisionRepoMock.CreateDecisionCalls()[0].Decision
+				assert.Equal(t, payload.MessageID, decision.MessageID())
+				assert.Equal(t, model.DecisionKindInvitation, decision.Kind())
+				assert.Equal(t, string(test.statusUpdates[0]), string(decision.Result()))
+				assert.Len(t, decision.Votes(), test.decisionVoteCount)
+				for _, vote := range decision.Votes() {
+					assert.Contains(t, adminIDs, vote.UserID())
+				}
+			} else {
+				assert.Empty(t, decisionRepoMock.CreateDecisionCalls())
 			}
 
-			if test.executeDeleteInvitations {
-				assert.Len(t, invRepoMock.DeleteInvitationsCalls(), 1)
-				assert.Equal(t, payload.MessageID, invRepoMock.DeleteInvitationsCalls()[0].InvitationID)
+			// 承認されたら、アカウントの対応を記録する
+			if len(test.statusUpdates) > 0 && test.statusUpdates[0] == model.InvitationStatusApproved && test.DecideInvitationsErr == nil {
+				linkCalls := accountLinkRepoMock.SaveAccountLinkCalls()
+				require.Len(t, linkCalls, len(test.invitations))
+				for i, call := range linkCalls {
+					assert.Equal(t, test.invitations[i].GitHubID(), call.Link.GitHubLogin())
+					assert.Equal(t, test.invitations[i].TraqID(), call.Link.TraqName())
+					assert.Equal(t, model.AccountLinkSourceInvitation, call.Link.Source())
+				}
 			} else {
-				assert.Len(t, invRepoMock.DeleteInvitationsCalls(), 0)
+				assert.Empty(t, accountLinkRepoMock.SaveAccountLinkCalls())
 			}
 
+			// 承認・却下はメッセージごと、送信の結果は1人ずつ記録される
+			statusUpdates := make([]model.InvitationStatus, 0, len(test.statusUpdates))
+			for _, call := range invRepoMock.DecideInvitationsCalls() {
+				statusUpdates = append(statusUpdates, call.Status)
+			}
+			for i, call := range invRepoMock.UpdateInvitationStatusByGitHubIDCalls() {
+				assert.Equal(t, test.invitations[i].GitHubID(), call.GitHubID)
+				statusUpdates = append(statusUpdates, call.Status)
+			}
+			if len(test.statusUpdates) == 0 {
+				assert.Empty(t, statusUpdates)
+			} else {
+				assert.Equal(t, test.statusUpdates, statusUpdates)
+			}
 		})
 	}
 }
@@ -0,0 +1,236 @@
+package handler
+
+import (
+	"context"
+	"fmt"
+	"hash/fnv"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/traP-jp/members_bot/model"
+	"github.com/traP-jp/members_bot/policy"
+	"github.com/traPtitech/traq-ws-bot/payload"
+)
+
+// 申請メッセージの本文と、判定の状況の区切り
+const progressSeparator = "\n\n---\n"
+
+// 判定に数えなかったスタンプを押した人の表示の始まり
+const excludedVotersNotePrefix = "\n申請者と招待されるユーザー本人のスタンプは数えていません"
+
+// 申請メッセージごとに、判定の状況の書き換えを1つずつ行うためのロック。
+// メッセージごとにロックを作らないように、メッセージIDのハッシュで分ける
+type progressLocks [64]sync.Mutex
+
+func (l *progressLocks) lock(messageID string) (unlock func()) {
+	hash := fnv.New32a()
+	hash.Write([]byte(messageID))
+	mu := &l[hash.Sum32()%uint32(len(l))]
+	mu.Lock()
+
+	return mu.Unlock
+}
+
+// updateProgress は、申請メッセージの末尾の判定の状況をprogressに書き換える
+func (h *BotHandler) updateProgress(ctx context.Context, messageID string, progress string) {
+	defer h.progressLocks.lock(messageID)()
+
+	h.editProgress(ctx, messageID, progress)
+}
+
+// updatePendingProgress は、招待が申請中のままの場合だけ、申請メッセージの末尾を判定中の状況に書き換える。
+// 他のイベントで判定された後に、古い判定中の状況で判定結果を上書きしないようにする
+func (h *BotHandler) updatePendingProgress(ctx context.Context, messageID string, progress string) {
+	defer h.progressLocks.lock(messageID)()
+
+	invitations, err := h.ir.GetInvitations(ctx, messageID)
+	if err != nil {
+		logger.Printf("failed to get invitations: %v", err)
+		return
+	}
+	if !slices.ContainsFunc(invitations, func(inv *model.Invitation) bool {
+		return inv.Status() == model.InvitationStatusPending
+	}) {
+		return // 判定済み
+	}
+
+	h.editProgress(ctx, messageID, progress)
+}
+
+// updateOutcome は、判定済みの申請メッセージの末尾を、記録した判定の結果と、判定の後に行ったことの結果outcomeに書き換える。
+// 数えなかったスタンプを押した人の表示は残す
+func (h *BotHandler) updateOutcome(ctx context.Context, kind model.DecisionKind, messageID string, outcome string) {
+	decision, err := h.dr.GetDecision(ctx, kind, messageID)
+	if err != nil {
+		logger.Printf("failed to get decision: %v", err)
+		return
+	}
+	progress, err := h.decidedProgressText(ctx, decision, outcome)
+	if err != nil {
+		logger.Printf("failed to make progress text: %v", err)
+		return
+	}
+
+	defer h.progressLocks.lock(messageID)()
+
+	content, err := h.traqClient.GetMessage(ctx, messageID)
+	if err != nil {
+		logger.Printf("failed to get message: %v", err)
+		return
+	}
+	if _, note, ok := strings.Cut(content, excludedVotersNotePrefix); ok {
+		progress += excludedVotersNotePrefix + note
+	}
+
+	h.replaceProgress(ctx, messageID, content, progress)
+}
+
+func (h *BotHandler) editProgress(ctx context.Context, messageID string, progress string) {
+	content, err := h.traqClient.GetMessage(ctx, messageID)
+	if err != nil {
+		logger.Printf("failed to get message: %v", err)
+		return
+	}
+
+	h.replaceProgress(ctx, messageID, content, progress)
+}
+
+// replaceProgress は、申請メッセージの本文contentの末尾の判定の状況をprogressに書き換える
+func (h *BotHandler) replaceProgress(ctx context.Context, messageID string, content string, progress string) {
+	body, _, _ := strings.Cut(content, progressSeparator)
+	text := body + progressSeparator + progress
+	if text == content {
+		return
+	}
+
+	err := h.traqClient.EditMessage(ctx, messageID, text)
+	if err != nil {
+		logger.Printf("failed to edit message: %v", err)
+	}
+}
+
+// pendingProgressText は、判定中の申請で判定の条件が数えているスタンプの数と押した人、判定の条件を返す
+func (h *BotHandler) pendingProgressText(ctx context.Context, result *policy.Result) (string, error) {
+	accepterIDs := make([]string, 0, len(result.Accepts))
+	for _, stamp := range result.Accepts {
+		accepterIDs = append(accepterIDs, stamp.UserID)
+	}
+	rejecterIDs := make([]string, 0, len(result.Rejects))
+	for _, stamp := range result.Rejects {
+		rejecterIDs = append(rejecterIDs, stamp.UserID)
+	}
+
+	accepters, err := h.userNames(ctx, accepterIDs)
+	if err != nil {
+		return "", err
+	}
+	rejecters, err := h.userNames(ctx, rejecterIDs)
+	if err != nil {
+		return "", err
+	}
+
+	return fmt.Sprintf("承認 %d%s / 却下 %d%s\n条件: %s",
+		len(accepters), votersText(accepters), len(rejecters), votersText(rejecters), h.policy.Description()), nil
+}
+
+// decidedProgressText は、判定された申請の結果と、判定結果と同じスタンプを押した人を返す。
+// outcomeが空でなければ、判定の後に行ったことの結果として続ける
+func (h *BotHandler) decidedProgressText(ctx context.Context, decision *model.Decision, outcome string) (string, error) {
+	accepted := decision.Result() != model.DecisionResultRejected
+	mark, label := "✅", "承認"
+	if !accepted {
+		mark, label = "❌", "却下"
+	}
+
+	// 拒否権のスタンプも却下として数える
+	voterIDs := make([]string, 0, len(decision.Votes()))
+	for _, vote := range decision.Votes() {
+		if (vote.StampID() == h.acceptStampID) == accepted {
+			voterIDs = append(voterIDs, vote.UserID())
+		}
+	}
+	voters, err := h.userNames(ctx, voterIDs)
+	if err != nil {
+		return "", err
+	}
+
+	text := fmt.Sprintf("%s %sされました (%s)", mark, label, formatTime(decision.DecidedAt()))
+	if len(voters) > 0 {
+		text = fmt.Sprintf("%s @%s が%sしました (%s)", mark, strings.Join(voters, ", @"), label, formatTime(decision.DecidedAt()))
+	}
+	if outcome != "" {
+		text += " — " + outcome
+	}
+
+	return text, nil
+}
+
+// excludedVotersNote は、判定に数えなかった申請者と招待されるユーザー本人のスタンプのうち、
+// 判定の条件が数えるスタンプを押した人を、数えなかったことと合わせて返す。いない場合は空文字列を返す
+func (h *BotHandler) excludedVotersNote(ctx context.Context, excluded []payload.MessageStamp) (string, error) {
+	if len(excluded) == 0 {
+		return "", nil
+	}
+
+	result, err := h.policy.Evaluate(ctx, &policy.Input{Stamps: excluded})
+	if err != nil {
+		return "", fmt.Errorf("failed to evaluate policy: %w", err)
+	}
+
+	voterIDs := make([]string, 0, len(result.Accepts)+len(result.Rejects))
+	for _, stamp := range append(slices.Clone(result.Accepts), result.Rejects...) {
+		voterIDs = append(voterIDs, stamp.UserID)
+	}
+	voters, err := h.userNames(ctx, voterIDs)
+	if err != nil {
+		return "", err
+	}
+	if len(voters) == 0 {
+		return "", nil
+	}
+
+	return excludedVotersNotePrefix + votersText(voters), nil
+}
+
+// sendOutcomeText は、申請メッセージの招待を送信した結果を返す
+func (h *BotHandler) sendOutcomeText(results []*model.SendResult) string {
+	if slices.ContainsFunc(results, (*model.SendResult).Failed) {
+		return "招待を送信できませんでした"
+	}
+	if len(results) > 0 && !slices.ContainsFunc(results, func(result *model.SendResult) bool {
+		return result.Status() != model.SendResultStatusAlreadyMember
+	}) {
+		return fmt.Sprintf("既に %s のメンバーでした", h.githubClient.OrgName())
+	}
+
+	return "招待を送信しました"
+}
+
+// userNames は、traQのユーザーのUUIDから、重複を除いてユーザー名を返す
+func (h *BotHandler) userNames(ctx context.Context, userIDs []string) ([]string, error) {
+	names := make([]string, 0, len(userIDs))
+	seen := make([]string, 0, len(userIDs))
+	for _, userID := range userIDs {
+		if slices.Contains(seen, userID) {
+			continue
+		}
+		seen = append(seen, userID)
+
+		user, err := h.traqClient.GetUser(ctx, userID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get user: %w", err)
+		}
+		names = append(names, user.Name())
+	}
+
+	return names, nil
+}
+
+func votersText(names []string) string {
+	if len(names) == 0 {
+		return ""
+	}
+
+	return " (@" + strings.Join(names, ", @") + ")"
+}
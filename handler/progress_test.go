@@ -0,0 +1,242 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"regexp"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/traP-jp/members_bot/model"
+	repomock "github.com/traP-jp/members_bot/repository/mock"
+	"github.com/traP-jp/members_bot/service/mock"
+	"github.com/traPtitech/traq-ws-bot/payload"
+)
+
+func TestAcceptOrRejectProgress(t *testing.T) {
+	t.Parallel()
+
+	botUserID := uuid.NewString()
+	adminIDs := []string{uuid.NewString(), uuid.NewString()}
+	userNames := map[string]string{adminIDs[0]: "alice", adminIDs[1]: "bob"}
+	acceptStampID := uuid.NewString()
+	rejectStampID := uuid.NewString()
+	inactiveStampID := uuid.NewString()
+	condition := "条件: @GitHub_org_Admin のメンバーの承認スタンプが2個で承認、却下スタンプが1個で却下"
+	ownerGroupID := uuid.NewString()
+
+	type test struct {
+		content          string
+		stamps           []payload.MessageStamp
+		sendResultStatus model.SendResultStatus
+		approvalPolicy   string
+		requesterID      string
+		// 判定中の状況を書き換える前に、他のイベントで判定されるか
+		decidedMeanwhile bool
+		editedText       string
+		// 判定された日時が入るので、正規表現で比べる
+		editedPattern string
+	}
+
+	testCases := map[string]test{
+		"判定中": {
+			content: "申請",
+			stamps: []payload.MessageStamp{
+				{StampID: acceptStampID, UserID: adminIDs[0], CreatedAt: time.Now()},
+				{StampID: acceptStampID, UserID: uuid.NewString(), CreatedAt: time.Now()},
+			},
+			editedText: "申請\n\n---\n承認 1 (@alice) / 却下 0\n" + condition,
+		},
+		"判定の条件が数えるスタンプを表示する": {
+			content: "申請",
+			stamps: []payload.MessageStamp{
+				{StampID: acceptStampID, UserID: adminIDs[0], CreatedAt: time.Now()},
+				{StampID: acceptStampID, UserID: adminIDs[1], CreatedAt: time.Now()},
+			},
+			approvalPolicy: `{"type": "threshold", "group_id": "` + ownerGroupID + `", "group_name": "GitHub_org_Owner", "accept": 2, "reject": 2}`,
+			editedText:     "申請\n\n---\n承認 1 (@alice) / 却下 0\n条件: @GitHub_org_Owner のメンバーの承認スタンプが2個で承認、却下スタンプが2個で却下",
+		},
+		"判定中の状況を書き換える前に判定されたら、判定結果を上書きしない": {
+			content: "申請\n\n---\n✅ @alice, @bob が承認しました (2026/10/18 12:00) — 招待を送信しました",
+			stamps: []payload.MessageStamp{
+				{StampID: acceptStampID, UserID: adminIDs[0], CreatedAt: time.Now()},
+			},
+			decidedMeanwhile: true,
+		},
+		"申請者のスタンプを数えなかったことを表示する": {
+			content: "申請",
+			stamps: []payload.MessageStamp{
+				{StampID: acceptStampID, UserID: adminIDs[0], CreatedAt: time.Now()},
+				{StampID: acceptStampID, UserID: adminIDs[1], CreatedAt: time.Now()},
+			},
+			requesterID: adminIDs[1],
+			editedText:  "申請\n\n---\n承認 1 (@alice) / 却下 0\n" + condition + "\n申請者と招待されるユーザー本人のスタンプは数えていません (@bob)",
+		},
+		"申請者が判定に関係ないスタンプを押しても表示しない": {
+			content: "申請",
+			stamps: []payload.MessageStamp{
+				{StampID: uuid.NewString(), UserID: adminIDs[1], CreatedAt: time.Now()},
+			},
+			requesterID: adminIDs[1],
+			editedText:  "申請\n\n---\n承認 0 / 却下 0\n" + condition,
+		},
+		"スタンプが外された": {
+			content:    "申請\n\n---\n承認 1 (@alice) / 却下 0\n" + condition,
+			stamps:     []payload.MessageStamp{},
+			editedText: "申請\n\n---\n承認 0 / 却下 0\n" + condition,
+		},
+		"変わらなければ編集しない": {
+			content: "申請\n\n---\n承認 1 (@alice) / 却下 0\n" + condition,
+			stamps: []payload.MessageStamp{
+				{StampID: acceptStampID, UserID: adminIDs[0], CreatedAt: time.Now()},
+			},
+		},
+		"承認": {
+			content: "申請\n\n---\n承認 1 (@alice) / 却下 0\n" + condition,
+			stamps: []payload.MessageStamp{
+				{StampID: acceptStampID, UserID: adminIDs[0], CreatedAt: time.Now()},
+				{StampID: acceptStampID, UserID: adminIDs[1], CreatedAt: time.Now()},
+			},
+			editedPattern: `^申請\n\n---\n✅ @alice, @bob が承認しました \(\d{4}/\d{2}/\d{2} \d{2}:\d{2}\) — 招待を送信しました$`,
+		},
+		"承認されたが送信に失敗": {
+			content: "申請",
+			stamps: []payload.MessageStamp{
+				{StampID: acceptStampID, UserID: adminIDs[0], CreatedAt: time.Now()},
+				{StampID: acceptStampID, UserID: adminIDs[1], CreatedAt: time.Now()},
+			},
+			sendResultStatus: model.SendResultStatusError,
+			editedPattern:    `^申請\n\n---\n✅ @alice, @bob が承認しました \(.+\) — 招待を送信できませんでした$`,
+		},
+		"却下": {
+			content: "申請",
+			stamps: []payload.MessageStamp{
+				{StampID: acceptStampID, UserID: adminIDs[0], CreatedAt: time.Now()},
+				{StampID: rejectStampID, UserID: adminIDs[1], CreatedAt: time.Now()},
+			},
+			editedPattern: `^申請\n\n---\n❌ @bob が却下しました \(.+\)$`,
+		},
+	}
+
+	for name, test := range testCases {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			messageID := uuid.NewString()
+			var (
+				mu       sync.Mutex
+				getCount int
+			)
+
+			traqMock := &mock.TraqMock{
+				GetGroupMemberIDsFunc: func(_ context.Context, groupID string) ([]string, error) {
+					if groupID == ownerGroupID {
+						return adminIDs[:1], nil
+					}
+					return adminIDs, nil
+				},
+				GetUserFunc: func(_ context.Context, userID string) (*model.User, error) {
+					return model.NewUser(userID, userNames[userID]), nil
+				},
+				GetMessageFunc: func(context.Context, string) (string, error) {
+					return test.content, nil
+				},
+				EditMessageFunc: func(context.Context, string, string) error {
+					return nil
+				},
+				AddStampFunc: func(context.Context, string, string, int) error {
+					return nil
+				},
+				PostMessageFunc: func(context.Context, string, string) (string, error) {
+					return "", nil
+				},
+			}
+			gitHubMock := &mock.GitHubMock{
+				OrgNameFunc: func() string {
+					return "traP-jp"
+				},
+				SendInvitationsFunc: func(_ context.Context, invitations []*model.Invitation) []*model.SendResult {
+					if test.sendResultStatus != "" {
+						return []*model.SendResult{model.NewSendResult(invitations[0], test.sendResultStatus, errors.New("send invitations error"))}
+					}
+					return []*model.SendResult{model.NewSendResult(invitations[0], model.SendResultStatusSent, nil)}
+				},
+			}
+			invRepoMock := &repomock.InvitationMock{
+				GetInvitationsFunc: func(context.Context, string) ([]*model.Invitation, error) {
+					mu.Lock()
+					defer mu.Unlock()
+					getCount++
+					opts := []model.InvitationOption{}
+					if test.requesterID != "" {
+						opts = append(opts, model.WithOrigin(model.NewUser(test.requesterID, userNames[test.requesterID]), "", ""))
+					}
+					if test.decidedMeanwhile && getCount > 1 {
+						opts = append(opts, model.WithStatus(model.InvitationStatusApproved))
+					}
+					return []*model.Invitation{model.NewInvitation(messageID, "@ikura-hamu", "ikura-hamu", opts...)}, nil
+				},
+				DecideInvitationsFunc: func(context.Context, string, model.InvitationStatus) error {
+					return nil
+				},
+				UpdateInvitationStatusByGitHubIDFunc: func(context.Context, string, string, model.InvitationStatus) error {
+					return nil
+				},
+			}
+
+			conf := &Config{
+				botChannelID:         "botChannelID",
+				acceptStampID:        acceptStampID,
+				rejectStampID:        rejectStampID,
+				inactiveStampID:      inactiveStampID,
+				acceptStampThreshold: 2,
+				rejectStampThreshold: 1,
+				adminGroupID:         uuid.NewString(),
+				adminGroupName:       "GitHub_org_Admin",
+				approvalPolicy:       test.approvalPolicy,
+			}
+			p, err := loadPolicy(conf, traqMock)
+			require.NoError(t, err)
+
+			bh := &BotHandler{
+				traqClient:   traqMock,
+				githubClient: gitHubMock,
+				ir:           invRepoMock,
+				dr: &repomock.DecisionMock{
+					CreateDecisionFunc: func(context.Context, *model.Decision) error {
+						return nil
+					},
+				},
+				tx: newTransactionMock(),
+				alr: &repomock.AccountLinkMock{
+					SaveAccountLinkFunc: func(context.Context, *model.AccountLink) error {
+						return nil
+					},
+				},
+				policy:  p,
+				botUser: model.NewUser(botUserID, "BOT_traP-jp"),
+				Config:  conf,
+			}
+
+			bh.AcceptOrReject(&payload.BotMessageStampsUpdated{MessageID: messageID, Stamps: test.stamps})
+
+			editCalls := traqMock.EditMessageCalls()
+			if test.editedText == "" && test.editedPattern == "" {
+				assert.Empty(t, editCalls)
+				return
+			}
+
+			require.Len(t, editCalls, 1)
+			assert.Equal(t, messageID, editCalls[0].MessageID)
+			if test.editedText != "" {
+				assert.Equal(t, test.editedText, editCalls[0].Text)
+			} else {
+				assert.Regexp(t, regexp.MustCompile(test.editedPattern), editCalls[0].Text)
+			}
+		})
+	}
+}
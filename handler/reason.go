@@ -0,0 +1,115 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/traP-jp/members_bot/model"
+	"github.com/traP-jp/members_bot/repository"
+	"github.com/traPtitech/traq-ws-bot/payload"
+)
+
+const reasonCommandUsage = "`@BOT_traP-jp /(reason|理由) <申請メッセージのURL> <理由>`"
+
+// traQのメッセージのURLか、メッセージのUUID
+var messageURLPattern = regexp.MustCompile(`^(?:https://q\.trap\.jp/messages/)?([0-9a-fA-F-]{36})$`)
+
+func reasonCommandMessage(message string) string {
+	return fmt.Sprintf("%s\n%s", message, reasonCommandUsage)
+}
+
+// reason は、申請を却下する理由を記録する。
+// 既に却下されている場合は、申請者に理由を通知する
+func (h *BotHandler) reason(p *payload.MessageCreated) {
+	ctx := context.Background()
+
+	mentionRawText, _ := checkIfBotMentioned(p, h.botUser.ID())
+	text := strings.TrimSpace(strings.Replace(p.Message.PlainText, mentionRawText, "", 1))
+	splitText := regexp.MustCompile(`\s+`).Split(text, -1)
+
+	if len(splitText) > 1 && slices.Contains([]string{"-h", "-help", "--help"}, splitText[1]) {
+		_, err := h.traqClient.PostMessage(ctx, p.Message.ChannelID,
+			reasonCommandMessage("/reason は、申請を却下する理由を申請者に伝えるためのコマンドです。"))
+		if err != nil {
+			logger.Println("failed to post message: ", err)
+		}
+		return
+	}
+
+	if len(splitText) < 3 {
+		_, err := h.traqClient.PostMessage(ctx, p.Message.ChannelID, reasonCommandMessage("引数が足りません"))
+		if err != nil {
+			logger.Println("failed to post message: ", err)
+		}
+		return
+	}
+
+	matches := messageURLPattern.FindStringSubmatch(splitText[1])
+	if matches == nil {
+		_, err := h.traqClient.PostMessage(ctx, p.Message.ChannelID, reasonCommandMessage("申請メッセージのURLを指定してください"))
+		if err != nil {
+			logger.Println("failed to post message: ", err)
+		}
+		return
+	}
+	messageID := strings.ToLower(matches[1])
+
+	// 理由は改行を含むことがあるので、分割前のテキストから取り出す
+	reason := strings.TrimSpace(text[strings.Index(text, splitText[1])+len(splitText[1]):])
+
+	isAdmin, err := h.isAdmin(ctx, p.Message.User.ID)
+	if err != nil {
+		logger.Println("failed to check admin: ", err)
+		return
+	}
+	if !isAdmin {
+		_, err := h.traqClient.PostMessage(ctx, p.Message.ChannelID, "理由を記録できるのはadminのみです")
+		if err != nil {
+			logger.Println("failed to post message: ", err)
+		}
+		return
+	}
+
+	invitations, err := h.ir.GetInvitations(ctx, messageID)
+	if errors.Is(err, repository.ErrRecordNotFound) {
+		_, err := h.traqClient.PostMessage(ctx, p.Message.ChannelID, "申請が見つかりません")
+		if err != nil {
+			logger.Println("failed to post message: ", err)
+		}
+		return
+	}
+	if err != nil {
+		logger.Println("failed to get invitations: ", err)
+		return
+	}
+
+	status := invitations[0].Status()
+	if status != model.InvitationStatusPending && status != model.InvitationStatusRejected {
+		_, err := h.traqClient.PostMessage(ctx, p.Message.ChannelID, "この申請は却下されていません")
+		if err != nil {
+			logger.Println("failed to post message: ", err)
+		}
+		return
+	}
+
+	err = h.ir.UpdateInvitationReason(ctx, messageID, reason)
+	if err != nil {
+		logger.Println("failed to update invitation reason: ", err)
+		return
+	}
+
+	message := "理由を記録しました。却下された場合、申請者に通知されます"
+	if status == model.InvitationStatusRejected {
+		h.notifyRequester(ctx, invitations, "招待の申請が却下された理由が追加されました\n理由: "+reason)
+		message = "理由を記録し、申請者に通知しました"
+	}
+
+	_, err = h.traqClient.PostMessage(ctx, p.Message.ChannelID, message)
+	if err != nil {
+		logger.Println("failed to post message: ", err)
+	}
+}
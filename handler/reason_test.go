@@ -0,0 +1,151 @@
+package handler
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/traP-jp/members_bot/model"
+	"github.com/traP-jp/members_bot/repository"
+	repomock "github.com/traP-jp/members_bot/repository/mock"
+	"github.com/traP-jp/members_bot/service/mock"
+	"github.com/traPtitech/traq-ws-bot/payload"
+)
+
+func TestReason(t *testing.T) {
+	t.Parallel()
+
+	botUserID := uuid.NewString()
+	adminID := uuid.NewString()
+	adminMessageID := uuid.NewString()
+	originChannelID := uuid.NewString()
+	originMessageID := uuid.NewString()
+	origin := model.WithOrigin(model.NewUser(uuid.NewString(), "requester"), originChannelID, originMessageID)
+
+	type test struct {
+		plainText         string
+		userID            string
+		invitations       []*model.Invitation
+		GetInvitationsErr error
+		updateReason      string
+		notifyText        string
+		postText          string
+	}
+
+	testCases := map[string]test{
+		"申請中の招待に理由を記録": {
+			plainText:    "@BOT_traP-jp /reason https://q.trap.jp/messages/" + adminMessageID + " GitHub IDが違います",
+			userID:       adminID,
+			invitations:  []*model.Invitation{model.NewInvitation(adminMessageID, "ikura-hamu", "ikura-hamu", origin)},
+			updateReason: "GitHub IDが違います",
+			postText:     "理由を記録しました。却下された場合、申請者に通知されます",
+		},
+		"却下された招待に理由を記録して通知": {
+			plainText: "@BOT_traP-jp /理由 " + adminMessageID + " GitHub IDが\n違います",
+			userID:    adminID,
+			invitations: []*model.Invitation{
+				model.NewInvitation(adminMessageID, "ikura-hamu", "ikura-hamu", origin, model.WithStatus(model.InvitationStatusRejected)),
+			},
+			updateReason: "GitHub IDが\n違います",
+			notifyText:   "@requester 招待の申請が却下された理由が追加されました\n理由: GitHub IDが\n違います\n@ikura-hamu (ikura-hamu)\nhttps://q.trap.jp/messages/" + originMessageID,
+			postText:     "理由を記録し、申請者に通知しました",
+		},
+		"承認済み": {
+			plainText: "@BOT_traP-jp /reason " + adminMessageID + " 理由",
+			userID:    adminID,
+			invitations: []*model.Invitation{
+				model.NewInvitation(adminMessageID, "ikura-hamu", "ikura-hamu", origin, model.WithStatus(model.InvitationStatusSent)),
+			},
+			postText: "この申請は却下されていません",
+		},
+		"申請が見つからない": {
+			plainText:         "@BOT_traP-jp /reason " + adminMessageID + " 理由",
+			userID:            adminID,
+			GetInvitationsErr: repository.ErrRecordNotFound,
+			postText:          "申請が見つかりません",
+		},
+		"adminではない": {
+			plainText: "@BOT_traP-jp /reason " + adminMessageID + " 理由",
+			userID:    uuid.NewString(),
+			postText:  "理由を記録できるのはadminのみです",
+		},
+		"URLではない": {
+			plainText: "@BOT_traP-jp /reason ikura-hamu 理由",
+			userID:    adminID,
+			postText:  reasonCommandMessage("申請メッセージのURLを指定してください"),
+		},
+		"引数が足りない": {
+			plainText: "@BOT_traP-jp /reason " + adminMessageID,
+			userID:    adminID,
+			postText:  reasonCommandMessage("引数が足りません"),
+		},
+	}
+
+	for name, test := range testCases {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			traqMock := &mock.TraqMock{
+				PostMessageFunc: func(context.Context, string, string) (string, error) {
+					return "", nil
+				},
+				GetGroupMemberIDsFunc: func(context.Context, string) ([]string, error) {
+					return []string{adminID}, nil
+				},
+			}
+			invRepoMock := &repomock.InvitationMock{
+				GetInvitationsFunc: func(context.Context, string) ([]*model.Invitation, error) {
+					return test.invitations, test.GetInvitationsErr
+				},
+				UpdateInvitationReasonFunc: func(context.Context, string, string) error {
+					return nil
+				},
+			}
+
+			bh := &BotHandler{
+				traqClient: traqMock,
+				ir:         invRepoMock,
+				botUser:    model.NewUser(botUserID, "BOT_traP-jp"),
+				Config: &Config{
+					botChannelID: "botChannelID",
+					adminGroupID: uuid.NewString(),
+				},
+			}
+
+			payload := &payload.MessageCreated{
+				Message: payload.Message{
+					PlainText: test.plainText,
+					ID:        uuid.NewString(),
+					ChannelID: "botChannelID",
+					Embedded:  []payload.EmbeddedInfo{{Type: "user", Raw: "@BOT_traP-jp", ID: botUserID}},
+					User:      payload.User{ID: test.userID},
+					CreatedAt: time.Now(),
+					UpdatedAt: time.Now(),
+				},
+				Base: payload.Base{EventTime: time.Now()},
+			}
+			bh.reason(payload)
+
+			if test.updateReason != "" {
+				assert.Len(t, invRepoMock.UpdateInvitationReasonCalls(), 1)
+				assert.Equal(t, adminMessageID, invRepoMock.UpdateInvitationReasonCalls()[0].InvitationID)
+				assert.Equal(t, test.updateReason, invRepoMock.UpdateInvitationReasonCalls()[0].Reason)
+			} else {
+				assert.Len(t, invRepoMock.UpdateInvitationReasonCalls(), 0)
+			}
+
+			postMessageCalls := traqMock.PostMessageCalls()
+			if test.notifyText != "" {
+				assert.Len(t, postMessageCalls, 2)
+				assert.Equal(t, originChannelID, postMessageCalls[0].ChannelID)
+				assert.Equal(t, test.notifyText, postMessageCalls[0].Text)
+			} else {
+				assert.Len(t, postMessageCalls, 1)
+			}
+			assert.Equal(t, "botChannelID", postMessageCalls[len(postMessageCalls)-1].ChannelID)
+			assert.Equal(t, test.postText, postMessageCalls[len(postMessageCalls)-1].Text)
+		})
+	}
+}
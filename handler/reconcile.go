@@ -0,0 +1,73 @@
+package handler
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/traP-jp/members_bot/model"
+)
+
+// ReconcileInvitations は、申請中の招待と、申請中のメンバーから外す申請のメッセージに今押されているスタンプを取得し、
+// AcceptOrReject と同じように判定する。
+// botが停止していたり、WebSocketが切断されていたりした間のスタンプのイベントは届かないので、接続したときと定期的に呼ぶ。
+// 承認された後や送り直している途中で処理が止まった招待も、送信に失敗した招待として /retry で送り直せるようにする
+func (h *BotHandler) ReconcileInvitations(ctx context.Context) {
+	h.releaseStaleInvitations(ctx)
+
+	invitations, err := h.ir.GetInvitationsByStatus(ctx, model.InvitationStatusPending)
+	if err != nil {
+		logger.Printf("failed to get invitations: %v", err)
+		return
+	}
+
+	removals, err := h.rr.GetRemovalsByStatus(ctx, model.RemovalStatusPending)
+	if err != nil {
+		logger.Printf("failed to get removals: %v", err)
+		return
+	}
+
+	messageIDs := make([]string, 0, len(invitations)+len(removals))
+	for _, inv := range invitations {
+		if !slices.Contains(messageIDs, inv.MessageID()) {
+			messageIDs = append(messageIDs, inv.MessageID())
+		}
+	}
+	for _, removal := range removals {
+		messageIDs = append(messageIDs, removal.MessageID())
+	}
+
+	for _, messageID := range messageIDs {
+		stamps, err := h.traqClient.GetMessageStamps(ctx, messageID)
+		if err != nil {
+			logger.Printf("failed to get message stamps: %v", err)
+			continue
+		}
+
+		h.decide(ctx, messageID, stamps)
+	}
+}
+
+// releaseStaleInvitations は、送信の途中で処理が止まった招待を送信に失敗したものとして記録し、botのチャンネルで伝える
+func (h *BotHandler) releaseStaleInvitations(ctx context.Context) {
+	released, err := h.ir.ReleaseStaleInvitations(ctx, time.Now().Add(-staleSendingTimeout))
+	if err != nil {
+		logger.Printf("failed to release stale invitations: %v", err)
+		return
+	}
+	if len(released) == 0 {
+		return
+	}
+
+	message := "送信が完了しなかった招待があります。`/retry` で送り直せます\n"
+	for _, inv := range released {
+		message += fmt.Sprintf("@%s (%s)\n", strings.TrimPrefix(inv.TraqID(), "@"), inv.GitHubID())
+	}
+
+	_, err = h.traqClient.PostMessage(ctx, h.botChannelID, strings.TrimSuffix(message, "\n"))
+	if err != nil {
+		logger.Printf("failed to post message: %v", err)
+	}
+}
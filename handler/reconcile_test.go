@@ -0,0 +1,212 @@
+package handler
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/traP-jp/members_bot/model"
+	"github.com/traP-jp/members_bot/repository"
+	repomock "github.com/traP-jp/members_bot/repository/mock"
+	"github.com/traP-jp/members_bot/service/mock"
+	"github.com/traPtitech/traq-ws-bot/payload"
+)
+
+func TestReconcileInvitations(t *testing.T) {
+	t.Parallel()
+
+	botUserID := uuid.NewString()
+	adminIDs := []string{uuid.NewString(), uuid.NewString()}
+	acceptStampID := uuid.NewString()
+	rejectStampID := uuid.NewString()
+	inactiveStampID := uuid.NewString()
+
+	acceptedMessageID := uuid.NewString()
+	rejectedMessageID := uuid.NewString()
+	undecidedMessageID := uuid.NewString()
+	inactiveMessageID := uuid.NewString()
+	removalMessageID := uuid.NewString()
+
+	invitations := []*model.Invitation{
+		model.NewInvitation(acceptedMessageID, "@ikura-hamu", "ikura-hamu"),
+		model.NewInvitation(acceptedMessageID, "@H1rono", "H1rono"),
+		model.NewInvitation(rejectedMessageID, "@pikachu", "pikachu"),
+		model.NewInvitation(undecidedMessageID, "@cp20", "cp20"),
+		model.NewInvitation(inactiveMessageID, "@Pugma", "Pugma"),
+	}
+
+	stamps := map[string][]payload.MessageStamp{
+		acceptedMessageID: {
+			{StampID: acceptStampID, UserID: adminIDs[0], CreatedAt: time.Now()},
+			{StampID: acceptStampID, UserID: adminIDs[1], CreatedAt: time.Now()},
+		},
+		rejectedMessageID: {
+			{StampID: rejectStampID, UserID: adminIDs[0], CreatedAt: time.Now()},
+		},
+		undecidedMessageID: {
+			{StampID: acceptStampID, UserID: adminIDs[0], CreatedAt: time.Now()},
+		},
+		inactiveMessageID: {
+			{StampID: acceptStampID, UserID: adminIDs[0], CreatedAt: time.Now()},
+			{StampID: acceptStampID, UserID: adminIDs[1], CreatedAt: time.Now()},
+			{StampID: inactiveStampID, UserID: botUserID, CreatedAt: time.Now()},
+		},
+		removalMessageID: {
+			{StampID: rejectStampID, UserID: adminIDs[0], CreatedAt: time.Now()},
+		},
+	}
+
+	traqMock := &mock.TraqMock{
+		GetMessageStampsFunc: func(ctx context.Context, messageID string) ([]payload.MessageStamp, error) {
+			return stamps[messageID], nil
+		},
+		GetGroupMemberIDsFunc: func(context.Context, string) ([]string, error) {
+			return adminIDs, nil
+		},
+		AddStampFunc: func(context.Context, string, string, int) error {
+			return nil
+		},
+		PostMessageFunc: func(context.Context, string, string) (string, error) {
+			return "", nil
+		},
+		GetUserFunc: func(_ context.Context, userID string) (*model.User, error) {
+			return model.NewUser(userID, "admin"), nil
+		},
+		GetMessageFunc: func(context.Context, string) (string, error) {
+			return "message", nil
+		},
+		EditMessageFunc: func(context.Context, string, string) error {
+			return nil
+		},
+	}
+	gitHubMock := &mock.GitHubMock{
+		OrgNameFunc: func() string {
+			return "traP-jp"
+		},
+		SendInvitationsFunc: func(ctx context.Context, invitations []*model.Invitation) []*model.SendResult {
+			results := make([]*model.SendResult, 0, len(invitations))
+			for _, inv := range invitations {
+				results = append(results, model.NewSendResult(inv, model.SendResultStatusSent, nil))
+			}
+			return results
+		},
+	}
+	// 承認された後に、送信されないまま止まっていた
+	unsent := model.NewInvitation(uuid.NewString(), "@SSlime", "SSlime", model.WithStatus(model.InvitationStatusApproved))
+	invRepoMock := &repomock.InvitationMock{
+		ReleaseStaleInvitationsFunc: func(context.Context, time.Time) ([]*model.Invitation, error) {
+			return []*model.Invitation{unsent}, nil
+		},
+		GetInvitationsByStatusFunc: func(ctx context.Context, statuses ...model.InvitationStatus) ([]*model.Invitation, error) {
+			return invitations, nil
+		},
+		GetInvitationsFunc: func(ctx context.Context, messageID string) ([]*model.Invitation, error) {
+			result := make([]*model.Invitation, 0)
+			for _, inv := range invitations {
+				if inv.MessageID() == messageID {
+					result = append(result, inv)
+				}
+			}
+			if len(result) == 0 {
+				return nil, repository.ErrRecordNotFound
+			}
+			return result, nil
+		},
+		DecideInvitationsFunc: func(context.Context, string, model.InvitationStatus) error {
+			return nil
+		},
+		UpdateInvitationStatusByGitHubIDFunc: func(context.Context, string, string, model.InvitationStatus) error {
+			return nil
+		},
+	}
+
+	// 接続していなかった間に、メンバーから外す申請が却下されていた
+	removalRepoMock := &repomock.RemovalMock{
+		GetRemovalsByStatusFunc: func(context.Context, model.RemovalStatus) ([]*model.Removal, error) {
+			return []*model.Removal{model.NewRemoval(removalMessageID, "SSlime")}, nil
+		},
+		GetRemovalFunc: func(context.Context, string) (*model.Removal, error) {
+			return model.NewRemoval(removalMessageID, "SSlime"), nil
+		},
+		UpdateRemovalStatusFunc: func(context.Context, string, model.RemovalStatus) error {
+			return nil
+		},
+	}
+
+	conf := &Config{
+		botChannelID:         "botChannelID",
+		acceptStampID:        acceptStampID,
+		rejectStampID:        rejectStampID,
+		inactiveStampID:      inactiveStampID,
+		acceptStampThreshold: 2,
+		rejectStampThreshold: 1,
+		// メンバーから外す申請の承認に必要なスタンプの数
+		removalAcceptStampThreshold: 3,
+		adminGroupID:                uuid.NewString(),
+		adminGroupName:              "GitHub_org_Admin",
+	}
+	p, err := loadPolicy(conf, traqMock)
+	require.NoError(t, err)
+
+	bh := &BotHandler{
+		traqClient:   traqMock,
+		githubClient: gitHubMock,
+		ir:           invRepoMock,
+		rr:           removalRepoMock,
+		dr: &repomock.DecisionMock{
+			CreateDecisionFunc: func(context.Context, *model.Decision) error {
+				return nil
+			},
+		},
+		tx: newTransactionMock(),
+		alr: &repomock.AccountLinkMock{
+			SaveAccountLinkFunc: func(context.Context, *model.AccountLink) error {
+				return nil
+			},
+		},
+		policy:        p,
+		removalPolicy: loadRemovalPolicy(conf, traqMock),
+		botUser:       model.NewUser(botUserID, "BOT_traP-jp"),
+		Config:        conf,
+	}
+
+	bh.ReconcileInvitations(context.Background())
+
+	// 同じメッセージのスタンプは1度だけ取得する
+	getStampsCalls := traqMock.GetMessageStampsCalls()
+	require.Len(t, getStampsCalls, 5)
+	for i, messageID := range []string{acceptedMessageID, rejectedMessageID, undecidedMessageID, inactiveMessageID, removalMessageID} {
+		assert.Equal(t, messageID, getStampsCalls[i].MessageID)
+	}
+
+	decideCalls := invRepoMock.DecideInvitationsCalls()
+	require.Len(t, decideCalls, 2)
+	assert.Equal(t, acceptedMessageID, decideCalls[0].InvitationID)
+	assert.Equal(t, model.InvitationStatusApproved, decideCalls[0].Status)
+	assert.Equal(t, rejectedMessageID, decideCalls[1].InvitationID)
+	assert.Equal(t, model.InvitationStatusRejected, decideCalls[1].Status)
+
+	getRemovalsCalls := removalRepoMock.GetRemovalsByStatusCalls()
+	require.Len(t, getRemovalsCalls, 1)
+	assert.Equal(t, model.RemovalStatusPending, getRemovalsCalls[0].Status)
+	removalCalls := removalRepoMock.UpdateRemovalStatusCalls()
+	require.Len(t, removalCalls, 1)
+	assert.Equal(t, removalMessageID, removalCalls[0].MessageID)
+	assert.Equal(t, model.RemovalStatusRejected, removalCalls[0].Status)
+
+	sendCalls := gitHubMock.SendInvitationsCalls()
+	require.Len(t, sendCalls, 1)
+	assert.ElementsMatch(t, invitations[:2], sendCalls[0].Invitations)
+
+	// 送信されないまま止まっていた招待は、送信に失敗したものとしてbotのチャンネルで伝える
+	releaseCalls := invRepoMock.ReleaseStaleInvitationsCalls()
+	require.Len(t, releaseCalls, 1)
+	assert.WithinDuration(t, time.Now().Add(-staleSendingTimeout), releaseCalls[0].Before, time.Second)
+	postCalls := traqMock.PostMessageCalls()
+	require.NotEmpty(t, postCalls)
+	assert.Equal(t, "botChannelID", postCalls[0].ChannelID)
+	assert.Equal(t, "送信が完了しなかった招待があります。`/retry` で送り直せます\n@SSlime (SSlime)", postCalls[0].Text)
+}
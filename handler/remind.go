@@ -0,0 +1,75 @@
+package handler
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/traP-jp/members_bot/model"
+	"github.com/traP-jp/members_bot/policy"
+)
+
+// RemindInvitations は、申請されてから reminderAfter が過ぎても判定されていない招待を、adminにリマインドする。
+// 同じ招待は reminderInterval の間リマインドしない。判定された招待は申請中ではなくなるので、リマインドされなくなる
+func (h *BotHandler) RemindInvitations(ctx context.Context) {
+	now := time.Now()
+
+	invitations, err := h.ir.GetInvitationsCreatedBefore(ctx, model.InvitationStatusPending, now.Add(-h.reminderAfter))
+	if err != nil {
+		logger.Printf("failed to get invitations: %v", err)
+		return
+	}
+
+	invitations = slices.DeleteFunc(invitations, func(inv *model.Invitation) bool {
+		return inv.RemindedAt().After(now.Add(-h.reminderInterval))
+	})
+	if len(invitations) == 0 {
+		return
+	}
+
+	remindedMessageIDs := make([]string, 0, len(invitations))
+	message := fmt.Sprintf("@%s\n判定されていない招待の申請があります。承認・却下をお願いします\n", h.adminGroupName)
+	for _, inv := range invitations {
+		if slices.Contains(remindedMessageIDs, inv.MessageID()) {
+			continue
+		}
+
+		stamps, err := h.traqClient.GetMessageStamps(ctx, inv.MessageID())
+		if err != nil {
+			logger.Printf("failed to get message stamps: %v", err)
+			continue
+		}
+
+		in, _ := policyInput(inv, stamps)
+		result, err := h.policy.Evaluate(ctx, in)
+		if err != nil {
+			logger.Printf("failed to evaluate policy: %v", err)
+			continue
+		}
+		if result.Decision == policy.Accept || result.Decision == policy.Reject {
+			continue // 判定できるので、リマインドしない
+		}
+
+		message += fmt.Sprintf("@%s (%s) 承認 %d / 却下 %d https://q.trap.jp/messages/%s\n",
+			inv.TraqID(), inv.GitHubID(), len(result.Accepts), len(result.Rejects), inv.MessageID())
+		remindedMessageIDs = append(remindedMessageIDs, inv.MessageID())
+	}
+
+	if len(remindedMessageIDs) == 0 {
+		return
+	}
+
+	_, err = h.traqClient.PostMessage(ctx, h.botChannelID, message)
+	if err != nil {
+		logger.Printf("failed to post message: %v", err)
+		return
+	}
+
+	for _, messageID := range remindedMessageIDs {
+		err := h.ir.UpdateInvitationRemindedAt(ctx, messageID, now)
+		if err != nil {
+			logger.Printf("failed to update invitation reminded at: %v", err)
+		}
+	}
+}
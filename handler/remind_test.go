@@ -0,0 +1,165 @@
+package handler
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/traP-jp/members_bot/model"
+	"github.com/traP-jp/members_bot/policy"
+	repomock "github.com/traP-jp/members_bot/repository/mock"
+	"github.com/traP-jp/members_bot/service/mock"
+	"github.com/traPtitech/traq-ws-bot/payload"
+)
+
+func TestRemindInvitations(t *testing.T) {
+	t.Parallel()
+
+	acceptStampID := uuid.NewString()
+	rejectStampID := uuid.NewString()
+	customStampID := uuid.NewString()
+	adminGroupID := uuid.NewString()
+	adminIDs := []string{uuid.NewString(), uuid.NewString()}
+	requesterID := uuid.NewString()
+	origin := model.WithOrigin(model.NewUser(requesterID, "requester"), uuid.NewString(), uuid.NewString())
+	messageIDs := []string{uuid.NewString(), uuid.NewString()}
+
+	type test struct {
+		invitations []*model.Invitation
+		stamps      map[string][]payload.MessageStamp
+		// 判定の条件で使う承認スタンプ。空文字列の場合は acceptStampID
+		policyAcceptStampID string
+		postText            string
+		remindedMessageIDs  []string
+	}
+
+	testCases := map[string]test{
+		"判定されていない招待をリマインド": {
+			invitations: []*model.Invitation{
+				model.NewInvitation(messageIDs[0], "ikura-hamu", "ikura-hamu", origin),
+				model.NewInvitation(messageIDs[1], "H1rono_K", "H1rono", origin),
+			},
+			stamps: map[string][]payload.MessageStamp{
+				messageIDs[0]: {
+					{StampID: acceptStampID, UserID: adminIDs[0], CreatedAt: time.Now()},
+					{StampID: rejectStampID, UserID: adminIDs[1], CreatedAt: time.Now()},
+				},
+				messageIDs[1]: {
+					{StampID: acceptStampID, UserID: requesterID, CreatedAt: time.Now()},
+					{StampID: acceptStampID, UserID: uuid.NewString(), CreatedAt: time.Now()},
+				},
+			},
+			postText: "@GitHub_org_Admin\n判定されていない招待の申請があります。承認・却下をお願いします\n" +
+				"@ikura-hamu (ikura-hamu) 承認 1 / 却下 1 https://q.trap.jp/messages/" + messageIDs[0] + "\n" +
+				"@H1rono_K (H1rono) 承認 0 / 却下 0 https://q.trap.jp/messages/" + messageIDs[1] + "\n",
+			remindedMessageIDs: messageIDs,
+		},
+		"判定の条件が数えるスタンプの数を表示する": {
+			invitations: []*model.Invitation{
+				model.NewInvitation(messageIDs[0], "ikura-hamu", "ikura-hamu", origin),
+			},
+			stamps: map[string][]payload.MessageStamp{
+				messageIDs[0]: {
+					{StampID: customStampID, UserID: adminIDs[0], CreatedAt: time.Now()},
+					{StampID: acceptStampID, UserID: adminIDs[1], CreatedAt: time.Now()},
+				},
+			},
+			policyAcceptStampID: customStampID,
+			postText: "@GitHub_org_Admin\n判定されていない招待の申請があります。承認・却下をお願いします\n" +
+				"@ikura-hamu (ikura-hamu) 承認 1 / 却下 0 https://q.trap.jp/messages/" + messageIDs[0] + "\n",
+			remindedMessageIDs: messageIDs[:1],
+		},
+		"最近リマインドした招待はリマインドしない": {
+			invitations: []*model.Invitation{
+				model.NewInvitation(messageIDs[0], "ikura-hamu", "ikura-hamu", origin, model.WithRemindedAt(time.Now().Add(-time.Hour))),
+				model.NewInvitation(messageIDs[1], "H1rono_K", "H1rono", origin, model.WithRemindedAt(time.Now().Add(-25*time.Hour))),
+			},
+			postText: "@GitHub_org_Admin\n判定されていない招待の申請があります。承認・却下をお願いします\n" +
+				"@H1rono_K (H1rono) 承認 0 / 却下 0 https://q.trap.jp/messages/" + messageIDs[1] + "\n",
+			remindedMessageIDs: messageIDs[1:],
+		},
+		"判定できる招待はリマインドしない": {
+			invitations: []*model.Invitation{
+				model.NewInvitation(messageIDs[0], "ikura-hamu", "ikura-hamu", origin),
+			},
+			stamps: map[string][]payload.MessageStamp{
+				messageIDs[0]: {
+					{StampID: acceptStampID, UserID: adminIDs[0], CreatedAt: time.Now()},
+					{StampID: acceptStampID, UserID: adminIDs[1], CreatedAt: time.Now()},
+				},
+			},
+		},
+		"申請中の招待がない": {},
+	}
+
+	for name, test := range testCases {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			traqMock := &mock.TraqMock{
+				GetGroupMemberIDsFunc: func(ctx context.Context, groupID string) ([]string, error) {
+					return adminIDs, nil
+				},
+				GetMessageStampsFunc: func(ctx context.Context, messageID string) ([]payload.MessageStamp, error) {
+					return test.stamps[messageID], nil
+				},
+				PostMessageFunc: func(context.Context, string, string) (string, error) {
+					return uuid.NewString(), nil
+				},
+			}
+			irMock := &repomock.InvitationMock{
+				GetInvitationsCreatedBeforeFunc: func(ctx context.Context, status model.InvitationStatus, cutoff time.Time) ([]*model.Invitation, error) {
+					return test.invitations, nil
+				},
+				UpdateInvitationRemindedAtFunc: func(ctx context.Context, invitationID string, remindedAt time.Time) error {
+					return nil
+				},
+			}
+
+			policyAcceptStampID := test.policyAcceptStampID
+			if policyAcceptStampID == "" {
+				policyAcceptStampID = acceptStampID
+			}
+
+			bh := &BotHandler{
+				traqClient: traqMock,
+				ir:         irMock,
+				policy:     policy.NewThreshold(traqMock, adminGroupID, "GitHub_org_Admin", policyAcceptStampID, 2, rejectStampID, 2),
+				Config: &Config{
+					botChannelID:     "botChannelID",
+					acceptStampID:    acceptStampID,
+					rejectStampID:    rejectStampID,
+					adminGroupID:     adminGroupID,
+					adminGroupName:   "GitHub_org_Admin",
+					reminderAfter:    defaultReminderAfter,
+					reminderInterval: defaultReminderInterval,
+				},
+			}
+
+			bh.RemindInvitations(context.Background())
+
+			getCalls := irMock.GetInvitationsCreatedBeforeCalls()
+			require.Len(t, getCalls, 1)
+			assert.Equal(t, model.InvitationStatusPending, getCalls[0].Status)
+			assert.WithinDuration(t, time.Now().Add(-defaultReminderAfter), getCalls[0].Cutoff, time.Second)
+
+			postCalls := traqMock.PostMessageCalls()
+			if test.postText == "" {
+				assert.Empty(t, postCalls)
+			} else {
+				require.Len(t, postCalls, 1)
+				assert.Equal(t, "botChannelID", postCalls[0].ChannelID)
+				assert.Equal(t, test.postText, postCalls[0].Text)
+			}
+
+			updateCalls := irMock.UpdateInvitationRemindedAtCalls()
+			require.Len(t, updateCalls, len(test.remindedMessageIDs))
+			for i, messageID := range test.remindedMessageIDs {
+				assert.Equal(t, messageID, updateCalls[i].InvitationID)
+			}
+		})
+	}
+}
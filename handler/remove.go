@@ -0,0 +1,236 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/traP-jp/members_bot/model"
+	"github.com/traP-jp/members_bot/policy"
+	"github.com/traP-jp/members_bot/repository"
+	"github.com/traPtitech/traq-ws-bot/payload"
+)
+
+const removeCommandUsage = "`@BOT_traP-jp /(remove|除名) <GitHubID> [<理由>]`"
+
+func removeCommandMessage(message string) string {
+	return fmt.Sprintf("%s\n%s", message, removeCommandUsage)
+}
+
+// remove は、GitHubのOrganizationからメンバーを外す申請を受け付ける。
+// 招待と同じようにadminのスタンプで判定するが、承認に必要なスタンプの数は別に設定する
+func (h *BotHandler) remove(p *payload.MessageCreated) {
+	ctx := context.Background()
+
+	mentionRawText, _ := checkIfBotMentioned(p, h.botUser.ID())
+	splitText := regexp.MustCompile(`\s+`).Split(strings.TrimSpace(strings.Replace(p.Message.PlainText, mentionRawText, "", 1)), 3)
+
+	if len(splitText) < 2 {
+		_, err := h.traqClient.PostMessage(ctx, p.Message.ChannelID, removeCommandMessage("引数が足りません"))
+		if err != nil {
+			logger.Println("failed to post message: ", err)
+		}
+		return
+	}
+
+	if slices.Contains([]string{"-h", "-help", "--help"}, splitText[1]) {
+		_, err := h.traqClient.PostMessage(ctx, p.Message.ChannelID,
+			removeCommandMessage("/remove は、GitHubのOrganizationからメンバーを外すことを申請するためのコマンドです。"))
+		if err != nil {
+			logger.Println("failed to post message: ", err)
+		}
+		return
+	}
+
+	gitHubID := splitText[1]
+	reason := ""
+	if len(splitText) == 3 {
+		reason = splitText[2]
+	}
+
+	isMember, err := h.githubClient.CheckUserIsMember(ctx, gitHubID)
+	if err != nil {
+		logger.Println("failed to check user is member: ", err)
+		return
+	}
+	if !isMember {
+		_, err := h.traqClient.PostMessage(ctx, p.Message.ChannelID,
+			fmt.Sprintf("GitHubユーザー %s は %s のメンバーではありません", gitHubID, h.githubClient.OrgName()))
+		if err != nil {
+			logger.Println("failed to post message: ", err)
+		}
+		return
+	}
+
+	message := fmt.Sprintf("@%s\n%s から外す申請です\nhttps://github.com/%s\n", h.adminGroupName, h.githubClient.OrgName(), gitHubID)
+	if reason != "" {
+		message += "理由: " + reason + "\n"
+	}
+	message += fmt.Sprintf("https://q.trap.jp/messages/%s\n※申請者のスタンプは数えません。「%s」で判定します",
+		p.Message.ID, h.removalPolicy.Description())
+
+	messageID, err := h.traqClient.PostMessage(ctx, h.botChannelID, message)
+	if err != nil {
+		logger.Printf("failed to post message: %v", err)
+		return
+	}
+
+	removal := model.NewRemoval(messageID, gitHubID,
+		model.WithRemovalReason(reason),
+		model.WithRemovalOrigin(model.NewUser(p.Message.User.ID, p.Message.User.Name), p.Message.ChannelID, p.Message.ID))
+	err = h.rr.CreateRemoval(ctx, removal)
+	if err != nil {
+		logger.Println("failed to create removal: ", err)
+		return
+	}
+
+	err = h.traqClient.AddStamp(ctx, messageID, h.acceptStampID, 1)
+	if err != nil {
+		logger.Println("failed to add stamp: ", err)
+		return
+	}
+	err = h.traqClient.AddStamp(ctx, messageID, h.rejectStampID, 1)
+	if err != nil {
+		logger.Println("failed to add stamp: ", err)
+		return
+	}
+}
+
+// decideRemoval は、メンバーから外す申請のメッセージに押されたスタンプから、承認するか却下するか判定する。
+// 承認されたら、GitHubのOrganizationから外す
+func (h *BotHandler) decideRemoval(ctx context.Context, messageID string, stamps []payload.MessageStamp) {
+	removal, err := h.rr.GetRemoval(ctx, messageID)
+	if errors.Is(err, repository.ErrRecordNotFound) {
+		return
+	}
+	if err != nil {
+		logger.Printf("failed to get removal: %v", err)
+		return
+	}
+	if removal.Status() != model.RemovalStatusPending {
+		return // 判定済み
+	}
+
+	// 申請者のスタンプは数えない
+	in := &policy.Input{Stamps: stamps}
+	if requester := removal.Requester(); requester != nil {
+		in.RequesterID = requester.ID()
+		in.Stamps = slices.DeleteFunc(slices.Clone(stamps), func(stamp payload.MessageStamp) bool {
+			return stamp.UserID == requester.ID()
+		})
+	}
+
+	result, err := h.removalPolicy.Evaluate(ctx, in)
+	if err != nil {
+		logger.Printf("failed to evaluate policy: %v", err)
+		return
+	}
+	if result.Decision != policy.Accept && result.Decision != policy.Reject {
+		return
+	}
+
+	status := model.RemovalStatusApproved
+	decisionResult := model.DecisionResultApproved
+	if result.Decision == policy.Reject {
+		status = model.RemovalStatusRejected
+		decisionResult = model.DecisionResultRejected
+	}
+	// 招待と同じように、判定に数えたスタンプも記録する。招待の判定とは種類で分ける。
+	// 同じメッセージへのイベントが同時に届いても、外すのは1度だけにする
+	decision := model.NewDecision(messageID, model.DecisionKindRemoval, decisionResult, decisionVotes(result.Votes), time.Now())
+	err = h.tx.RunInTx(ctx, func(ctx context.Context) error {
+		err := h.rr.UpdateRemovalStatus(ctx, messageID, status)
+		if err != nil {
+			return err
+		}
+		return h.dr.CreateDecision(ctx, decision)
+	})
+	if errors.Is(err, repository.ErrInvalidStatusTransition) {
+		return // 他のイベントで判定済み
+	}
+	if err != nil {
+		logger.Printf("failed to decide removal: %v", err)
+		return
+	}
+
+	err = h.traqClient.AddStamp(ctx, messageID, h.inactiveStampID, 1)
+	if err != nil {
+		logger.Printf("failed to add stamp: %v", err)
+	}
+
+	if status == model.RemovalStatusRejected {
+		progress, err := h.decidedProgressText(ctx, decision, "")
+		if err != nil {
+			logger.Printf("failed to make progress text: %v", err)
+		} else {
+			h.updateProgress(ctx, messageID, progress)
+		}
+		h.notifyRemovalRequester(ctx, removal, fmt.Sprintf("%s から外す申請は却下されました", h.githubClient.OrgName()))
+		return
+	}
+
+	h.removeMember(ctx, removal)
+}
+
+// removeMember は、承認された申請のメンバーをOrganizationから外し、結果を記録してbotのチャンネルに投稿する。
+// 申請メッセージの末尾にも、判定の結果と合わせて外した結果を表示する。
+// 外せなかった場合は外せなかった状態で残すので、 /retry で外し直せる。
+// 外し直す場合は、申請を外し直している途中の状態にしてから渡す
+func (h *BotHandler) removeMember(ctx context.Context, removal *model.Removal) {
+	err := h.githubClient.RemoveOrgMember(ctx, removal.GitHubID())
+	if err != nil {
+		logger.Printf("failed to remove org member: %v", err)
+
+		err := h.rr.UpdateRemovalStatus(ctx, removal.MessageID(), model.RemovalStatusRemoveFailed)
+		if err != nil {
+			logger.Printf("failed to update removal status: %v", err)
+		}
+		h.updateOutcome(ctx, model.DecisionKindRemoval, removal.MessageID(), fmt.Sprintf("%s から外せませんでした", h.githubClient.OrgName()))
+
+		_, err = h.traqClient.PostMessage(ctx, h.botChannelID,
+			fmt.Sprintf("%s を %s から外せませんでした。`/retry` で外し直せます", removal.GitHubID(), h.githubClient.OrgName()))
+		if err != nil {
+			logger.Printf("failed to post message: %v", err)
+		}
+		// 外し直して失敗した場合は、既に申請者に通知している
+		if removal.Status() != model.RemovalStatusRemoveFailed {
+			h.notifyRemovalRequester(ctx, removal, fmt.Sprintf("%s から外す申請は承認されましたが、外せませんでした。adminが対応するまでお待ちください", h.githubClient.OrgName()))
+		}
+		return
+	}
+
+	err = h.rr.UpdateRemovalStatus(ctx, removal.MessageID(), model.RemovalStatusRemoved)
+	if err != nil {
+		logger.Printf("failed to update removal status: %v", err)
+	}
+	h.updateOutcome(ctx, model.DecisionKindRemoval, removal.MessageID(), fmt.Sprintf("%s から外しました", h.githubClient.OrgName()))
+
+	_, err = h.traqClient.PostMessage(ctx, h.botChannelID,
+		fmt.Sprintf("%s を %s から外しました", removal.GitHubID(), h.githubClient.OrgName()))
+	if err != nil {
+		logger.Printf("failed to post message: %v", err)
+	}
+	h.notifyRemovalRequester(ctx, removal, fmt.Sprintf("申請が承認され、%s から外しました", h.githubClient.OrgName()))
+}
+
+// notifyRemovalRequester は、申請したチャンネルで申請者にメンションし、メンバーから外す申請の結果を通知する
+func (h *BotHandler) notifyRemovalRequester(ctx context.Context, removal *model.Removal, message string) {
+	if removal.OriginChannelID() == "" || removal.OriginChannelID() == h.botChannelID {
+		return
+	}
+
+	text := message + "\n"
+	if requester := removal.Requester(); requester != nil {
+		text = fmt.Sprintf("@%s %s", requester.Name(), text)
+	}
+	text += fmt.Sprintf("%s\nhttps://q.trap.jp/messages/%s", removal.GitHubID(), removal.OriginMessageID())
+
+	_, err := h.traqClient.PostMessage(ctx, removal.OriginChannelID(), text)
+	if err != nil {
+		logger.Printf("failed to post message: %v", err)
+	}
+}
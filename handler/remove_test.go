@@ -0,0 +1,357 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/traP-jp/members_bot/model"
+	"github.com/traP-jp/members_bot/repository"
+	repomock "github.com/traP-jp/members_bot/repository/mock"
+	"github.com/traP-jp/members_bot/service/mock"
+	"github.com/traPtitech/traq-ws-bot/payload"
+)
+
+func TestRemove(t *testing.T) {
+	t.Parallel()
+
+	botUserID := uuid.NewString()
+	requesterID := uuid.NewString()
+	originChannelID := uuid.NewString()
+	originMessageID := uuid.NewString()
+	removalMessageID := uuid.NewString()
+
+	type test struct {
+		plainText      string
+		isMember       bool
+		createdRemoval *model.Removal
+		postTexts      []string
+	}
+
+	testCases := map[string]test{
+		"理由付きで申請": {
+			plainText: "@BOT_traP-jp /remove ikura-hamu 卒業 したため",
+			isMember:  true,
+			createdRemoval: model.NewRemoval(removalMessageID, "ikura-hamu",
+				model.WithRemovalReason("卒業 したため"),
+				model.WithRemovalOrigin(model.NewUser(requesterID, "requester"), originChannelID, originMessageID)),
+			postTexts: []string{
+				"@GitHub_org_Admin\ntraP-jp から外す申請です\nhttps://github.com/ikura-hamu\n理由: 卒業 したため\nhttps://q.trap.jp/messages/" + originMessageID +
+					"\n※申請者のスタンプは数えません。「@GitHub_org_Admin のメンバーの承認スタンプが3個で承認、却下スタンプが1個で却下」で判定します",
+			},
+		},
+		"理由なしで申請": {
+			plainText: "@BOT_traP-jp /除名 ikura-hamu",
+			isMember:  true,
+			createdRemoval: model.NewRemoval(removalMessageID, "ikura-hamu",
+				model.WithRemovalOrigin(model.NewUser(requesterID, "requester"), originChannelID, originMessageID)),
+			postTexts: []string{
+				"@GitHub_org_Admin\ntraP-jp から外す申請です\nhttps://github.com/ikura-hamu\nhttps://q.trap.jp/messages/" + originMessageID +
+					"\n※申請者のスタンプは数えません。「@GitHub_org_Admin のメンバーの承認スタンプが3個で承認、却下スタンプが1個で却下」で判定します",
+			},
+		},
+		"メンバーではない": {
+			plainText: "@BOT_traP-jp /remove ikura-hamu",
+			postTexts: []string{"GitHubユーザー ikura-hamu は traP-jp のメンバーではありません"},
+		},
+		"引数が足りない": {
+			plainText: "@BOT_traP-jp /remove",
+			postTexts: []string{removeCommandMessage("引数が足りません")},
+		},
+	}
+
+	for name, test := range testCases {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			traqMock := &mock.TraqMock{
+				PostMessageFunc: func(context.Context, string, string) (string, error) {
+					return removalMessageID, nil
+				},
+				AddStampFunc: func(context.Context, string, string, int) error {
+					return nil
+				},
+			}
+			gitHubMock := &mock.GitHubMock{
+				OrgNameFunc: func() string {
+					return "traP-jp"
+				},
+				CheckUserIsMemberFunc: func(context.Context, string) (bool, error) {
+					return test.isMember, nil
+				},
+			}
+			removalRepoMock := &repomock.RemovalMock{
+				CreateRemovalFunc: func(context.Context, *model.Removal) error {
+					return nil
+				},
+			}
+
+			conf := &Config{
+				botChannelID:                "botChannelID",
+				acceptStampID:               "acceptStampID",
+				rejectStampID:               "rejectStampID",
+				rejectStampThreshold:        1,
+				removalAcceptStampThreshold: 3,
+				adminGroupID:                uuid.NewString(),
+				adminGroupName:              "GitHub_org_Admin",
+			}
+			bh := &BotHandler{
+				traqClient:    traqMock,
+				githubClient:  gitHubMock,
+				rr:            removalRepoMock,
+				removalPolicy: loadRemovalPolicy(conf, traqMock),
+				botUser:       model.NewUser(botUserID, "BOT_traP-jp"),
+				Config:        conf,
+			}
+
+			bh.remove(&payload.MessageCreated{
+				Message: payload.Message{
+					PlainText: test.plainText,
+					ID:        originMessageID,
+					ChannelID: originChannelID,
+					Embedded:  []payload.EmbeddedInfo{{Type: "user", Raw: "@BOT_traP-jp", ID: botUserID}},
+					User:      payload.User{ID: requesterID, Name: "requester"},
+				},
+			})
+
+			postMessageCalls := traqMock.PostMessageCalls()
+			require.Len(t, postMessageCalls, len(test.postTexts))
+			for i, text := range test.postTexts {
+				assert.Equal(t, text, postMessageCalls[i].Text)
+			}
+
+			createCalls := removalRepoMock.CreateRemovalCalls()
+			if test.createdRemoval == nil {
+				assert.Empty(t, createCalls)
+				assert.Empty(t, traqMock.AddStampCalls())
+				return
+			}
+
+			assert.Equal(t, "botChannelID", postMessageCalls[0].ChannelID)
+			require.Len(t, createCalls, 1)
+			assert.Equal(t, test.createdRemoval, createCalls[0].Removal)
+			assert.Len(t, traqMock.AddStampCalls(), 2)
+		})
+	}
+}
+
+func TestDecideRemoval(t *testing.T) {
+	t.Parallel()
+
+	botUserID := uuid.NewString()
+	requesterID := uuid.NewString()
+	adminIDs := []string{uuid.NewString(), uuid.NewString(), uuid.NewString()}
+	userNames := map[string]string{adminIDs[0]: "admin1", adminIDs[1]: "admin2", adminIDs[2]: "admin3"}
+	acceptStampID := uuid.NewString()
+	rejectStampID := uuid.NewString()
+	inactiveStampID := uuid.NewString()
+	originChannelID := uuid.NewString()
+	originMessageID := uuid.NewString()
+
+	acceptStamps := []payload.MessageStamp{
+		{StampID: acceptStampID, UserID: adminIDs[0], CreatedAt: time.Now()},
+		{StampID: acceptStampID, UserID: adminIDs[1], CreatedAt: time.Now()},
+		{StampID: acceptStampID, UserID: adminIDs[2], CreatedAt: time.Now()},
+	}
+
+	type test struct {
+		stamps        []payload.MessageStamp
+		status        model.RemovalStatus
+		updateErr     error
+		removeErr     error
+		statusUpdates []model.RemovalStatus
+		executeRemove bool
+		postTexts     []string
+		// 申請メッセージの末尾の判定の状況。%sには判定した時刻が入る
+		progress string
+	}
+
+	testCases := map[string]test{
+		"承認されて外す": {
+			stamps:        acceptStamps,
+			status:        model.RemovalStatusPending,
+			statusUpdates: []model.RemovalStatus{model.RemovalStatusApproved, model.RemovalStatusRemoved},
+			executeRemove: true,
+			postTexts: []string{
+				"ikura-hamu を traP-jp から外しました",
+				"@requester 申請が承認され、traP-jp から外しました\nikura-hamu\nhttps://q.trap.jp/messages/" + originMessageID,
+			},
+			progress: "✅ @admin1, @admin2, @admin3 が承認しました (%s) — traP-jp から外しました",
+		},
+		"招待の閾値では足りない": {
+			stamps: acceptStamps[:2],
+			status: model.RemovalStatusPending,
+		},
+		"申請者のスタンプは数えない": {
+			stamps: append(acceptStamps[:2:2], payload.MessageStamp{StampID: acceptStampID, UserID: requesterID, CreatedAt: time.Now()}),
+			status: model.RemovalStatusPending,
+		},
+		"却下": {
+			stamps:        []payload.MessageStamp{{StampID: rejectStampID, UserID: adminIDs[0], CreatedAt: time.Now()}},
+			status:        model.RemovalStatusPending,
+			statusUpdates: []model.RemovalStatus{model.RemovalStatusRejected},
+			postTexts: []string{
+				"@requester traP-jp から外す申請は却下されました\nikura-hamu\nhttps://q.trap.jp/messages/" + originMessageID,
+			},
+			progress: "❌ @admin1 が却下しました (%s)",
+		},
+		"外せなかった": {
+			stamps:        acceptStamps,
+			status:        model.RemovalStatusPending,
+			removeErr:     errors.New("remove error"),
+			statusUpdates: []model.RemovalStatus{model.RemovalStatusApproved, model.RemovalStatusRemoveFailed},
+			executeRemove: true,
+			postTexts: []string{
+				"ikura-hamu を traP-jp から外せませんでした。`/retry` で外し直せます",
+				"@requester traP-jp から外す申請は承認されましたが、外せませんでした。adminが対応するまでお待ちください\nikura-hamu\nhttps://q.trap.jp/messages/" + originMessageID,
+			},
+			progress: "✅ @admin1, @admin2, @admin3 が承認しました (%s) — traP-jp から外せませんでした",
+		},
+		"他のイベントで判定済み": {
+			stamps:        acceptStamps,
+			status:        model.RemovalStatusPending,
+			updateErr:     repository.ErrInvalidStatusTransition,
+			statusUpdates: []model.RemovalStatus{model.RemovalStatusApproved},
+		},
+		"判定済み": {
+			stamps: acceptStamps,
+			status: model.RemovalStatusRemoved,
+		},
+	}
+
+	for name, test := range testCases {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			messageID := uuid.NewString()
+
+			traqMock := &mock.TraqMock{
+				GetGroupMemberIDsFunc: func(context.Context, string) ([]string, error) {
+					return append(adminIDs, requesterID), nil
+				},
+				AddStampFunc: func(context.Context, string, string, int) error {
+					return nil
+				},
+				PostMessageFunc: func(context.Context, string, string) (string, error) {
+					return "", nil
+				},
+				GetUserFunc: func(_ context.Context, userID string) (*model.User, error) {
+					return model.NewUser(userID, userNames[userID]), nil
+				},
+				GetMessageFunc: func(context.Context, string) (string, error) {
+					return "申請", nil
+				},
+				EditMessageFunc: func(context.Context, string, string) error {
+					return nil
+				},
+			}
+			gitHubMock := &mock.GitHubMock{
+				OrgNameFunc: func() string {
+					return "traP-jp"
+				},
+				RemoveOrgMemberFunc: func(context.Context, string) error {
+					return test.removeErr
+				},
+			}
+			invRepoMock := &repomock.InvitationMock{
+				GetInvitationsFunc: func(context.Context, string) ([]*model.Invitation, error) {
+					return nil, repository.ErrRecordNotFound
+				},
+			}
+			removalRepoMock := &repomock.RemovalMock{
+				GetRemovalFunc: func(context.Context, string) (*model.Removal, error) {
+					return model.NewRemoval(messageID, "ikura-hamu",
+						model.WithRemovalStatus(test.status),
+						model.WithRemovalOrigin(model.NewUser(requesterID, "requester"), originChannelID, originMessageID)), nil
+				},
+				UpdateRemovalStatusFunc: func(_ context.Context, _ string, status model.RemovalStatus) error {
+					if status == model.RemovalStatusApproved || status == model.RemovalStatusRejected {
+						return test.updateErr
+					}
+					return nil
+				},
+			}
+
+			conf := &Config{
+				botChannelID:                "botChannelID",
+				acceptStampID:               acceptStampID,
+				rejectStampID:               rejectStampID,
+				inactiveStampID:             inactiveStampID,
+				acceptStampThreshold:        2,
+				rejectStampThreshold:        1,
+				removalAcceptStampThreshold: 3,
+				adminGroupID:                uuid.NewString(),
+				adminGroupName:              "GitHub_org_Admin",
+			}
+			decisionRepoMock := &repomock.DecisionMock{}
+			decisionRepoMock.CreateDecisionFunc = func(context.Context, *model.Decision) error {
+				return nil
+			}
+			decisionRepoMock.GetDecisionFunc = func(context.Context, model.DecisionKind, string) (*model.Decision, error) {
+				return decisionRepoMock.CreateDecisionCalls()[0].Decision, nil
+			}
+			bh := &BotHandler{
+				traqClient:    traqMock,
+				githubClient:  gitHubMock,
+				ir:            invRepoMock,
+				rr:            removalRepoMock,
+				dr:            decisionRepoMock,
+				tx:            newTransactionMock(),
+				removalPolicy: loadRemovalPolicy(conf, traqMock),
+				botUser:       model.NewUser(botUserID, "BOT_traP-jp"),
+				Config:        conf,
+			}
+
+			bh.AcceptOrReject(&payload.BotMessageStampsUpdated{MessageID: messageID, Stamps: test.stamps})
+
+			updateCalls := removalRepoMock.UpdateRemovalStatusCalls()
+			require.Len(t, updateCalls, len(test.statusUpdates))
+			for i, status := range test.statusUpdates {
+				assert.Equal(t, messageID, updateCalls[i].MessageID)
+				assert.Equal(t, status, updateCalls[i].Status)
+			}
+
+			// 判定と判定に数えたスタンプを記録する
+			decisionCalls := decisionRepoMock.CreateDecisionCalls()
+			if len(test.statusUpdates) > 0 && test.updateErr == nil {
+				require.Len(t, decisionCalls, 1)
+				assert.Equal(t, messageID, decisionCalls[0].Decision.MessageID())
+				// 招待の判定とは分けて記録する
+				assert.Equal(t, model.DecisionKindRemoval, decisionCalls[0].Decision.Kind())
+				assert.Equal(t, string(test.statusUpdates[0]), string(decisionCalls[0].Decision.Result()))
+				assert.NotEmpty(t, decisionCalls[0].Decision.Votes())
+			} else {
+				assert.Empty(t, decisionCalls)
+			}
+
+			if test.executeRemove {
+				require.Len(t, gitHubMock.RemoveOrgMemberCalls(), 1)
+				assert.Equal(t, "ikura-hamu", gitHubMock.RemoveOrgMemberCalls()[0].UserID)
+			} else {
+				assert.Empty(t, gitHubMock.RemoveOrgMemberCalls())
+			}
+
+			postMessageCalls := traqMock.PostMessageCalls()
+			require.Len(t, postMessageCalls, len(test.postTexts))
+			for i, text := range test.postTexts {
+				assert.Equal(t, text, postMessageCalls[i].Text)
+			}
+
+			// 判定の結果と外した結果を、申請メッセージの末尾に表示する
+			editCalls := traqMock.EditMessageCalls()
+			if test.progress == "" {
+				assert.Empty(t, editCalls)
+				return
+			}
+			require.Len(t, editCalls, 1)
+			assert.Equal(t, messageID, editCalls[0].MessageID)
+			decidedAt := decisionCalls[0].Decision.DecidedAt()
+			assert.Equal(t, "申請"+progressSeparator+fmt.Sprintf(test.progress, formatTime(decidedAt)), editCalls[0].Text)
+		})
+	}
+}
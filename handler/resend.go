@@ -0,0 +1,169 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/traP-jp/members_bot/model"
+	"github.com/traP-jp/members_bot/repository"
+	"github.com/traPtitech/traq-ws-bot/payload"
+)
+
+const resendCommandUsage = "`@BOT_traP-jp /(resend|再送) <GitHubID>`"
+
+func resendCommandMessage(message string) string {
+	return fmt.Sprintf("%s\n%s", message, resendCommandUsage)
+}
+
+// resend は、期限切れになった招待を送り直す。送信に失敗した招待は /retry で送り直す。
+// 既に承認された招待なので、もう一度承認を求めることはしない
+func (h *BotHandler) resend(p *payload.MessageCreated) {
+	ctx := context.Background()
+
+	mentionRawText, _ := checkIfBotMentioned(p, h.botUser.ID())
+	splitText := regexp.MustCompile(`\s+`).Split(strings.TrimSpace(strings.Replace(p.Message.PlainText, mentionRawText, "", 1)), -1)
+
+	if len(splitText) > 1 && slices.Contains([]string{"-h", "-help", "--help"}, splitText[1]) {
+		_, err := h.traqClient.PostMessage(ctx, p.Message.ChannelID,
+			resendCommandMessage("/resend は、期限切れになったGitHubの招待を送り直すためのコマンドです。送信に失敗した招待は /retry で送り直してください。"))
+		if err != nil {
+			logger.Println("failed to post message: ", err)
+		}
+		return
+	}
+
+	if len(splitText) != 2 {
+		_, err := h.traqClient.PostMessage(ctx, p.Message.ChannelID, resendCommandMessage("引数の数が合いません"))
+		if err != nil {
+			logger.Println("failed to post message: ", err)
+		}
+		return
+	}
+	gitHubID := splitText[1]
+
+	isAdmin, err := h.isAdmin(ctx, p.Message.User.ID)
+	if err != nil {
+		logger.Println("failed to check admin: ", err)
+		return
+	}
+	if !isAdmin {
+		_, err := h.traqClient.PostMessage(ctx, p.Message.ChannelID, "招待を送り直せるのはadminのみです")
+		if err != nil {
+			logger.Println("failed to post message: ", err)
+		}
+		return
+	}
+
+	invitations, err := h.ir.GetInvitationsByStatus(ctx, model.InvitationStatusExpired)
+	if err != nil {
+		logger.Println("failed to get invitations: ", err)
+		return
+	}
+
+	invitations, err = h.filterByGitHubUser(ctx, slices.DeleteFunc(invitations, func(inv *model.Invitation) bool {
+		return !canResend(inv)
+	}), gitHubID)
+	if err != nil {
+		logger.Println("failed to filter invitations: ", err)
+		return
+	}
+
+	// 同じGitHubユーザーの招待が複数ある場合は、最後に申請されたものを送り直す
+	var invitation *model.Invitation
+	if len(invitations) > 0 {
+		invitation = h.refreshGitHubLogin(ctx, invitations[len(invitations)-1])
+	}
+	if invitation == nil {
+		_, err := h.traqClient.PostMessage(ctx, p.Message.ChannelID,
+			fmt.Sprintf("GitHubユーザー %s の、送り直せる招待が見つかりません", gitHubID))
+		if err != nil {
+			logger.Println("failed to post message: ", err)
+		}
+		return
+	}
+
+	err = h.resendInvitation(ctx, invitation)
+	if errors.Is(err, repository.ErrInvalidStatusTransition) {
+		_, err := h.traqClient.PostMessage(ctx, p.Message.ChannelID, "この招待は既に送り直されています")
+		if err != nil {
+			logger.Println("failed to post message: ", err)
+		}
+		return
+	}
+	if err != nil {
+		logger.Println("failed to resend invitation: ", err)
+
+		_, err := h.traqClient.PostMessage(ctx, p.Message.ChannelID, "招待を送り直せませんでした")
+		if err != nil {
+			logger.Println("failed to post message: ", err)
+		}
+		return
+	}
+
+	_, err = h.traqClient.PostMessage(ctx, p.Message.ChannelID,
+		fmt.Sprintf("招待を送り直しました\n@%s (%s)", strings.TrimPrefix(invitation.TraqID(), "@"), invitation.GitHubID()))
+	if err != nil {
+		logger.Println("failed to post message: ", err)
+	}
+
+	h.notifyRequester(ctx, []*model.Invitation{invitation}, "GitHubから招待が送り直されました。メールを確認してください")
+}
+
+// canResend は、招待を送り直せるかを返す。
+// 承認されていない招待が期限切れになった場合は、送り直せない
+func canResend(inv *model.Invitation) bool {
+	return inv.Status() == model.InvitationStatusExpired && !inv.TransitionedAt(model.InvitationStatusSent).IsZero()
+}
+
+// resendInvitation は、期限切れになった招待を、GitHubに残っている承諾されていない招待を取り消してから送り直す。
+// 同時に送り直さないように、送信中の状態にできた場合だけ送り直す。
+// 他の処理が送り直している場合は repository.ErrInvalidStatusTransition を返す。
+// invのユーザー名は、現在のものにしておく
+func (h *BotHandler) resendInvitation(ctx context.Context, inv *model.Invitation) error {
+	err := h.ir.UpdateInvitationStatusByGitHubID(ctx, inv.MessageID(), inv.GitHubID(), model.InvitationStatusSending)
+	if err != nil {
+		return fmt.Errorf("failed to claim invitation: %w", err)
+	}
+
+	status, err := h.sendClaimedInvitation(ctx, inv)
+
+	updateErr := h.ir.UpdateInvitationStatusByGitHubID(ctx, inv.MessageID(), inv.GitHubID(), status)
+	if updateErr != nil {
+		updateErr = fmt.Errorf("failed to update invitation status: %w", updateErr)
+	}
+
+	return errors.Join(err, updateErr)
+}
+
+// sendClaimedInvitation は、送信中の状態にした招待を送り直し、次に遷移する状態を返す
+func (h *BotHandler) sendClaimedInvitation(ctx context.Context, inv *model.Invitation) (model.InvitationStatus, error) {
+	pendingInvitations, err := h.githubClient.ListPendingInvitations(ctx)
+	if err != nil {
+		return model.InvitationStatusExpired, fmt.Errorf("failed to list pending invitations: %w", err)
+	}
+
+	for _, pending := range pendingInvitations {
+		if !strings.EqualFold(pending.Login(), inv.GitHubID()) {
+			continue
+		}
+
+		err := h.githubClient.CancelInvitation(ctx, pending.ID())
+		if err != nil {
+			return model.InvitationStatusExpired, fmt.Errorf("failed to cancel invitation: %w", err)
+		}
+	}
+
+	result := h.githubClient.SendInvitations(ctx, []*model.Invitation{inv})[0]
+	if result.Status() == model.SendResultStatusAlreadyMember {
+		return model.InvitationStatusAccepted, errors.New("already a member")
+	}
+	if result.Failed() {
+		return model.InvitationStatusExpired, fmt.Errorf("failed to send invitation (%s): %w", result.Status(), result.Err())
+	}
+
+	return model.InvitationStatusSent, nil
+}
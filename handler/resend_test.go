@@ -0,0 +1,249 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/traP-jp/members_bot/model"
+	"github.com/traP-jp/members_bot/repository"
+	repomock "github.com/traP-jp/members_bot/repository/mock"
+	"github.com/traP-jp/members_bot/service/mock"
+	"github.com/traPtitech/traq-ws-bot/payload"
+)
+
+func TestResend(t *testing.T) {
+	t.Parallel()
+
+	botUserID := uuid.NewString()
+	adminID := uuid.NewString()
+	messageID := uuid.NewString()
+	originChannelID := uuid.NewString()
+	originMessageID := uuid.NewString()
+	origin := model.WithOrigin(model.NewUser(uuid.NewString(), "requester"), originChannelID, originMessageID)
+	sentAt := model.WithTransitionedAt(model.InvitationStatusSent, time.Now().Add(-8*24*time.Hour))
+	gitHubUserIDs := map[string]int64{"ikura-hamu": 1001}
+	gitHubLogins := map[int64]string{1001: "ikura-hamu"}
+
+	type test struct {
+		plainText          string
+		userID             string
+		invitations        []*model.Invitation
+		pendingInvitations []*model.GitHubInvitation
+		sendResultStatus   model.SendResultStatus
+		cancelledIDs       []int64
+		// 他の処理が送り直しているか
+		claimed bool
+		// 送り直した結果の状態
+		status model.InvitationStatus
+		// ユーザー名の変更を反映するか
+		renamed    bool
+		notifyText string
+		postText   string
+	}
+
+	testCases := map[string]test{
+		"期限切れの招待を送り直す": {
+			plainText: "@BOT_traP-jp /resend ikura-hamu",
+			userID:    adminID,
+			invitations: []*model.Invitation{
+				model.NewInvitation(messageID, "@ikura-hamu", "ikura-hamu", origin, model.WithStatus(model.InvitationStatusExpired), sentAt),
+			},
+			pendingInvitations: []*model.GitHubInvitation{
+				model.NewGitHubInvitation(1, "Ikura-Hamu", time.Now(), time.Time{}, ""),
+				model.NewGitHubInvitation(2, "H1rono", time.Now(), time.Time{}, ""),
+			},
+			cancelledIDs: []int64{1},
+			status:       model.InvitationStatusSent,
+			notifyText:   "@requester GitHubから招待が送り直されました。メールを確認してください\n@ikura-hamu (ikura-hamu)\nhttps://q.trap.jp/messages/" + originMessageID,
+			postText:     "招待を送り直しました\n@ikura-hamu (ikura-hamu)",
+		},
+		"送信に失敗した招待は送り直さない": {
+			plainText: "@BOT_traP-jp /再送 ikura-hamu",
+			userID:    adminID,
+			invitations: []*model.Invitation{
+				model.NewInvitation(messageID, "@ikura-hamu", "ikura-hamu", origin, model.WithStatus(model.InvitationStatusSendFailed)),
+			},
+			postText: "GitHubユーザー ikura-hamu の、送り直せる招待が見つかりません",
+		},
+		"他で送り直している招待は送らない": {
+			plainText: "@BOT_traP-jp /resend ikura-hamu",
+			userID:    adminID,
+			invitations: []*model.Invitation{
+				model.NewInvitation(messageID, "@ikura-hamu", "ikura-hamu", origin, model.WithStatus(model.InvitationStatusExpired), sentAt),
+			},
+			claimed:  true,
+			postText: "この招待は既に送り直されています",
+		},
+		"ユーザー名が変わった人の招待を、新しいユーザー名で送り直す": {
+			plainText: "@BOT_traP-jp /resend ikura-hamu",
+			userID:    adminID,
+			invitations: []*model.Invitation{
+				model.NewInvitation(messageID, "@ikura-hamu", "old-name", origin, model.WithStatus(model.InvitationStatusExpired), sentAt, model.WithGitHubUserID(1001)),
+			},
+			pendingInvitations: []*model.GitHubInvitation{
+				model.NewGitHubInvitation(1, "ikura-hamu", time.Now(), time.Time{}, ""),
+			},
+			cancelledIDs: []int64{1},
+			status:       model.InvitationStatusSent,
+			renamed:      true,
+			notifyText:   "@requester GitHubから招待が送り直されました。メールを確認してください\n@ikura-hamu (ikura-hamu)\nhttps://q.trap.jp/messages/" + originMessageID,
+			postText:     "招待を送り直しました\n@ikura-hamu (ikura-hamu)",
+		},
+		"承認されずに期限切れになった招待は送り直さない": {
+			plainText: "@BOT_traP-jp /resend ikura-hamu",
+			userID:    adminID,
+			invitations: []*model.Invitation{
+				model.NewInvitation(messageID, "@ikura-hamu", "ikura-hamu", origin, model.WithStatus(model.InvitationStatusExpired)),
+			},
+			postText: "GitHubユーザー ikura-hamu の、送り直せる招待が見つかりません",
+		},
+		"送信に失敗": {
+			plainText: "@BOT_traP-jp /resend ikura-hamu",
+			userID:    adminID,
+			invitations: []*model.Invitation{
+				model.NewInvitation(messageID, "@ikura-hamu", "ikura-hamu", origin, model.WithStatus(model.InvitationStatusExpired), sentAt),
+			},
+			sendResultStatus: model.SendResultStatusError,
+			status:           model.InvitationStatusExpired,
+			postText:         "招待を送り直せませんでした",
+		},
+		"adminではない": {
+			plainText: "@BOT_traP-jp /resend ikura-hamu",
+			userID:    uuid.NewString(),
+			postText:  "招待を送り直せるのはadminのみです",
+		},
+		"引数が足りない": {
+			plainText: "@BOT_traP-jp /resend",
+			userID:    adminID,
+			postText:  resendCommandMessage("引数の数が合いません"),
+		},
+	}
+
+	for name, test := range testCases {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			traqMock := &mock.TraqMock{
+				PostMessageFunc: func(context.Context, string, string) (string, error) {
+					return "", nil
+				},
+				GetGroupMemberIDsFunc: func(context.Context, string) ([]string, error) {
+					return []string{adminID}, nil
+				},
+			}
+			gitHubMock := &mock.GitHubMock{
+				ListPendingInvitationsFunc: func(ctx context.Context) ([]*model.GitHubInvitation, error) {
+					return test.pendingInvitations, nil
+				},
+				CancelInvitationFunc: func(ctx context.Context, invitationID int64) error {
+					return nil
+				},
+				GetUserIDFunc: func(ctx context.Context, userID string) (int64, error) {
+					return gitHubUserIDs[userID], nil
+				},
+				GetUserLoginFunc: func(ctx context.Context, gitHubUserID int64) (string, error) {
+					return gitHubLogins[gitHubUserID], nil
+				},
+				SendInvitationsFunc: func(ctx context.Context, invitations []*model.Invitation) []*model.SendResult {
+					if test.sendResultStatus != "" {
+						return []*model.SendResult{model.NewSendResult(invitations[0], test.sendResultStatus, errors.New("send invitations error"))}
+					}
+					return []*model.SendResult{model.NewSendResult(invitations[0], model.SendResultStatusSent, nil)}
+				},
+			}
+			invRepoMock := &repomock.InvitationMock{
+				GetInvitationsByStatusFunc: func(ctx context.Context, statuses ...model.InvitationStatus) ([]*model.Invitation, error) {
+					return test.invitations, nil
+				},
+				UpdateInvitationStatusByGitHubIDFunc: func(ctx context.Context, invitationID string, gitHubID string, status model.InvitationStatus) error {
+					if test.claimed && status == model.InvitationStatusSending {
+						return repository.ErrInvalidStatusTransition
+					}
+					return nil
+				},
+				UpdateInvitationGitHubIDFunc: func(ctx context.Context, gitHubUserID int64, gitHubID string) error {
+					return nil
+				},
+			}
+			alrMock := &repomock.AccountLinkMock{
+				UpdateAccountLinkGitHubLoginFunc: func(ctx context.Context, gitHubUserID int64, login string) error {
+					return nil
+				},
+			}
+
+			bh := &BotHandler{
+				traqClient:   traqMock,
+				githubClient: gitHubMock,
+				ir:           invRepoMock,
+				alr:          alrMock,
+				botUser:      model.NewUser(botUserID, "BOT_traP-jp"),
+				Config: &Config{
+					botChannelID: "botChannelID",
+					adminGroupID: uuid.NewString(),
+				},
+			}
+
+			payload := &payload.MessageCreated{
+				Message: payload.Message{
+					PlainText: test.plainText,
+					ID:        uuid.NewString(),
+					ChannelID: "botChannelID",
+					Embedded:  []payload.EmbeddedInfo{{Type: "user", Raw: "@BOT_traP-jp", ID: botUserID}},
+					User:      payload.User{ID: test.userID},
+					CreatedAt: time.Now(),
+					UpdatedAt: time.Now(),
+				},
+				Base: payload.Base{EventTime: time.Now()},
+			}
+			bh.resend(payload)
+
+			cancelCalls := gitHubMock.CancelInvitationCalls()
+			require.Len(t, cancelCalls, len(test.cancelledIDs))
+			for i, id := range test.cancelledIDs {
+				assert.Equal(t, id, cancelCalls[i].InvitationID)
+			}
+
+			renameCalls := invRepoMock.UpdateInvitationGitHubIDCalls()
+			if test.renamed {
+				require.Len(t, renameCalls, 1)
+				assert.Equal(t, int64(1001), renameCalls[0].GitHubUserID)
+				assert.Equal(t, "ikura-hamu", renameCalls[0].GitHubID)
+			} else {
+				assert.Empty(t, renameCalls)
+			}
+
+			// 送信中の状態にしてから送り、結果の状態にする
+			updateCalls := invRepoMock.UpdateInvitationStatusByGitHubIDCalls()
+			switch {
+			case test.claimed:
+				require.Len(t, updateCalls, 1)
+				assert.Empty(t, gitHubMock.SendInvitationsCalls())
+			case test.status != "":
+				require.Len(t, updateCalls, 2)
+				assert.Equal(t, messageID, updateCalls[1].InvitationID)
+				assert.Equal(t, test.status, updateCalls[1].Status)
+			default:
+				assert.Empty(t, updateCalls)
+			}
+			if len(updateCalls) > 0 {
+				assert.Equal(t, model.InvitationStatusSending, updateCalls[0].Status)
+			}
+
+			postMessageCalls := traqMock.PostMessageCalls()
+			if test.notifyText != "" {
+				require.Len(t, postMessageCalls, 2)
+				assert.Equal(t, originChannelID, postMessageCalls[1].ChannelID)
+				assert.Equal(t, test.notifyText, postMessageCalls[1].Text)
+			} else {
+				require.Len(t, postMessageCalls, 1)
+			}
+			assert.Equal(t, "botChannelID", postMessageCalls[0].ChannelID)
+			assert.Equal(t, test.postText, postMessageCalls[0].Text)
+		})
+	}
+}
@@ -0,0 +1,182 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/traP-jp/members_bot/model"
+	"github.com/traP-jp/members_bot/repository"
+	"github.com/traPtitech/traq-ws-bot/payload"
+)
+
+const retryCommandUsage = "`@BOT_traP-jp /(retry|再試行) [<申請メッセージのURL>|<GitHubID>]`"
+
+// 送信中や、承認されたまま送信されていない状態がこれより長く続いている招待は、送信の途中で処理が止まったとみなす
+const staleSendingTimeout = 10 * time.Minute
+
+func retryCommandMessage(message string) string {
+	return fmt.Sprintf("%s\n%s", message, retryCommandUsage)
+}
+
+// retry は、承認された後に送信に失敗した招待を、もう一度送信する。
+// メンバーから外す申請が承認された後に外せなかったメンバーも、もう一度外す。
+// 承認された後や送信中のまま処理が止まった招待も、送信に失敗した招待として送り直す。
+// 引数を省略した場合は、送信に失敗した全ての招待と、外せなかった全てのメンバーを対象にする
+func (h *BotHandler) retry(p *payload.MessageCreated) {
+	ctx := context.Background()
+
+	mentionRawText, _ := checkIfBotMentioned(p, h.botUser.ID())
+	splitText := regexp.MustCompile(`\s+`).Split(strings.TrimSpace(strings.Replace(p.Message.PlainText, mentionRawText, "", 1)), -1)
+
+	if len(splitText) > 1 && slices.Contains([]string{"-h", "-help", "--help"}, splitText[1]) {
+		_, err := h.traqClient.PostMessage(ctx, p.Message.ChannelID,
+			retryCommandMessage("/retry は、承認された後に送信に失敗した招待や、外せなかったメンバーを、もう一度処理するためのコマンドです。"))
+		if err != nil {
+			logger.Println("failed to post message: ", err)
+		}
+		return
+	}
+
+	if len(splitText) > 2 {
+		_, err := h.traqClient.PostMessage(ctx, p.Message.ChannelID, retryCommandMessage("引数が多すぎます"))
+		if err != nil {
+			logger.Println("failed to post message: ", err)
+		}
+		return
+	}
+
+	isAdmin, err := h.isAdmin(ctx, p.Message.User.ID)
+	if err != nil {
+		logger.Println("failed to check admin: ", err)
+		return
+	}
+	if !isAdmin {
+		_, err := h.traqClient.PostMessage(ctx, p.Message.ChannelID, "招待を送り直せるのはadminのみです")
+		if err != nil {
+			logger.Println("failed to post message: ", err)
+		}
+		return
+	}
+
+	_, err = h.ir.ReleaseStaleInvitations(ctx, time.Now().Add(-staleSendingTimeout))
+	if err != nil {
+		logger.Println("failed to release stale invitations: ", err)
+		return
+	}
+
+	invitations, err := h.ir.GetInvitationsByStatus(ctx, model.InvitationStatusSendFailed)
+	if err != nil {
+		logger.Println("failed to get invitations: ", err)
+		return
+	}
+	removals, err := h.rr.GetRemovalsByStatus(ctx, model.RemovalStatusRemoveFailed)
+	if err != nil {
+		logger.Println("failed to get removals: ", err)
+		return
+	}
+
+	if len(splitText) == 2 {
+		target := splitText[1]
+		if matches := messageURLPattern.FindStringSubmatch(target); matches != nil {
+			invitations = slices.DeleteFunc(invitations, func(inv *model.Invitation) bool {
+				return inv.MessageID() != strings.ToLower(matches[1])
+			})
+			removals = slices.DeleteFunc(removals, func(removal *model.Removal) bool {
+				return removal.MessageID() != strings.ToLower(matches[1])
+			})
+		} else {
+			invitations, err = h.filterByGitHubUser(ctx, invitations, target)
+			if err != nil {
+				logger.Println("failed to filter invitations: ", err)
+				return
+			}
+			removals = slices.DeleteFunc(removals, func(removal *model.Removal) bool {
+				return !strings.EqualFold(removal.GitHubID(), target)
+			})
+		}
+	}
+
+	if len(invitations) == 0 && len(removals) == 0 {
+		_, err := h.traqClient.PostMessage(ctx, p.Message.ChannelID, "送信に失敗した招待や、外せなかったメンバーはいません")
+		if err != nil {
+			logger.Println("failed to post message: ", err)
+		}
+		return
+	}
+
+	// 同時に実行されても同じ招待を2回送らないように、送信中の状態にできた招待だけを送る
+	invitations = slices.DeleteFunc(invitations, func(inv *model.Invitation) bool {
+		err := h.ir.UpdateInvitationStatusByGitHubID(ctx, inv.MessageID(), inv.GitHubID(), model.InvitationStatusSending)
+		if errors.Is(err, repository.ErrInvalidStatusTransition) {
+			return true // 他の処理が送っている
+		}
+		if err != nil {
+			logger.Println("failed to claim invitation: ", err)
+			return true
+		}
+		return false
+	})
+	// 外すメンバーも同じように、外し直している途中の状態にできたものだけを外す
+	removals = slices.DeleteFunc(removals, func(removal *model.Removal) bool {
+		err := h.rr.UpdateRemovalStatus(ctx, removal.MessageID(), model.RemovalStatusRemoving)
+		if errors.Is(err, repository.ErrInvalidStatusTransition) {
+			return true // 他の処理が外している
+		}
+		if err != nil {
+			logger.Println("failed to claim removal: ", err)
+			return true
+		}
+		return false
+	})
+	if len(invitations) == 0 && len(removals) == 0 {
+		_, err := h.traqClient.PostMessage(ctx, p.Message.ChannelID, "送信に失敗した招待や外せなかったメンバーは、既に他で再試行されています")
+		if err != nil {
+			logger.Println("failed to post message: ", err)
+		}
+		return
+	}
+
+	message := ""
+	if len(invitations) > 0 {
+		// 申請者に申請ごとに通知し、申請メッセージの末尾の結果を書き換えるため、申請のメッセージごとに送る
+		for _, group := range groupByMessageID(invitations) {
+			h.updateOutcome(ctx, model.DecisionKindInvitation, group[0].MessageID(), h.sendOutcomeText(h.sendInvitations(ctx, group)))
+		}
+		message += fmt.Sprintf("%d人の招待を送り直しました。", len(invitations))
+	}
+	for _, removal := range removals {
+		h.removeMember(ctx, removal)
+	}
+	if len(removals) > 0 {
+		message += fmt.Sprintf("%d人を %s から外し直しました。", len(removals), h.githubClient.OrgName())
+	}
+
+	if p.Message.ChannelID != h.botChannelID {
+		_, err := h.traqClient.PostMessage(ctx, p.Message.ChannelID, message+"結果はbotのチャンネルに投稿されます")
+		if err != nil {
+			logger.Println("failed to post message: ", err)
+		}
+	}
+}
+
+// groupByMessageID は、招待を申請のメッセージごとに分ける。最初に現れた順に並べる
+func groupByMessageID(invitations []*model.Invitation) [][]*model.Invitation {
+	groups := make([][]*model.Invitation, 0)
+	indexes := make(map[string]int)
+	for _, inv := range invitations {
+		i, ok := indexes[inv.MessageID()]
+		if !ok {
+			i = len(groups)
+			indexes[inv.MessageID()] = i
+			groups = append(groups, nil)
+		}
+		groups[i] = append(groups[i], inv)
+	}
+
+	return groups
+}
@@ -0,0 +1,380 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/traP-jp/members_bot/model"
+	"github.com/traP-jp/members_bot/repository"
+	repomock "github.com/traP-jp/members_bot/repository/mock"
+	"github.com/traP-jp/members_bot/service/mock"
+	"github.com/traPtitech/traq-ws-bot/payload"
+)
+
+func TestRetry(t *testing.T) {
+	t.Parallel()
+
+	botUserID := uuid.NewString()
+	adminID := uuid.NewString()
+	messageID1 := uuid.NewString()
+	messageID2 := uuid.NewString()
+	originChannelID := uuid.NewString()
+	originMessageID := uuid.NewString()
+	origin := model.WithOrigin(model.NewUser(uuid.NewString(), "requester"), originChannelID, originMessageID)
+	// 別の人が別のチャンネルで申請した招待
+	originChannelID2 := uuid.NewString()
+	originMessageID2 := uuid.NewString()
+	origin2 := model.WithOrigin(model.NewUser(uuid.NewString(), "requester2"), originChannelID2, originMessageID2)
+	sendFailed := model.WithStatus(model.InvitationStatusSendFailed)
+
+	invitations := []*model.Invitation{
+		model.NewInvitation(messageID1, "@ikura-hamu", "ikura-hamu", origin, sendFailed),
+		model.NewInvitation(messageID2, "@H1rono", "H1rono", origin2, sendFailed, model.WithGitHubUserID(1002)),
+	}
+	gitHubUserIDs := map[string]int64{"H1rono-new": 1002}
+	removalMessageID := uuid.NewString()
+	removalOriginMessageID := uuid.NewString()
+	removals := []*model.Removal{
+		model.NewRemoval(removalMessageID, "SSlime",
+			model.WithRemovalStatus(model.RemovalStatusRemoveFailed),
+			model.WithRemovalOrigin(model.NewUser(uuid.NewString(), "requester"), originChannelID, removalOriginMessageID)),
+	}
+	removedNotifyText := "@requester 申請が承認され、traP-jp から外しました\nSSlime\nhttps://q.trap.jp/messages/" + removalOriginMessageID
+
+	// 申請メッセージの末尾には、判定の結果と送信できなかったことが表示されている
+	decidedAt := time.Now().Add(-time.Hour)
+	decided := fmt.Sprintf("✅ @admin が承認しました (%s)", formatTime(decidedAt))
+	excludedNote := "\n申請者と招待されるユーザー本人のスタンプは数えていません (@requester)"
+	messages := map[string]string{
+		messageID1:       "申請1" + progressSeparator + decided + " — 招待を送信できませんでした" + excludedNote,
+		messageID2:       "申請2" + progressSeparator + decided + " — 招待を送信できませんでした",
+		removalMessageID: "申請3" + progressSeparator + decided + " — traP-jp から外せませんでした",
+	}
+
+	type test struct {
+		plainText        string
+		userID           string
+		channelID        string
+		sendResultStatus model.SendResultStatus
+		removeErr        error
+		// 他の処理が送り直しているか
+		claimed bool
+		// 送り直す招待と、送り直した結果の状態
+		resentMessageIDs []string
+		status           model.InvitationStatus
+		// メンバーから外し直したか、外し直した結果の状態
+		removed       bool
+		removalStatus model.RemovalStatus
+		postTexts     []string
+		// 投稿されるチャンネル。省略した場合は確かめない
+		postChannelIDs []string
+		// 書き換えた申請メッセージ
+		editedMessageIDs []string
+		editTexts        []string
+	}
+
+	testCases := map[string]test{
+		"全て送り直す": {
+			plainText:        "@BOT_traP-jp /retry",
+			userID:           adminID,
+			channelID:        "botChannelID",
+			resentMessageIDs: []string{messageID1, messageID2},
+			status:           model.InvitationStatusSent,
+			removed:          true,
+			removalStatus:    model.RemovalStatusRemoved,
+			postTexts: []string{
+				"招待を送信しました。確認してください\n@ikura-hamu (ikura-hamu)\n",
+				"@requester 招待が承認され、GitHubから招待が送信されました。メールを確認してください\n@ikura-hamu (ikura-hamu)\nhttps://q.trap.jp/messages/" + originMessageID,
+				"招待を送信しました。確認してください\n@H1rono (H1rono)\n",
+				"@requester2 招待が承認され、GitHubから招待が送信されました。メールを確認してください\n@H1rono (H1rono)\nhttps://q.trap.jp/messages/" + originMessageID2,
+				"SSlime を traP-jp から外しました",
+				removedNotifyText,
+			},
+			// 申請者には、申請したチャンネルで申請ごとに通知する
+			postChannelIDs:   []string{"botChannelID", originChannelID, "botChannelID", originChannelID2, "botChannelID", originChannelID},
+			editedMessageIDs: []string{messageID1, messageID2, removalMessageID},
+			editTexts: []string{
+				"申請1" + progressSeparator + decided + " — 招待を送信しました" + excludedNote,
+				"申請2" + progressSeparator + decided + " — 招待を送信しました",
+				"申請3" + progressSeparator + decided + " — traP-jp から外しました",
+			},
+		},
+		"外せなかったメンバーを外し直す": {
+			plainText:     "@BOT_traP-jp /retry sslime",
+			userID:        adminID,
+			channelID:     "otherChannelID",
+			removed:       true,
+			removalStatus: model.RemovalStatusRemoved,
+			postTexts: []string{
+				"SSlime を traP-jp から外しました",
+				removedNotifyText,
+				"1人を traP-jp から外し直しました。結果はbotのチャンネルに投稿されます",
+			},
+			editedMessageIDs: []string{removalMessageID},
+			editTexts: []string{
+				"申請3" + progressSeparator + decided + " — traP-jp から外しました",
+			},
+		},
+		// 外せなかったことは既に申請者に通知している
+		"もう一度外せなかった場合は申請者に通知しない": {
+			plainText:     "@BOT_traP-jp /retry https://q.trap.jp/messages/" + removalMessageID,
+			userID:        adminID,
+			channelID:     "botChannelID",
+			removeErr:     errors.New("remove error"),
+			removed:       true,
+			removalStatus: model.RemovalStatusRemoveFailed,
+			postTexts: []string{
+				"SSlime を traP-jp から外せませんでした。`/retry` で外し直せます",
+			},
+		},
+		"GitHubIDで絞り込む": {
+			plainText:        "@BOT_traP-jp /再試行 IKURA-HAMU",
+			userID:           adminID,
+			channelID:        "botChannelID",
+			resentMessageIDs: []string{messageID1},
+			status:           model.InvitationStatusSent,
+			postTexts: []string{
+				"招待を送信しました。確認してください\n@ikura-hamu (ikura-hamu)\n",
+				"@requester 招待が承認され、GitHubから招待が送信されました。メールを確認してください\n@ikura-hamu (ikura-hamu)\nhttps://q.trap.jp/messages/" + originMessageID,
+			},
+			editedMessageIDs: []string{messageID1},
+			editTexts: []string{
+				"申請1" + progressSeparator + decided + " — 招待を送信しました" + excludedNote,
+			},
+		},
+		"メッセージのURLで絞り込み、別のチャンネルから送り直す": {
+			plainText:        "@BOT_traP-jp /retry https://q.trap.jp/messages/" + messageID2,
+			userID:           adminID,
+			channelID:        "otherChannelID",
+			resentMessageIDs: []string{messageID2},
+			status:           model.InvitationStatusSent,
+			postTexts: []string{
+				"招待を送信しました。確認してください\n@H1rono (H1rono)\n",
+				"@requester2 招待が承認され、GitHubから招待が送信されました。メールを確認してください\n@H1rono (H1rono)\nhttps://q.trap.jp/messages/" + originMessageID2,
+				"1人の招待を送り直しました。結果はbotのチャンネルに投稿されます",
+			},
+			editedMessageIDs: []string{messageID2},
+			editTexts: []string{
+				"申請2" + progressSeparator + decided + " — 招待を送信しました",
+			},
+		},
+		"もう一度失敗した場合は申請者に通知しない": {
+			plainText:        "@BOT_traP-jp /retry ikura-hamu",
+			userID:           adminID,
+			channelID:        "botChannelID",
+			sendResultStatus: model.SendResultStatusError,
+			resentMessageIDs: []string{messageID1},
+			status:           model.InvitationStatusSendFailed,
+			postTexts: []string{
+				"招待を送信できませんでした。`/retry` で送り直せます\n@ikura-hamu (ikura-hamu) エラーが発生しました\n",
+			},
+		},
+		"ユーザー名が変わっていてもユーザーIDで絞り込む": {
+			plainText:        "@BOT_traP-jp /retry H1rono-new",
+			userID:           adminID,
+			channelID:        "botChannelID",
+			resentMessageIDs: []string{messageID2},
+			status:           model.InvitationStatusSent,
+			postTexts: []string{
+				"招待を送信しました。確認してください\n@H1rono (H1rono)\n",
+				"@requester2 招待が承認され、GitHubから招待が送信されました。メールを確認してください\n@H1rono (H1rono)\nhttps://q.trap.jp/messages/" + originMessageID2,
+			},
+			editedMessageIDs: []string{messageID2},
+			editTexts: []string{
+				"申請2" + progressSeparator + decided + " — 招待を送信しました",
+			},
+		},
+		"他で送り直している招待は送らない": {
+			plainText: "@BOT_traP-jp /retry ikura-hamu",
+			userID:    adminID,
+			channelID: "botChannelID",
+			claimed:   true,
+			postTexts: []string{"送信に失敗した招待や外せなかったメンバーは、既に他で再試行されています"},
+		},
+		"該当する招待がない": {
+			plainText: "@BOT_traP-jp /retry pikachu",
+			userID:    adminID,
+			channelID: "botChannelID",
+			postTexts: []string{"送信に失敗した招待や、外せなかったメンバーはいません"},
+		},
+		"adminではない": {
+			plainText: "@BOT_traP-jp /retry",
+			userID:    uuid.NewString(),
+			channelID: "botChannelID",
+			postTexts: []string{"招待を送り直せるのはadminのみです"},
+		},
+		"引数が多すぎる": {
+			plainText: "@BOT_traP-jp /retry ikura-hamu H1rono",
+			userID:    adminID,
+			channelID: "botChannelID",
+			postTexts: []string{retryCommandMessage("引数が多すぎます")},
+		},
+	}
+
+	for name, test := range testCases {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			traqMock := &mock.TraqMock{
+				PostMessageFunc: func(context.Context, string, string) (string, error) {
+					return "", nil
+				},
+				GetGroupMemberIDsFunc: func(context.Context, string) ([]string, error) {
+					return []string{adminID}, nil
+				},
+				GetUserFunc: func(_ context.Context, userID string) (*model.User, error) {
+					return model.NewUser(userID, "admin"), nil
+				},
+				GetMessageFunc: func(_ context.Context, messageID string) (string, error) {
+					return messages[messageID], nil
+				},
+				EditMessageFunc: func(context.Context, string, string) error {
+					return nil
+				},
+			}
+			gitHubMock := &mock.GitHubMock{
+				OrgNameFunc: func() string {
+					return "traP-jp"
+				},
+				GetUserIDFunc: func(ctx context.Context, userID string) (int64, error) {
+					return gitHubUserIDs[userID], nil
+				},
+				SendInvitationsFunc: func(ctx context.Context, invitations []*model.Invitation) []*model.SendResult {
+					results := make([]*model.SendResult, 0, len(invitations))
+					for _, inv := range invitations {
+						if test.sendResultStatus != "" {
+							results = append(results, model.NewSendResult(inv, test.sendResultStatus, errors.New("send invitations error")))
+						} else {
+							results = append(results, model.NewSendResult(inv, model.SendResultStatusSent, nil))
+						}
+					}
+					return results
+				},
+				RemoveOrgMemberFunc: func(context.Context, string) error {
+					return test.removeErr
+				},
+			}
+			invRepoMock := &repomock.InvitationMock{
+				ReleaseStaleInvitationsFunc: func(context.Context, time.Time) ([]*model.Invitation, error) {
+					return nil, nil
+				},
+				GetInvitationsByStatusFunc: func(ctx context.Context, statuses ...model.InvitationStatus) ([]*model.Invitation, error) {
+					return append([]*model.Invitation{}, invitations...), nil
+				},
+				UpdateInvitationStatusByGitHubIDFunc: func(ctx context.Context, invitationID string, gitHubID string, status model.InvitationStatus) error {
+					if test.claimed && status == model.InvitationStatusSending {
+						return repository.ErrInvalidStatusTransition
+					}
+					return nil
+				},
+			}
+
+			removalRepoMock := &repomock.RemovalMock{
+				GetRemovalsByStatusFunc: func(context.Context, model.RemovalStatus) ([]*model.Removal, error) {
+					return append([]*model.Removal{}, removals...), nil
+				},
+				UpdateRemovalStatusFunc: func(_ context.Context, _ string, status model.RemovalStatus) error {
+					if test.claimed && status == model.RemovalStatusRemoving {
+						return repository.ErrInvalidStatusTransition
+					}
+					return nil
+				},
+			}
+
+			decisionRepoMock := &repomock.DecisionMock{
+				GetDecisionFunc: func(_ context.Context, kind model.DecisionKind, messageID string) (*model.Decision, error) {
+					votes := []*model.Vote{model.NewVote(adminID, "acceptStampID", decidedAt)}
+					return model.NewDecision(messageID, kind, model.DecisionResultApproved, votes, decidedAt), nil
+				},
+			}
+
+			bh := &BotHandler{
+				traqClient:   traqMock,
+				githubClient: gitHubMock,
+				ir:           invRepoMock,
+				rr:           removalRepoMock,
+				dr:           decisionRepoMock,
+				botUser:      model.NewUser(botUserID, "BOT_traP-jp"),
+				Config: &Config{
+					botChannelID:  "botChannelID",
+					adminGroupID:  uuid.NewString(),
+					acceptStampID: "acceptStampID",
+					rejectStampID: "rejectStampID",
+				},
+			}
+
+			payload := &payload.MessageCreated{
+				Message: payload.Message{
+					PlainText: test.plainText,
+					ID:        uuid.NewString(),
+					ChannelID: test.channelID,
+					Embedded:  []payload.EmbeddedInfo{{Type: "user", Raw: "@BOT_traP-jp", ID: botUserID}},
+					User:      payload.User{ID: test.userID},
+					CreatedAt: time.Now(),
+					UpdatedAt: time.Now(),
+				},
+				Base: payload.Base{EventTime: time.Now()},
+			}
+			bh.retry(payload)
+
+			// 送信中のまま止まった招待を、送信に失敗した招待に戻してから探す
+			if test.userID == adminID && len(invRepoMock.GetInvitationsByStatusCalls()) > 0 {
+				releaseCalls := invRepoMock.ReleaseStaleInvitationsCalls()
+				require.Len(t, releaseCalls, 1)
+				assert.WithinDuration(t, time.Now().Add(-staleSendingTimeout), releaseCalls[0].Before, time.Second)
+			}
+
+			// 送信中の状態にしてから送り、結果の状態にする
+			updateCalls := invRepoMock.UpdateInvitationStatusByGitHubIDCalls()
+			if test.claimed {
+				require.Len(t, updateCalls, 1)
+				assert.Equal(t, model.InvitationStatusSending, updateCalls[0].Status)
+				assert.Empty(t, gitHubMock.SendInvitationsCalls())
+			} else {
+				require.Len(t, updateCalls, 2*len(test.resentMessageIDs))
+				for i, id := range test.resentMessageIDs {
+					assert.Equal(t, id, updateCalls[i].InvitationID)
+					assert.Equal(t, model.InvitationStatusSending, updateCalls[i].Status)
+					assert.Equal(t, id, updateCalls[len(test.resentMessageIDs)+i].InvitationID)
+					assert.Equal(t, test.status, updateCalls[len(test.resentMessageIDs)+i].Status)
+				}
+			}
+
+			// 外すメンバーも、外し直している途中の状態にしてから外す
+			removalCalls := removalRepoMock.UpdateRemovalStatusCalls()
+			if test.removed {
+				require.Len(t, removalCalls, 2)
+				assert.Equal(t, removalMessageID, removalCalls[0].MessageID)
+				assert.Equal(t, model.RemovalStatusRemoving, removalCalls[0].Status)
+				assert.Equal(t, test.removalStatus, removalCalls[1].Status)
+				assert.Len(t, gitHubMock.RemoveOrgMemberCalls(), 1)
+			} else {
+				assert.Empty(t, removalCalls)
+				assert.Empty(t, gitHubMock.RemoveOrgMemberCalls())
+			}
+
+			postMessageCalls := traqMock.PostMessageCalls()
+			require.Len(t, postMessageCalls, len(test.postTexts))
+			for i, text := range test.postTexts {
+				assert.Equal(t, text, postMessageCalls[i].Text)
+			}
+			for i, channelID := range test.postChannelIDs {
+				assert.Equal(t, channelID, postMessageCalls[i].ChannelID)
+			}
+
+			// 申請メッセージの末尾の結果を、送り直した結果に書き換える
+			editCalls := traqMock.EditMessageCalls()
+			require.Len(t, editCalls, len(test.editTexts))
+			for i, text := range test.editTexts {
+				assert.Equal(t, test.editedMessageIDs[i], editCalls[i].MessageID)
+				assert.Equal(t, text, editCalls[i].Text)
+			}
+		})
+	}
+}
@@ -0,0 +1,91 @@
+package handler
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/traP-jp/members_bot/model"
+)
+
+// 送信に失敗した理由
+var sendFailureLabels = map[model.SendResultStatus]string{
+	model.SendResultStatusUserNotFound: "GitHubユーザーが見つかりません",
+	model.SendResultStatusRateLimited:  "GitHub APIの利用制限に達しました",
+	model.SendResultStatusError:        "エラーが発生しました",
+}
+
+// sendInvitations は、承認された招待を送信し、1人ずつ結果を記録する。
+// 送信できた人と失敗した人をbotのチャンネルに投稿し、申請者にも通知する。
+// 失敗した招待は送信失敗の状態で残すので、後で送り直せる。
+// 送り直す場合は、招待を送信中の状態にしてから渡す
+func (h *BotHandler) sendInvitations(ctx context.Context, invitations []*model.Invitation) []*model.SendResult {
+	results := h.githubClient.SendInvitations(ctx, invitations)
+
+	var (
+		sentMessage, memberMessage, failedMessage string
+		sent, members, failed                     []*model.Invitation
+	)
+	for _, result := range results {
+		inv := result.Invitation()
+		line := fmt.Sprintf("@%s (%s)", strings.TrimPrefix(inv.TraqID(), "@"), inv.GitHubID())
+
+		var status model.InvitationStatus
+		switch result.Status() {
+		case model.SendResultStatusSent, model.SendResultStatusAlreadyInvited:
+			status = model.InvitationStatusSent
+			if result.Status() == model.SendResultStatusAlreadyInvited {
+				line += " 既に招待されています"
+			}
+			sentMessage += line + "\n"
+			sent = append(sent, inv)
+		case model.SendResultStatusAlreadyMember:
+			status = model.InvitationStatusAccepted
+			memberMessage += line + "\n"
+			members = append(members, inv)
+		default:
+			logger.Printf("failed to send invitation to %s: %v", inv.GitHubID(), result.Err())
+			status = model.InvitationStatusSendFailed
+			failedMessage += fmt.Sprintf("%s %s\n", line, sendFailureLabels[result.Status()])
+			// 送り直して失敗した場合は、既に申請者に通知している
+			if inv.Status() != model.InvitationStatusSendFailed {
+				failed = append(failed, inv)
+			}
+		}
+
+		err := h.ir.UpdateInvitationStatusByGitHubID(ctx, inv.MessageID(), inv.GitHubID(), status)
+		if err != nil {
+			logger.Printf("failed to update invitation status: %v", err)
+		}
+	}
+
+	message := ""
+	if sentMessage != "" {
+		message += "招待を送信しました。確認してください\n" + sentMessage
+	}
+	if memberMessage != "" {
+		message += fmt.Sprintf("既に %s のメンバーでした\n", h.githubClient.OrgName()) + memberMessage
+	}
+	if failedMessage != "" {
+		message += "招待を送信できませんでした。`/retry` で送り直せます\n" + failedMessage
+	}
+
+	if message != "" {
+		_, err := h.traqClient.PostMessage(ctx, h.botChannelID, message)
+		if err != nil {
+			logger.Printf("failed to post message: %v", err)
+		}
+	}
+
+	if len(sent) > 0 && sent[0].OriginChannelID() != h.botChannelID {
+		h.notifyRequester(ctx, sent, "招待が承認され、GitHubから招待が送信されました。メールを確認してください")
+	}
+	if len(members) > 0 && members[0].OriginChannelID() != h.botChannelID {
+		h.notifyRequester(ctx, members, fmt.Sprintf("招待が承認されました。既に %s のメンバーです", h.githubClient.OrgName()))
+	}
+	if len(failed) > 0 {
+		h.notifyRequester(ctx, failed, "招待は承認されましたが、送信に失敗しました。adminが対応するまでお待ちください")
+	}
+
+	return results
+}
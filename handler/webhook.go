@@ -0,0 +1,168 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/google/go-github/v63/github"
+	"github.com/traP-jp/members_bot/model"
+	"github.com/traP-jp/members_bot/repository"
+)
+
+const gitHubWebhookPath = "/github/webhook"
+
+// Webhookのリクエストを読み書きする時間の上限
+const (
+	webhookReadHeaderTimeout = 5 * time.Second
+	webhookReadTimeout       = 10 * time.Second
+	webhookWriteTimeout      = 30 * time.Second
+)
+
+// ServeWebhook は、GitHubのWebhookを受け取るHTTPサーバーを起動する。
+// GITHUB_WEBHOOK_ADDR が設定されていない場合は何もしない
+func (h *BotHandler) ServeWebhook() error {
+	if h.gitHubWebhookAddr == "" {
+		return nil
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST "+gitHubWebhookPath, h.GitHubWebhook)
+
+	server := &http.Server{
+		Addr:              h.gitHubWebhookAddr,
+		Handler:           mux,
+		ReadHeaderTimeout: webhookReadHeaderTimeout,
+		ReadTimeout:       webhookReadTimeout,
+		WriteTimeout:      webhookWriteTimeout,
+	}
+
+	return server.ListenAndServe()
+}
+
+// GitHubWebhook は、GitHub AppのWebhookを受け取り、Organizationのメンバーの変化をbotのチャンネルに知らせる。
+// X-Hub-Signature-256 の署名が正しくないリクエストは拒否する
+func (h *BotHandler) GitHubWebhook(w http.ResponseWriter, r *http.Request) {
+	if h.gitHubWebhookSecret == "" {
+		http.Error(w, "webhook is not configured", http.StatusNotFound)
+		return
+	}
+
+	body, err := github.ValidatePayload(r, []byte(h.gitHubWebhookSecret))
+	if err != nil {
+		http.Error(w, "invalid signature", http.StatusUnauthorized)
+		return
+	}
+
+	event, err := github.ParseWebHook(github.WebHookType(r), body)
+	if err != nil {
+		// 受け取る設定にしていないイベントは無視する
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+
+	ctx := r.Context()
+	switch e := event.(type) {
+	case *github.OrganizationEvent:
+		h.organizationEvent(ctx, e)
+	case *github.MembershipEvent:
+		h.membershipEvent(ctx, e)
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *BotHandler) organizationEvent(ctx context.Context, e *github.OrganizationEvent) {
+	var message string
+	switch e.GetAction() {
+	case "member_invited":
+		login := e.GetInvitation().GetLogin()
+		if login == "" {
+			return // メールアドレスへの招待
+		}
+		message = fmt.Sprintf("GitHubユーザー %s が %s に招待されました", login, h.githubClient.OrgName())
+	case "member_added":
+		user := e.GetMembership().GetUser()
+		login := user.GetLogin()
+		message = fmt.Sprintf("GitHubユーザー %s が %s に参加しました", login, h.githubClient.OrgName())
+
+		inv, err := h.acceptInvitation(ctx, user.GetID(), login)
+		if err != nil {
+			logger.Printf("failed to accept invitation: %v", err)
+		}
+		if inv != nil {
+			message = fmt.Sprintf("@%s (%s) が %s に参加しました", strings.TrimPrefix(inv.TraqID(), "@"), login, h.githubClient.OrgName())
+		}
+	case "member_removed":
+		login := e.GetMembership().GetUser().GetLogin()
+		message = fmt.Sprintf("GitHubユーザー %s が %s から外れました", login, h.githubClient.OrgName())
+	default:
+		return
+	}
+
+	_, err := h.traqClient.PostMessage(ctx, h.botChannelID, message)
+	if err != nil {
+		logger.Printf("failed to post message: %v", err)
+	}
+}
+
+func (h *BotHandler) membershipEvent(ctx context.Context, e *github.MembershipEvent) {
+	var message string
+	switch e.GetAction() {
+	case "added":
+		message = fmt.Sprintf("GitHubユーザー %s がチーム %s に追加されました", e.GetMember().GetLogin(), e.GetTeam().GetName())
+	case "removed":
+		message = fmt.Sprintf("GitHubユーザー %s がチーム %s から外れました", e.GetMember().GetLogin(), e.GetTeam().GetName())
+	default:
+		return
+	}
+
+	_, err := h.traqClient.PostMessage(ctx, h.botChannelID, message)
+	if err != nil {
+		logger.Printf("failed to post message: %v", err)
+	}
+}
+
+// acceptInvitation は、GitHubユーザーへ送信した招待を、承諾されたことにする。
+// ユーザーIDを記録している招待はユーザーIDで、記録していない招待はユーザー名で照合する。
+// 送信した招待がない場合はnilを返す
+func (h *BotHandler) acceptInvitation(ctx context.Context, gitHubUserID int64, gitHubID string) (*model.Invitation, error) {
+	invitations, err := h.ir.GetInvitationsByStatus(ctx, model.InvitationStatusSent)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get invitations: %w", err)
+	}
+
+	for _, inv := range invitations {
+		if inv.GitHubUserID() != 0 {
+			if inv.GitHubUserID() != gitHubUserID {
+				continue
+			}
+		} else if !strings.EqualFold(inv.GitHubID(), gitHubID) {
+			continue
+		}
+
+		err := h.ir.UpdateInvitationStatusByGitHubID(ctx, inv.MessageID(), inv.GitHubID(), model.InvitationStatusAccepted)
+		if errors.Is(err, repository.ErrInvalidStatusTransition) {
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to update invitation status: %w", err)
+		}
+
+		if !strings.EqualFold(inv.GitHubID(), gitHubID) {
+			// 招待した後にユーザー名が変わっていた
+			err := h.renameGitHubLogin(ctx, gitHubUserID, gitHubID)
+			if err != nil {
+				logger.Printf("failed to rename GitHub login: %v", err)
+			}
+			inv = inv.WithGitHubID(gitHubID)
+		}
+
+		return inv, nil
+	}
+
+	return nil, nil
+}
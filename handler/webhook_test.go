@@ -0,0 +1,200 @@
+package handler
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/traP-jp/members_bot/model"
+	repomock "github.com/traP-jp/members_bot/repository/mock"
+	"github.com/traP-jp/members_bot/service/mock"
+)
+
+const webhookSecret = "secret"
+
+func signWebhookPayload(secret string, body string) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(body))
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+func TestGitHubWebhook(t *testing.T) {
+	t.Parallel()
+
+	messageID := uuid.NewString()
+
+	type test struct {
+		event       string
+		body        string
+		signature   string
+		invitations []*model.Invitation
+		statusCode  int
+		accepted    bool
+		renamed     bool
+		postText    string
+	}
+
+	testCases := map[string]test{
+		"招待した人が参加した": {
+			event: "organization",
+			body:  `{"action": "member_added", "membership": {"user": {"login": "ikura-hamu"}}}`,
+			invitations: []*model.Invitation{
+				model.NewInvitation(messageID, "@ikura-hamu", "Ikura-Hamu", model.WithStatus(model.InvitationStatusSent)),
+			},
+			statusCode: http.StatusNoContent,
+			accepted:   true,
+			postText:   "@ikura-hamu (ikura-hamu) が traP-jp に参加しました",
+		},
+		"招待した後にユーザー名を変えた人が参加した": {
+			event: "organization",
+			body:  `{"action": "member_added", "membership": {"user": {"login": "ikura-hamu", "id": 1001}}}`,
+			invitations: []*model.Invitation{
+				model.NewInvitation(messageID, "@ikura-hamu", "old-name", model.WithStatus(model.InvitationStatusSent), model.WithGitHubUserID(1001)),
+			},
+			statusCode: http.StatusNoContent,
+			accepted:   true,
+			renamed:    true,
+			postText:   "@ikura-hamu (ikura-hamu) が traP-jp に参加しました",
+		},
+		"ユーザーIDが違う人は同じユーザー名でも招待した人とみなさない": {
+			event: "organization",
+			body:  `{"action": "member_added", "membership": {"user": {"login": "ikura-hamu", "id": 2002}}}`,
+			invitations: []*model.Invitation{
+				model.NewInvitation(messageID, "@ikura-hamu", "ikura-hamu", model.WithStatus(model.InvitationStatusSent), model.WithGitHubUserID(1001)),
+			},
+			statusCode: http.StatusNoContent,
+			postText:   "GitHubユーザー ikura-hamu が traP-jp に参加しました",
+		},
+		"botが招待していない人が参加した": {
+			event:      "organization",
+			body:       `{"action": "member_added", "membership": {"user": {"login": "H1rono"}}}`,
+			statusCode: http.StatusNoContent,
+			postText:   "GitHubユーザー H1rono が traP-jp に参加しました",
+		},
+		"招待された": {
+			event:      "organization",
+			body:       `{"action": "member_invited", "invitation": {"login": "ikura-hamu"}}`,
+			statusCode: http.StatusNoContent,
+			postText:   "GitHubユーザー ikura-hamu が traP-jp に招待されました",
+		},
+		"外れた": {
+			event:      "organization",
+			body:       `{"action": "member_removed", "membership": {"user": {"login": "ikura-hamu"}}}`,
+			statusCode: http.StatusNoContent,
+			postText:   "GitHubユーザー ikura-hamu が traP-jp から外れました",
+		},
+		"チームに追加された": {
+			event:      "membership",
+			body:       `{"action": "added", "scope": "team", "member": {"login": "ikura-hamu"}, "team": {"name": "SysAd"}}`,
+			statusCode: http.StatusNoContent,
+			postText:   "GitHubユーザー ikura-hamu がチーム SysAd に追加されました",
+		},
+		"関係ないイベント": {
+			event:      "ping",
+			body:       `{"zen": "Keep it logically awesome."}`,
+			statusCode: http.StatusNoContent,
+		},
+		"署名が正しくない": {
+			event:      "organization",
+			body:       `{"action": "member_added", "membership": {"user": {"login": "ikura-hamu"}}}`,
+			signature:  signWebhookPayload("wrong secret", `{"action": "member_added", "membership": {"user": {"login": "ikura-hamu"}}}`),
+			statusCode: http.StatusUnauthorized,
+		},
+	}
+
+	for name, test := range testCases {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			traqMock := &mock.TraqMock{
+				PostMessageFunc: func(context.Context, string, string) (string, error) {
+					return uuid.NewString(), nil
+				},
+			}
+			gitHubMock := &mock.GitHubMock{
+				OrgNameFunc: func() string {
+					return "traP-jp"
+				},
+			}
+			irMock := &repomock.InvitationMock{
+				GetInvitationsByStatusFunc: func(ctx context.Context, statuses ...model.InvitationStatus) ([]*model.Invitation, error) {
+					return test.invitations, nil
+				},
+				UpdateInvitationStatusByGitHubIDFunc: func(ctx context.Context, invitationID string, gitHubID string, status model.InvitationStatus) error {
+					return nil
+				},
+				UpdateInvitationGitHubIDFunc: func(ctx context.Context, gitHubUserID int64, gitHubID string) error {
+					return nil
+				},
+			}
+			alrMock := &repomock.AccountLinkMock{
+				UpdateAccountLinkGitHubLoginFunc: func(ctx context.Context, gitHubUserID int64, login string) error {
+					return nil
+				},
+			}
+
+			bh := &BotHandler{
+				traqClient:   traqMock,
+				githubClient: gitHubMock,
+				ir:           irMock,
+				alr:          alrMock,
+				Config: &Config{
+					botChannelID:        "botChannelID",
+					gitHubWebhookSecret: webhookSecret,
+				},
+			}
+
+			signature := test.signature
+			if signature == "" {
+				signature = signWebhookPayload(webhookSecret, test.body)
+			}
+
+			req := httptest.NewRequest(http.MethodPost, gitHubWebhookPath, strings.NewReader(test.body))
+			req.Header.Set("Content-Type", "application/json")
+			req.Header.Set("X-GitHub-Event", test.event)
+			req.Header.Set("X-Hub-Signature-256", signature)
+			rec := httptest.NewRecorder()
+
+			bh.GitHubWebhook(rec, req)
+
+			assert.Equal(t, test.statusCode, rec.Code)
+
+			// 同じメッセージで申請された他の人の招待を変えないように、GitHubのユーザー名を指定して更新する
+			updateCalls := irMock.UpdateInvitationStatusByGitHubIDCalls()
+			if test.accepted {
+				require.Len(t, updateCalls, 1)
+				assert.Equal(t, messageID, updateCalls[0].InvitationID)
+				assert.NotEmpty(t, updateCalls[0].GitHubID)
+				assert.Equal(t, model.InvitationStatusAccepted, updateCalls[0].Status)
+			} else {
+				assert.Empty(t, updateCalls)
+			}
+
+			renameCalls := irMock.UpdateInvitationGitHubIDCalls()
+			if test.renamed {
+				require.Len(t, renameCalls, 1)
+				assert.Equal(t, int64(1001), renameCalls[0].GitHubUserID)
+				assert.Equal(t, "ikura-hamu", renameCalls[0].GitHubID)
+			} else {
+				assert.Empty(t, renameCalls)
+			}
+
+			postCalls := traqMock.PostMessageCalls()
+			if test.postText == "" {
+				assert.Empty(t, postCalls)
+			} else {
+				require.Len(t, postCalls, 1)
+				assert.Equal(t, "botChannelID", postCalls[0].ChannelID)
+				assert.Equal(t, test.postText, postCalls[0].Text)
+			}
+		})
+	}
+}
@@ -0,0 +1,155 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/traP-jp/members_bot/model"
+	"github.com/traP-jp/members_bot/repository"
+	"github.com/traPtitech/traq-ws-bot/payload"
+)
+
+const whoisCommandUsage = "`@BOT_traP-jp /(whois|誰) <@traQID>|<GitHubID>`"
+
+func whoisCommandMessage(message string) string {
+	return fmt.Sprintf("%s\n%s", message, whoisCommandUsage)
+}
+
+// whois は、traQのユーザーに対応するGitHubのアカウント、またはその逆を、Organizationでの状態と一緒に表示する。
+// @から始まる場合はtraQのユーザー、それ以外はGitHubのユーザーとして扱う
+func (h *BotHandler) whois(p *payload.MessageCreated) {
+	ctx := context.Background()
+
+	mentionRawText, _ := checkIfBotMentioned(p, h.botUser.ID())
+	splitText := regexp.MustCompile(`\s+`).Split(strings.TrimSpace(strings.Replace(p.Message.PlainText, mentionRawText, "", 1)), -1)
+
+	if len(splitText) > 1 && slices.Contains([]string{"-h", "-help", "--help"}, splitText[1]) {
+		_, err := h.traqClient.PostMessage(ctx, p.Message.ChannelID,
+			whoisCommandMessage("/whois は、traQのユーザーとGitHubのアカウントの対応を調べるためのコマンドです。"))
+		if err != nil {
+			logger.Println("failed to post message: ", err)
+		}
+		return
+	}
+
+	if len(splitText) != 2 {
+		_, err := h.traqClient.PostMessage(ctx, p.Message.ChannelID, whoisCommandMessage("引数の数が合いません"))
+		if err != nil {
+			logger.Println("failed to post message: ", err)
+		}
+		return
+	}
+	target := splitText[1]
+
+	var (
+		message string
+		err     error
+	)
+	if strings.HasPrefix(target, "@") {
+		message, err = h.whoisTraqUser(ctx, strings.TrimPrefix(target, "@"))
+	} else {
+		message, err = h.whoisGitHubUser(ctx, target)
+	}
+	if err != nil {
+		logger.Println("failed to look up account: ", err)
+		return
+	}
+
+	_, err = h.traqClient.PostMessage(ctx, p.Message.ChannelID, message)
+	if err != nil {
+		logger.Println("failed to post message: ", err)
+	}
+}
+
+func (h *BotHandler) whoisTraqUser(ctx context.Context, traqName string) (string, error) {
+	links, err := h.alr.GetAccountLinksByTraqName(ctx, traqName)
+	if err != nil {
+		return "", fmt.Errorf("failed to get account links: %w", err)
+	}
+	if len(links) == 0 {
+		return fmt.Sprintf("@%s に対応するGitHubアカウントは記録されていません", traqName), nil
+	}
+
+	sections := make([]string, 0, len(links))
+	for _, link := range links {
+		section, err := h.gitHubAccountText(ctx, link.GitHubLogin(), link)
+		if err != nil {
+			return "", err
+		}
+		sections = append(sections, section)
+	}
+
+	return strings.Join(sections, "\n\n"), nil
+}
+
+func (h *BotHandler) whoisGitHubUser(ctx context.Context, login string) (string, error) {
+	link, err := h.alr.GetAccountLinkByGitHubLogin(ctx, login)
+	if errors.Is(err, repository.ErrRecordNotFound) {
+		exist, err := h.githubClient.CheckUserExist(ctx, login)
+		if err != nil {
+			return "", fmt.Errorf("failed to check user exist: %w", err)
+		}
+		if !exist {
+			return fmt.Sprintf("GitHubユーザー %s は存在しません", login), nil
+		}
+	} else if err != nil {
+		return "", fmt.Errorf("failed to get account link: %w", err)
+	}
+
+	return h.gitHubAccountText(ctx, login, link)
+}
+
+// gitHubAccountText は、GitHubのアカウントに対応するtraQのユーザー、Organizationでの状態、招待された経緯を返す。
+// 対応が記録されていない場合、linkはnil
+func (h *BotHandler) gitHubAccountText(ctx context.Context, login string, link *model.AccountLink) (string, error) {
+	text := fmt.Sprintf("GitHub: %s https://github.com/%s\n", login, login)
+	if link != nil {
+		text += fmt.Sprintf("traQ: @%s\n", link.TraqName())
+	} else {
+		text += "traQ: 記録されていません\n"
+	}
+
+	membership, err := h.githubClient.GetOrgMembership(ctx, login)
+	if err != nil {
+		return "", fmt.Errorf("failed to get org membership: %w", err)
+	}
+	switch {
+	case membership == nil:
+		text += fmt.Sprintf("%s: 所属していません\n", h.githubClient.OrgName())
+	case membership.State() == model.GitHubMembershipStatePending:
+		text += fmt.Sprintf("%s: 招待中 (%s)\n", h.githubClient.OrgName(), membership.Role())
+	default:
+		text += fmt.Sprintf("%s: メンバー (%s)\n", h.githubClient.OrgName(), membership.Role())
+	}
+	if membership != nil && len(membership.Teams()) > 0 {
+		text += fmt.Sprintf("チーム: %s\n", strings.Join(membership.Teams(), ", "))
+	}
+
+	// 最後に承認された招待を、招待された経緯として表示する。その後に却下された申請があっても、承認された招待を表示する
+	invitations, err := h.ir.GetInvitationHistory(ctx, repository.InvitationHistoryQuery{
+		GitHubID: login,
+		Statuses: []model.InvitationStatus{model.InvitationStatusApproved, model.InvitationStatusSent, model.InvitationStatusAccepted},
+		Limit:    1,
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to get invitation history: %w", err)
+	}
+	if len(invitations) > 0 {
+		inv := invitations[0]
+		invitedAt := inv.TransitionedAt(model.InvitationStatusSent)
+		if invitedAt.IsZero() {
+			invitedAt = inv.TransitionedAt(model.InvitationStatusApproved)
+		}
+		requester := "不明"
+		if inv.Requester() != nil {
+			requester = "@" + inv.Requester().Name()
+		}
+		text += fmt.Sprintf("招待: %s (申請者: %s) %s\n", formatTime(invitedAt), requester, invitationStatusLabels[inv.Status()])
+	}
+
+	return strings.TrimSuffix(text, "\n"), nil
+}
@@ -0,0 +1,142 @@
+package handler
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/traP-jp/members_bot/model"
+	"github.com/traP-jp/members_bot/repository"
+	repomock "github.com/traP-jp/members_bot/repository/mock"
+	"github.com/traP-jp/members_bot/service/mock"
+	"github.com/traPtitech/traq-ws-bot/payload"
+)
+
+func TestWhois(t *testing.T) {
+	t.Parallel()
+
+	botUserID := uuid.NewString()
+	sentAt := time.Date(2026, 10, 1, 12, 30, 0, 0, jst)
+	invitation := model.NewInvitation(uuid.NewString(), "@ikura-hamu", "ikura-hamu",
+		model.WithStatus(model.InvitationStatusAccepted),
+		model.WithOrigin(model.NewUser(uuid.NewString(), "requester"), uuid.NewString(), uuid.NewString()),
+		model.WithTransitionedAt(model.InvitationStatusApproved, sentAt.Add(-time.Minute)),
+		model.WithTransitionedAt(model.InvitationStatusSent, sentAt))
+	link := model.NewAccountLink(uuid.NewString(), "ikura-hamu", 0, "ikura-hamu", model.AccountLinkSourceInvitation, sentAt)
+
+	type test struct {
+		plainText   string
+		linksByName []*model.AccountLink
+		link        *model.AccountLink
+		userExists  bool
+		membership  *model.GitHubMembership
+		invitations []*model.Invitation
+		postText    string
+	}
+
+	testCases := map[string]test{
+		"traQのユーザーから調べる": {
+			plainText:   "@BOT_traP-jp /whois @ikura-hamu",
+			linksByName: []*model.AccountLink{link},
+			membership:  model.NewGitHubMembership(model.GitHubMembershipStateActive, "member", []string{"SysAd", "Web"}),
+			invitations: []*model.Invitation{invitation},
+			postText: "GitHub: ikura-hamu https://github.com/ikura-hamu\ntraQ: @ikura-hamu\ntraP-jp: メンバー (member)\n" +
+				"チーム: SysAd, Web\n招待: 2026/10/01 12:30 (申請者: @requester) 承認(参加済み)",
+		},
+		"GitHubのユーザーから調べる": {
+			plainText:  "@BOT_traP-jp /誰 ikura-hamu",
+			link:       link,
+			membership: model.NewGitHubMembership(model.GitHubMembershipStatePending, "admin", []string{}),
+			postText:   "GitHub: ikura-hamu https://github.com/ikura-hamu\ntraQ: @ikura-hamu\ntraP-jp: 招待中 (admin)",
+		},
+		"対応が記録されていないGitHubのユーザー": {
+			plainText:  "@BOT_traP-jp /whois H1rono",
+			userExists: true,
+			postText:   "GitHub: H1rono https://github.com/H1rono\ntraQ: 記録されていません\ntraP-jp: 所属していません",
+		},
+		"存在しないGitHubのユーザー": {
+			plainText: "@BOT_traP-jp /whois H1rono",
+			postText:  "GitHubユーザー H1rono は存在しません",
+		},
+		"対応が記録されていないtraQのユーザー": {
+			plainText: "@BOT_traP-jp /whois @H1rono",
+			postText:  "@H1rono に対応するGitHubアカウントは記録されていません",
+		},
+		"引数がない": {
+			plainText: "@BOT_traP-jp /whois",
+			postText:  whoisCommandMessage("引数の数が合いません"),
+		},
+	}
+
+	for name, test := range testCases {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			traqMock := &mock.TraqMock{
+				PostMessageFunc: func(context.Context, string, string) (string, error) {
+					return "", nil
+				},
+			}
+			gitHubMock := &mock.GitHubMock{
+				OrgNameFunc: func() string {
+					return "traP-jp"
+				},
+				CheckUserExistFunc: func(context.Context, string) (bool, error) {
+					return test.userExists, nil
+				},
+				GetOrgMembershipFunc: func(context.Context, string) (*model.GitHubMembership, error) {
+					return test.membership, nil
+				},
+			}
+			accountLinkRepoMock := &repomock.AccountLinkMock{
+				GetAccountLinksByTraqNameFunc: func(context.Context, string) ([]*model.AccountLink, error) {
+					return test.linksByName, nil
+				},
+				GetAccountLinkByGitHubLoginFunc: func(context.Context, string) (*model.AccountLink, error) {
+					if test.link == nil {
+						return nil, repository.ErrRecordNotFound
+					}
+					return test.link, nil
+				},
+			}
+			invRepoMock := &repomock.InvitationMock{
+				GetInvitationHistoryFunc: func(context.Context, repository.InvitationHistoryQuery) ([]*model.Invitation, error) {
+					return test.invitations, nil
+				},
+			}
+
+			bh := &BotHandler{
+				traqClient:   traqMock,
+				githubClient: gitHubMock,
+				ir:           invRepoMock,
+				alr:          accountLinkRepoMock,
+				botUser:      model.NewUser(botUserID, "BOT_traP-jp"),
+				Config:       &Config{botChannelID: "botChannelID"},
+			}
+
+			bh.whois(&payload.MessageCreated{
+				Message: payload.Message{
+					PlainText: test.plainText,
+					ID:        uuid.NewString(),
+					ChannelID: "channelID",
+					Embedded:  []payload.EmbeddedInfo{{Type: "user", Raw: "@BOT_traP-jp", ID: botUserID}},
+				},
+			})
+
+			postMessageCalls := traqMock.PostMessageCalls()
+			require.Len(t, postMessageCalls, 1)
+			assert.Equal(t, "channelID", postMessageCalls[0].ChannelID)
+			assert.Equal(t, test.postText, postMessageCalls[0].Text)
+
+			// 却下された申請に隠れないように、承認された招待だけを調べる
+			for _, call := range invRepoMock.GetInvitationHistoryCalls() {
+				assert.ElementsMatch(t,
+					[]model.InvitationStatus{model.InvitationStatusApproved, model.InvitationStatusSent, model.InvitationStatusAccepted},
+					call.Query.Statuses)
+			}
+		})
+	}
+}
@@ -1,6 +1,9 @@
 package main
 
 import (
+	"bytes"
+	"context"
+	"io"
 	"log"
 	"os"
 
@@ -11,6 +14,25 @@ import (
 	traqwsbot "github.com/traPtitech/traq-ws-bot"
 )
 
+// traq-ws-botがtraQに接続したときに出力するログ (v1.1.4 の bot.go)
+const connectedLog = "[traq-ws-bot] Connected!"
+
+// connectedLogWriter は、traq-ws-botが接続したときのログを見つけると、onConnectedを呼ぶ。
+// traq-ws-botには接続したことを知らせるイベントがないので、標準のlogへの出力から判断する。
+// ログの文言が変わるなどして呼ばれなくなっても、StartJobs で定期的に判定し直すので、判定が遅れるだけで済む
+type connectedLogWriter struct {
+	w           io.Writer
+	onConnected func()
+}
+
+func (c *connectedLogWriter) Write(p []byte) (int, error) {
+	if bytes.Contains(p, []byte(connectedLog)) {
+		go c.onConnected()
+	}
+
+	return c.w.Write(p)
+}
+
 func main() {
 	botToken, ok := os.LookupEnv("TRAQ_BOT_TOKEN")
 	if !ok {
@@ -46,12 +68,25 @@ func main() {
 	}
 
 	ir := repoimpl.NewInvitation(db)
+	dr := repoimpl.NewDecision(db)
+	rr := repoimpl.NewRemoval(db)
+	alr := repoimpl.NewAccountLink(db)
+	tx := repoimpl.NewTransaction(db)
 
-	bh, err := handler.NewBotHandler(tc, gh, ir)
+	bh, err := handler.NewBotHandler(tc, gh, ir, dr, rr, alr, tx)
 	if err != nil {
 		panic(err)
 	}
 
+	bh.StartJobs(context.Background())
+
+	go func() {
+		// Webhookを受け取れなくても、traQのbotは動かし続ける
+		if err := bh.ServeWebhook(); err != nil {
+			log.Printf("failed to serve webhook: %v", err)
+		}
+	}()
+
 	bot.OnError(func(message string) {
 		log.Println("Received ERROR message: " + message)
 	})
@@ -59,5 +94,14 @@ func main() {
 	bot.OnMessageCreated(bh.MessageCreated)
 	bot.OnBotMessageStampsUpdated(bh.AcceptOrReject)
 
+	// 接続していなかった間のスタンプのイベントは届かないので、接続するたびに判定し直す。
+	// 標準のlogの出力先を変えるのはここだけにする
+	log.SetOutput(&connectedLogWriter{
+		w: log.Writer(),
+		onConnected: func() {
+			bh.ReconcileInvitations(context.Background())
+		},
+	})
+
 	log.Fatal(bot.Start())
 }
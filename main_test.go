@@ -0,0 +1,77 @@
+package main
+
+import (
+	"bytes"
+	"log"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// traq-ws-botを更新して接続したときのログが変わった場合に、気づけるようにする
+func TestConnectedLogInLibrary(t *testing.T) {
+	t.Parallel()
+
+	out, err := exec.Command("go", "list", "-m", "-f", "{{.Dir}}", "github.com/traPtitech/traq-ws-bot").Output()
+	if err != nil {
+		t.Skipf("failed to find traq-ws-bot: %v", err)
+	}
+
+	src, err := os.ReadFile(filepath.Join(strings.TrimSpace(string(out)), "bot.go"))
+	require.NoError(t, err)
+
+	assert.Contains(t, string(src), connectedLog)
+}
+
+func TestConnectedLogWriter(t *testing.T) {
+	t.Parallel()
+
+	testCases := map[string]struct {
+		message   string
+		connected bool
+	}{
+		"接続したときのログ": {
+			message:   "[traq-ws-bot] Connected! Now receiving events...",
+			connected: true,
+		},
+		"切断したときのログ": {
+			message: "[traq-ws-bot] Disconnected from WebSocket, retrying in 1s ...",
+		},
+		"他のログ": {
+			message: "Received ERROR message: Connected!",
+		},
+	}
+
+	for name, test := range testCases {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			var buf bytes.Buffer
+			connected := make(chan struct{}, 1)
+			logger := log.New(&connectedLogWriter{
+				w: &buf,
+				onConnected: func() {
+					connected <- struct{}{}
+				},
+			}, "", log.LstdFlags)
+
+			logger.Println(test.message)
+
+			// ログはそのまま出力する
+			assert.Contains(t, buf.String(), test.message)
+
+			select {
+			case <-connected:
+				assert.True(t, test.connected)
+			case <-time.After(100 * time.Millisecond):
+				assert.False(t, test.connected)
+			}
+		})
+	}
+}
@@ -0,0 +1,58 @@
+package model
+
+import "time"
+
+// AccountLinkSource は、traQとGitHubのアカウントの対応を知った経緯
+type AccountLinkSource string
+
+const (
+	// 招待の申請が承認された
+	AccountLinkSourceInvitation AccountLinkSource = "invitation"
+)
+
+// AccountLink は、traQのアカウントとGitHubのアカウントの対応
+type AccountLink struct {
+	// traQのUUID。分からない場合は空文字列
+	traqUserID string
+	traqName   string
+	// GitHubのユーザーID。分からない場合は0
+	gitHubUserID int64
+	gitHubLogin  string
+	source       AccountLinkSource
+	linkedAt     time.Time
+}
+
+func NewAccountLink(traqUserID, traqName string, gitHubUserID int64, gitHubLogin string, source AccountLinkSource, linkedAt time.Time) *AccountLink {
+	return &AccountLink{
+		traqUserID:   traqUserID,
+		traqName:     traqName,
+		gitHubUserID: gitHubUserID,
+		gitHubLogin:  gitHubLogin,
+		source:       source,
+		linkedAt:     linkedAt,
+	}
+}
+
+func (l *AccountLink) TraqUserID() string {
+	return l.traqUserID
+}
+
+func (l *AccountLink) TraqName() string {
+	return l.traqName
+}
+
+func (l *AccountLink) GitHubUserID() int64 {
+	return l.gitHubUserID
+}
+
+func (l *AccountLink) GitHubLogin() string {
+	return l.gitHubLogin
+}
+
+func (l *AccountLink) Source() AccountLinkSource {
+	return l.source
+}
+
+func (l *AccountLink) LinkedAt() time.Time {
+	return l.linkedAt
+}
@@ -0,0 +1,84 @@
+package model
+
+import "time"
+
+// Vote は、判定に数えられたスタンプ1つを表す
+type Vote struct {
+	userID    string
+	stampID   string
+	stampedAt time.Time
+}
+
+func NewVote(userID, stampID string, stampedAt time.Time) *Vote {
+	return &Vote{
+		userID:    userID,
+		stampID:   stampID,
+		stampedAt: stampedAt,
+	}
+}
+
+func (v *Vote) UserID() string {
+	return v.userID
+}
+
+func (v *Vote) StampID() string {
+	return v.stampID
+}
+
+func (v *Vote) StampedAt() time.Time {
+	return v.stampedAt
+}
+
+// DecisionKind は、判定した申請の種類を表す
+type DecisionKind string
+
+const (
+	DecisionKindInvitation DecisionKind = "invitation"
+	DecisionKindRemoval    DecisionKind = "removal"
+)
+
+type DecisionResult string
+
+const (
+	DecisionResultApproved DecisionResult = "approved"
+	DecisionResultRejected DecisionResult = "rejected"
+)
+
+// Decision は、招待やメンバーから外す申請の承認・却下の判定結果を表す
+type Decision struct {
+	messageID string
+	kind      DecisionKind
+	result    DecisionResult
+	votes     []*Vote
+	decidedAt time.Time
+}
+
+func NewDecision(messageID string, kind DecisionKind, result DecisionResult, votes []*Vote, decidedAt time.Time) *Decision {
+	return &Decision{
+		messageID: messageID,
+		kind:      kind,
+		result:    result,
+		votes:     votes,
+		decidedAt: decidedAt,
+	}
+}
+
+func (d *Decision) MessageID() string {
+	return d.messageID
+}
+
+func (d *Decision) Kind() DecisionKind {
+	return d.kind
+}
+
+func (d *Decision) Result() DecisionResult {
+	return d.result
+}
+
+func (d *Decision) Votes() []*Vote {
+	return d.votes
+}
+
+func (d *Decision) DecidedAt() time.Time {
+	return d.decidedAt
+}
@@ -0,0 +1,43 @@
+package model
+
+import "time"
+
+// GitHubInvitation は、GitHubのOrganizationへの招待
+type GitHubInvitation struct {
+	id        int64
+	login     string
+	createdAt time.Time
+	// 招待が失敗した日時と理由。失敗していない場合はゼロ値
+	failedAt     time.Time
+	failedReason string
+}
+
+func NewGitHubInvitation(id int64, login string, createdAt time.Time, failedAt time.Time, failedReason string) *GitHubInvitation {
+	return &GitHubInvitation{
+		id:           id,
+		login:        login,
+		createdAt:    createdAt,
+		failedAt:     failedAt,
+		failedReason: failedReason,
+	}
+}
+
+func (i *GitHubInvitation) ID() int64 {
+	return i.id
+}
+
+func (i *GitHubInvitation) Login() string {
+	return i.login
+}
+
+func (i *GitHubInvitation) CreatedAt() time.Time {
+	return i.createdAt
+}
+
+func (i *GitHubInvitation) FailedAt() time.Time {
+	return i.failedAt
+}
+
+func (i *GitHubInvitation) FailedReason() string {
+	return i.failedReason
+}
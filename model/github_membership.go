@@ -0,0 +1,39 @@
+package model
+
+type GitHubMembershipState string
+
+const (
+	// Organizationのメンバー
+	GitHubMembershipStateActive GitHubMembershipState = "active"
+	// 招待を承諾していない
+	GitHubMembershipStatePending GitHubMembershipState = "pending"
+)
+
+// GitHubMembership は、GitHubのOrganizationでのユーザーの状態
+type GitHubMembership struct {
+	state GitHubMembershipState
+	// admin, member など
+	role string
+	// 所属しているチームの名前
+	teams []string
+}
+
+func NewGitHubMembership(state GitHubMembershipState, role string, teams []string) *GitHubMembership {
+	return &GitHubMembership{
+		state: state,
+		role:  role,
+		teams: teams,
+	}
+}
+
+func (m *GitHubMembership) State() GitHubMembershipState {
+	return m.state
+}
+
+func (m *GitHubMembership) Role() string {
+	return m.role
+}
+
+func (m *GitHubMembership) Teams() []string {
+	return m.teams
+}
@@ -1,17 +1,182 @@
 package model
 
+import (
+	"slices"
+	"time"
+)
+
+type InvitationStatus string
+
+const (
+	InvitationStatusPending    InvitationStatus = "pending"
+	InvitationStatusApproved   InvitationStatus = "approved"
+	InvitationStatusRejected   InvitationStatus = "rejected"
+	InvitationStatusSent       InvitationStatus = "sent"
+	InvitationStatusSendFailed InvitationStatus = "send_failed"
+	InvitationStatusAccepted   InvitationStatus = "accepted"
+	InvitationStatusExpired    InvitationStatus = "expired"
+	InvitationStatusCancelled  InvitationStatus = "cancelled"
+	// 送り直すために、1つの処理が招待を確保している状態。同じ招待を同時に送り直さないようにする。
+	// 送信の途中で処理が止まって長く続いている場合は、送信失敗に戻す
+	InvitationStatusSending InvitationStatus = "sending"
+)
+
+// 各状態から遷移できる状態
+var invitationStatusTransitions = map[InvitationStatus][]InvitationStatus{
+	InvitationStatusPending: {
+		InvitationStatusApproved,
+		InvitationStatusRejected,
+		InvitationStatusExpired,
+		InvitationStatusCancelled,
+	},
+	InvitationStatusApproved: {
+		InvitationStatusSent,
+		InvitationStatusSendFailed,
+		// 招待を送信する前に、既にメンバーになっていた場合
+		InvitationStatusAccepted,
+	},
+	InvitationStatusSendFailed: {
+		InvitationStatusSending,
+		InvitationStatusCancelled,
+	},
+	// 送り直した結果。期限切れの招待を送り直せなかった場合は、期限切れに戻す
+	InvitationStatusSending: {
+		InvitationStatusSent,
+		InvitationStatusSendFailed,
+		InvitationStatusAccepted,
+		InvitationStatusExpired,
+	},
+	InvitationStatusSent: {
+		InvitationStatusAccepted,
+		InvitationStatusExpired,
+		InvitationStatusCancelled,
+	},
+	// 送信した招待が期限切れになった場合は、送り直せる
+	InvitationStatusExpired: {
+		InvitationStatusSending,
+	},
+}
+
+// CanTransitionTo は、sからnextに遷移できるかを返す
+func (s InvitationStatus) CanTransitionTo(next InvitationStatus) bool {
+	return slices.Contains(invitationStatusTransitions[s], next)
+}
+
+// PreviousStatuses は、sに遷移できる状態の一覧を返す
+func (s InvitationStatus) PreviousStatuses() []InvitationStatus {
+	previous := make([]InvitationStatus, 0)
+	for from, tos := range invitationStatusTransitions {
+		if slices.Contains(tos, s) {
+			previous = append(previous, from)
+		}
+	}
+	slices.Sort(previous)
+
+	return previous
+}
+
 type Invitation struct {
 	messageID string
 	traqID    string
 	gitHubID  string
+	// GitHubのユーザーID。ユーザー名と違って変わらない。分からない場合は0
+	gitHubUserID int64
+	// 招待されるユーザーのtraQのUUID。分からない場合は空文字列
+	traqUserID string
+	// 招待されるユーザーのtraQの表示名。分からない場合は空文字列
+	traqDisplayName string
+	status          InvitationStatus
+	// 申請したユーザー。記録されていない場合はnil
+	requester *User
+	// 申請のメッセージが投稿されたチャンネルとメッセージ
+	originChannelID string
+	originMessageID string
+	// 却下などの理由
+	reason    string
+	createdAt time.Time
+	// 最後にadminにリマインドした日時
+	remindedAt time.Time
+	// 各状態に遷移した日時
+	transitionedAt map[InvitationStatus]time.Time
+}
+
+type InvitationOption func(*Invitation)
+
+func WithStatus(status InvitationStatus) InvitationOption {
+	return func(i *Invitation) {
+		i.status = status
+	}
+}
+
+func WithOrigin(requester *User, channelID, messageID string) InvitationOption {
+	return func(i *Invitation) {
+		i.requester = requester
+		i.originChannelID = channelID
+		i.originMessageID = messageID
+	}
+}
+
+func WithTraqUserID(traqUserID string) InvitationOption {
+	return func(i *Invitation) {
+		i.traqUserID = traqUserID
+	}
+}
+
+func WithTraqDisplayName(traqDisplayName string) InvitationOption {
+	return func(i *Invitation) {
+		i.traqDisplayName = traqDisplayName
+	}
+}
+
+func WithGitHubUserID(gitHubUserID int64) InvitationOption {
+	return func(i *Invitation) {
+		i.gitHubUserID = gitHubUserID
+	}
+}
+
+func WithReason(reason string) InvitationOption {
+	return func(i *Invitation) {
+		i.reason = reason
+	}
+}
+
+func WithCreatedAt(createdAt time.Time) InvitationOption {
+	return func(i *Invitation) {
+		i.createdAt = createdAt
+	}
 }
 
-func NewInvitation(id string, traqID, gitHubID string) *Invitation {
-	return &Invitation{
+func WithRemindedAt(remindedAt time.Time) InvitationOption {
+	return func(i *Invitation) {
+		i.remindedAt = remindedAt
+	}
+}
+
+func WithTransitionedAt(status InvitationStatus, transitionedAt time.Time) InvitationOption {
+	return func(i *Invitation) {
+		if transitionedAt.IsZero() {
+			return
+		}
+		if i.transitionedAt == nil {
+			i.transitionedAt = make(map[InvitationStatus]time.Time)
+		}
+		i.transitionedAt[status] = transitionedAt
+	}
+}
+
+func NewInvitation(id string, traqID, gitHubID string, opts ...InvitationOption) *Invitation {
+	i := &Invitation{
 		messageID: id,
 		traqID:    traqID,
 		gitHubID:  gitHubID,
+		status:    InvitationStatusPending,
+	}
+
+	for _, opt := range opts {
+		opt(i)
 	}
+
+	return i
 }
 
 func (i *Invitation) MessageID() string {
@@ -25,3 +190,56 @@ func (i *Invitation) TraqID() string {
 func (i *Invitation) GitHubID() string {
 	return i.gitHubID
 }
+
+// WithGitHubID は、GitHubのユーザー名をgitHubIDに変えた招待を返す
+func (i *Invitation) WithGitHubID(gitHubID string) *Invitation {
+	renamed := *i
+	renamed.gitHubID = gitHubID
+	return &renamed
+}
+
+func (i *Invitation) TraqDisplayName() string {
+	return i.traqDisplayName
+}
+
+func (i *Invitation) GitHubUserID() int64 {
+	return i.gitHubUserID
+}
+
+func (i *Invitation) TraqUserID() string {
+	return i.traqUserID
+}
+
+func (i *Invitation) Status() InvitationStatus {
+	return i.status
+}
+
+func (i *Invitation) Requester() *User {
+	return i.requester
+}
+
+func (i *Invitation) OriginChannelID() string {
+	return i.originChannelID
+}
+
+func (i *Invitation) OriginMessageID() string {
+	return i.originMessageID
+}
+
+func (i *Invitation) Reason() string {
+	return i.reason
+}
+
+func (i *Invitation) CreatedAt() time.Time {
+	return i.createdAt
+}
+
+// RemindedAt は、最後にadminにリマインドした日時を返す。リマインドしていない場合はゼロ値を返す
+func (i *Invitation) RemindedAt() time.Time {
+	return i.remindedAt
+}
+
+// TransitionedAt は、statusに遷移した日時を返す。遷移していない場合はゼロ値を返す
+func (i *Invitation) TransitionedAt(status InvitationStatus) time.Time {
+	return i.transitionedAt[status]
+}
@@ -0,0 +1,138 @@
+package model
+
+import (
+	"slices"
+	"time"
+)
+
+type RemovalStatus string
+
+const (
+	RemovalStatusPending      RemovalStatus = "pending"
+	RemovalStatusApproved     RemovalStatus = "approved"
+	RemovalStatusRejected     RemovalStatus = "rejected"
+	RemovalStatusRemoved      RemovalStatus = "removed"
+	RemovalStatusRemoveFailed RemovalStatus = "remove_failed"
+	// 外せなかったメンバーを、外し直している途中。同時に外し直さないようにするための状態
+	RemovalStatusRemoving RemovalStatus = "removing"
+)
+
+// 各状態から遷移できる状態
+var removalStatusTransitions = map[RemovalStatus][]RemovalStatus{
+	RemovalStatusPending: {
+		RemovalStatusApproved,
+		RemovalStatusRejected,
+	},
+	RemovalStatusApproved: {
+		RemovalStatusRemoved,
+		RemovalStatusRemoveFailed,
+	},
+	RemovalStatusRemoveFailed: {
+		RemovalStatusRemoving,
+	},
+	RemovalStatusRemoving: {
+		RemovalStatusRemoved,
+		RemovalStatusRemoveFailed,
+	},
+}
+
+// PreviousStatuses は、sに遷移できる状態の一覧を返す
+func (s RemovalStatus) PreviousStatuses() []RemovalStatus {
+	previous := make([]RemovalStatus, 0)
+	for from, tos := range removalStatusTransitions {
+		if slices.Contains(tos, s) {
+			previous = append(previous, from)
+		}
+	}
+	slices.Sort(previous)
+
+	return previous
+}
+
+// Removal は、GitHubのOrganizationからメンバーを外す申請
+type Removal struct {
+	messageID string
+	gitHubID  string
+	status    RemovalStatus
+	// 外す理由
+	reason string
+	// 申請したユーザー。記録されていない場合はnil
+	requester *User
+	// 申請のメッセージが投稿されたチャンネルとメッセージ
+	originChannelID string
+	originMessageID string
+	createdAt       time.Time
+}
+
+type RemovalOption func(*Removal)
+
+func WithRemovalStatus(status RemovalStatus) RemovalOption {
+	return func(r *Removal) {
+		r.status = status
+	}
+}
+
+func WithRemovalReason(reason string) RemovalOption {
+	return func(r *Removal) {
+		r.reason = reason
+	}
+}
+
+func WithRemovalOrigin(requester *User, channelID, messageID string) RemovalOption {
+	return func(r *Removal) {
+		r.requester = requester
+		r.originChannelID = channelID
+		r.originMessageID = messageID
+	}
+}
+
+func WithRemovalCreatedAt(createdAt time.Time) RemovalOption {
+	return func(r *Removal) {
+		r.createdAt = createdAt
+	}
+}
+
+func NewRemoval(messageID, gitHubID string, opts ...RemovalOption) *Removal {
+	r := &Removal{
+		messageID: messageID,
+		gitHubID:  gitHubID,
+		status:    RemovalStatusPending,
+	}
+	for _, opt := range opts {
+		opt(r)
+	}
+
+	return r
+}
+
+func (r *Removal) MessageID() string {
+	return r.messageID
+}
+
+func (r *Removal) GitHubID() string {
+	return r.gitHubID
+}
+
+func (r *Removal) Status() RemovalStatus {
+	return r.status
+}
+
+func (r *Removal) Reason() string {
+	return r.reason
+}
+
+func (r *Removal) Requester() *User {
+	return r.requester
+}
+
+func (r *Removal) OriginChannelID() string {
+	return r.originChannelID
+}
+
+func (r *Removal) OriginMessageID() string {
+	return r.originMessageID
+}
+
+func (r *Removal) CreatedAt() time.Time {
+	return r.createdAt
+}
@@ -0,0 +1,50 @@
+package model
+
+type SendResultStatus string
+
+const (
+	SendResultStatusSent           SendResultStatus = "sent"
+	SendResultStatusAlreadyMember  SendResultStatus = "already_member"
+	SendResultStatusAlreadyInvited SendResultStatus = "already_invited"
+	SendResultStatusUserNotFound   SendResultStatus = "user_not_found"
+	SendResultStatusRateLimited    SendResultStatus = "rate_limited"
+	SendResultStatusError          SendResultStatus = "error"
+)
+
+// SendResult は、1人分の招待を送信した結果
+type SendResult struct {
+	invitation *Invitation
+	status     SendResultStatus
+	// 送信に失敗した場合のエラー。失敗していない場合はnil
+	err error
+}
+
+func NewSendResult(invitation *Invitation, status SendResultStatus, err error) *SendResult {
+	return &SendResult{
+		invitation: invitation,
+		status:     status,
+		err:        err,
+	}
+}
+
+func (r *SendResult) Invitation() *Invitation {
+	return r.invitation
+}
+
+func (r *SendResult) Status() SendResultStatus {
+	return r.status
+}
+
+func (r *SendResult) Err() error {
+	return r.err
+}
+
+// Failed は、送信に失敗し、送り直す必要があるかを返す
+func (r *SendResult) Failed() bool {
+	switch r.status {
+	case SendResultStatusSent, SendResultStatusAlreadyMember, SendResultStatusAlreadyInvited:
+		return false
+	default:
+		return true
+	}
+}
@@ -0,0 +1,51 @@
+package model
+
+type TraqUserState string
+
+const (
+	TraqUserStateActive TraqUserState = "active"
+	// 退部などで利用できなくなったアカウント
+	TraqUserStateDeactivated TraqUserState = "deactivated"
+	// 一時停止されたアカウント
+	TraqUserStateSuspended TraqUserState = "suspended"
+)
+
+// TraqUser は、traQのユーザーの詳しい情報
+type TraqUser struct {
+	id          string
+	name        string
+	displayName string
+	bot         bool
+	state       TraqUserState
+}
+
+func NewTraqUser(id, name, displayName string, bot bool, state TraqUserState) *TraqUser {
+	return &TraqUser{
+		id:          id,
+		name:        name,
+		displayName: displayName,
+		bot:         bot,
+		state:       state,
+	}
+}
+
+func (u *TraqUser) ID() string {
+	return u.id
+}
+
+// Name は、@を除いたtraQ ID
+func (u *TraqUser) Name() string {
+	return u.name
+}
+
+func (u *TraqUser) DisplayName() string {
+	return u.displayName
+}
+
+func (u *TraqUser) Bot() bool {
+	return u.bot
+}
+
+func (u *TraqUser) State() TraqUserState {
+	return u.state
+}
@@ -0,0 +1,69 @@
+package policy
+
+import (
+	"context"
+	"strings"
+
+	"github.com/traPtitech/traq-ws-bot/payload"
+)
+
+var _ Policy = &AllOf{}
+
+// AllOf は、複数の条件を組み合わせる。
+// 1つでも却下なら却下し、判定に関わらないもの以外が全て承認なら承認する
+type AllOf struct {
+	policies []Policy
+}
+
+func NewAllOf(policies ...Policy) *AllOf {
+	return &AllOf{policies: policies}
+}
+
+func (a *AllOf) Evaluate(ctx context.Context, in *Input) (*Result, error) {
+	results := make([]*Result, 0, len(a.policies))
+	for _, p := range a.policies {
+		result, err := p.Evaluate(ctx, in)
+		if err != nil {
+			return nil, err
+		}
+		results = append(results, result)
+	}
+
+	// 判定中の状況を表示できるように、却下が決まっても全ての条件のスタンプを集める
+	merged := &Result{Decision: Pending}
+	acceptVotes := make([]payload.MessageStamp, 0)
+	accepted, pending := false, false
+	for _, result := range results {
+		merged.Accepts = mergeStamps(merged.Accepts, result.Accepts)
+		merged.Rejects = mergeStamps(merged.Rejects, result.Rejects)
+
+		switch result.Decision {
+		case Reject:
+			if merged.Decision != Reject {
+				merged.Decision = Reject
+				merged.Votes = result.Votes
+			}
+		case Accept:
+			accepted = true
+			acceptVotes = append(acceptVotes, result.Votes...)
+		case Pending:
+			pending = true
+		}
+	}
+
+	if merged.Decision != Reject && accepted && !pending {
+		merged.Decision = Accept
+		merged.Votes = mergeStamps(acceptVotes)
+	}
+
+	return merged, nil
+}
+
+func (a *AllOf) Description() string {
+	descriptions := make([]string, 0, len(a.policies))
+	for _, p := range a.policies {
+		descriptions = append(descriptions, p.Description())
+	}
+
+	return strings.Join(descriptions, "、かつ")
+}
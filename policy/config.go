@@ -0,0 +1,101 @@
+package policy
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+)
+
+// Config は、判定の条件をJSONで設定するための形式。
+// GitHubのOrganizationのロールごとに条件を変える場合は、ロールに対応するtraQのグループを指定する
+//
+//	{"type": "all_of", "policies": [
+//	  {"type": "threshold", "group_id": "...", "group_name": "GitHub_org_Admin", "accept": 2, "reject": 1},
+//	  {"type": "veto", "group_id": "...", "group_name": "GitHub_org_Owner"}
+//	]}
+type Config struct {
+	Type      string `json:"type"`
+	GroupID   string `json:"group_id"`
+	GroupName string `json:"group_name"`
+	// threshold の閾値
+	Accept int `json:"accept"`
+	Reject int `json:"reject"`
+	// veto のスタンプ。省略すると却下スタンプ
+	StampID string `json:"stamp_id"`
+	// veto のスタンプの名前。説明に使うので、stamp_id を指定した場合は必須
+	StampName string `json:"stamp_name"`
+	// all_of で組み合わせる条件
+	Policies []Config `json:"policies"`
+	// exclude_requester で判定する条件
+	Policy *Config `json:"policy"`
+}
+
+// Stamps は、Configで省略されたスタンプに使う値
+type Stamps struct {
+	AcceptStampID string
+	RejectStampID string
+}
+
+// Parse は、JSONの設定から判定の条件を作る
+func Parse(data []byte, groups GroupMemberGetter, stamps Stamps) (Policy, error) {
+	var conf Config
+	if err := json.Unmarshal(data, &conf); err != nil {
+		return nil, fmt.Errorf("failed to parse policy config: %w", err)
+	}
+
+	return conf.Build(groups, stamps)
+}
+
+func (c *Config) Build(groups GroupMemberGetter, stamps Stamps) (Policy, error) {
+	switch c.Type {
+	case "threshold":
+		if c.GroupID == "" {
+			return nil, errors.New("threshold: group_id is required")
+		}
+		if c.Accept <= 0 || c.Reject <= 0 {
+			return nil, errors.New("threshold: accept and reject must be positive")
+		}
+
+		return NewThreshold(groups, c.GroupID, c.GroupName, stamps.AcceptStampID, c.Accept, stamps.RejectStampID, c.Reject), nil
+	case "veto":
+		if c.GroupID == "" {
+			return nil, errors.New("veto: group_id is required")
+		}
+		if c.StampID == "" {
+			return NewVeto(groups, c.GroupID, c.GroupName, stamps.RejectStampID, ""), nil
+		}
+		if c.StampName == "" {
+			return nil, errors.New("veto: stamp_name is required when stamp_id is set")
+		}
+
+		return NewVeto(groups, c.GroupID, c.GroupName, c.StampID, c.StampName), nil
+	case "all_of":
+		if len(c.Policies) == 0 {
+			return nil, errors.New("all_of: policies is required")
+		}
+
+		policies := make([]Policy, 0, len(c.Policies))
+		for _, pc := range c.Policies {
+			p, err := pc.Build(groups, stamps)
+			if err != nil {
+				return nil, fmt.Errorf("all_of: %w", err)
+			}
+			policies = append(policies, p)
+		}
+
+		return NewAllOf(policies...), nil
+	case "exclude_requester":
+		if c.Policy == nil {
+			return nil, errors.New("exclude_requester: policy is required")
+		}
+
+		p, err := c.Policy.Build(groups, stamps)
+		if err != nil {
+			return nil, fmt.Errorf("exclude_requester: %w", err)
+		}
+
+		return NewExcludeRequester(p), nil
+	default:
+		return nil, fmt.Errorf("unknown policy type: %q", c.Type)
+	}
+}
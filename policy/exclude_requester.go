@@ -0,0 +1,35 @@
+package policy
+
+import (
+	"context"
+	"slices"
+
+	"github.com/traPtitech/traq-ws-bot/payload"
+)
+
+var _ Policy = &ExcludeRequester{}
+
+// ExcludeRequester は、申請した本人のスタンプを除いてから判定する
+type ExcludeRequester struct {
+	policy Policy
+}
+
+func NewExcludeRequester(policy Policy) *ExcludeRequester {
+	return &ExcludeRequester{policy: policy}
+}
+
+func (e *ExcludeRequester) Evaluate(ctx context.Context, in *Input) (*Result, error) {
+	if in.RequesterID == "" {
+		return e.policy.Evaluate(ctx, in)
+	}
+
+	stamps := slices.DeleteFunc(slices.Clone(in.Stamps), func(stamp payload.MessageStamp) bool {
+		return stamp.UserID == in.RequesterID
+	})
+
+	return e.policy.Evaluate(ctx, &Input{Stamps: stamps, RequesterID: in.RequesterID})
+}
+
+func (e *ExcludeRequester) Description() string {
+	return e.policy.Description() + " (申請者本人のスタンプは数えない)"
+}
@@ -0,0 +1,74 @@
+// Package policy は、招待の申請を承認するか却下するかを、スタンプから判定する方法を定義する
+package policy
+
+import (
+	"cmp"
+	"context"
+	"slices"
+
+	"github.com/traPtitech/traq-ws-bot/payload"
+)
+
+type Decision int
+
+const (
+	// Pending は、まだ判定できないことを表す
+	Pending Decision = iota
+	// Abstain は、判定に関わらないことを表す。AllOfで他の判定に従うために使う
+	Abstain
+	Accept
+	Reject
+)
+
+type Input struct {
+	Stamps []payload.MessageStamp
+	// 申請したユーザーのUUID。分からない場合は空文字列
+	RequesterID string
+}
+
+type Result struct {
+	Decision Decision
+	// 判定に数えたスタンプ
+	Votes []payload.MessageStamp
+	// 条件が数える承認と却下のスタンプ。判定中の状況を表示するために、判定できない場合も返す
+	Accepts []payload.MessageStamp
+	Rejects []payload.MessageStamp
+}
+
+type Policy interface {
+	Evaluate(ctx context.Context, in *Input) (*Result, error)
+	// Description は、判定の条件をヘルプに表示するための説明を返す
+	Description() string
+}
+
+// GroupMemberGetter は、traQのグループのメンバーを取得する。service.Traqが満たす
+type GroupMemberGetter interface {
+	GetGroupMemberIDs(ctx context.Context, groupID string) ([]string, error)
+}
+
+// sortedStamps は、スタンプを押された順に並べ替えたコピーを返す
+func sortedStamps(stamps []payload.MessageStamp) []payload.MessageStamp {
+	sorted := slices.Clone(stamps)
+	slices.SortStableFunc(sorted, func(i, j payload.MessageStamp) int {
+		return cmp.Compare(i.CreatedAt.UnixNano(), j.CreatedAt.UnixNano())
+	})
+
+	return sorted
+}
+
+// mergeStamps は、同じ人が押した同じスタンプを1つにまとめて、押された順に並べる
+func mergeStamps(stamps ...[]payload.MessageStamp) []payload.MessageStamp {
+	merged := make([]payload.MessageStamp, 0)
+	for _, s := range stamps {
+		for _, stamp := range s {
+			if slices.ContainsFunc(merged, func(m payload.MessageStamp) bool {
+				return m.UserID == stamp.UserID && m.StampID == stamp.StampID
+			}) {
+				continue
+			}
+			merged = append(merged, stamp)
+		}
+	}
+
+	return sortedStamps(merged)
+}
@@ -0,0 +1,250 @@
+package policy
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/traP-jp/members_bot/service/mock"
+	"github.com/traPtitech/traq-ws-bot/payload"
+)
+
+var (
+	adminGroupID  = uuid.NewString()
+	ownerGroupID  = uuid.NewString()
+	acceptStampID = uuid.NewString()
+	rejectStampID = uuid.NewString()
+	adminIDs      = []string{uuid.NewString(), uuid.NewString(), uuid.NewString()}
+	ownerIDs      = []string{uuid.NewString()}
+	otherUserID   = uuid.NewString()
+)
+
+func newGroupsMock() *mock.TraqMock {
+	return &mock.TraqMock{
+		GetGroupMemberIDsFunc: func(ctx context.Context, groupID string) ([]string, error) {
+			switch groupID {
+			case adminGroupID:
+				return adminIDs, nil
+			case ownerGroupID:
+				return ownerIDs, nil
+			}
+			return nil, nil
+		},
+	}
+}
+
+func stamps(pairs ...string) []payload.MessageStamp {
+	now := time.Now()
+	stamps := make([]payload.MessageStamp, 0, len(pairs)/2)
+	for i := 0; i < len(pairs); i += 2 {
+		stamps = append(stamps, payload.MessageStamp{
+			UserID:    pairs[i],
+			StampID:   pairs[i+1],
+			Count:     1,
+			CreatedAt: now.Add(time.Duration(i) * time.Second),
+		})
+	}
+
+	return stamps
+}
+
+func TestThreshold(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		in          *Input
+		decision    Decision
+		voteCount   int
+		acceptCount int
+		rejectCount int
+	}{
+		"承認が閾値に達したら承認": {
+			in:          &Input{Stamps: stamps(adminIDs[0], acceptStampID, adminIDs[1], acceptStampID)},
+			decision:    Accept,
+			voteCount:   2,
+			acceptCount: 2,
+		},
+		"却下が閾値に達したら却下": {
+			in:          &Input{Stamps: stamps(adminIDs[0], acceptStampID, adminIDs[1], rejectStampID)},
+			decision:    Reject,
+			voteCount:   2,
+			acceptCount: 1,
+			rejectCount: 1,
+		},
+		"グループ外のスタンプは数えない": {
+			in:          &Input{Stamps: stamps(adminIDs[0], acceptStampID, otherUserID, acceptStampID)},
+			decision:    Pending,
+			voteCount:   1,
+			acceptCount: 1,
+		},
+		"判定した後のスタンプは票にしないが数える": {
+			in:          &Input{Stamps: stamps(adminIDs[0], rejectStampID, adminIDs[1], acceptStampID, adminIDs[2], acceptStampID)},
+			decision:    Reject,
+			voteCount:   1,
+			acceptCount: 2,
+			rejectCount: 1,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			p := NewThreshold(newGroupsMock(), adminGroupID, "GitHub_org_Admin", acceptStampID, 2, rejectStampID, 1)
+			result, err := p.Evaluate(context.Background(), test.in)
+			require.NoError(t, err)
+
+			assert.Equal(t, test.decision, result.Decision)
+			assert.Len(t, result.Votes, test.voteCount)
+			assert.Len(t, result.Accepts, test.acceptCount)
+			assert.Len(t, result.Rejects, test.rejectCount)
+		})
+	}
+}
+
+func TestAllOf(t *testing.T) {
+	t.Parallel()
+
+	p := NewAllOf(
+		NewThreshold(newGroupsMock(), adminGroupID, "GitHub_org_Admin", acceptStampID, 2, rejectStampID, 2),
+		NewVeto(newGroupsMock(), adminGroupID, "GitHub_org_Admin", rejectStampID, ""),
+	)
+	in := &Input{Stamps: stamps(adminIDs[0], acceptStampID, adminIDs[1], rejectStampID)}
+	result, err := p.Evaluate(context.Background(), in)
+	require.NoError(t, err)
+
+	assert.Equal(t, Reject, result.Decision)
+	assert.Equal(t, in.Stamps[1:], result.Votes)
+	// 両方の条件が数えたスタンプは1つにまとめる
+	assert.Len(t, result.Accepts, 1)
+	assert.Len(t, result.Rejects, 1)
+}
+
+func TestAllOfOverlappingGroups(t *testing.T) {
+	t.Parallel()
+
+	// adminのグループとownerのグループの両方に所属している人がいる
+	groups := &mock.TraqMock{
+		GetGroupMemberIDsFunc: func(ctx context.Context, groupID string) ([]string, error) {
+			if groupID == ownerGroupID {
+				return adminIDs[:1], nil
+			}
+			return adminIDs, nil
+		},
+	}
+	p := NewAllOf(
+		NewThreshold(groups, adminGroupID, "GitHub_org_Admin", acceptStampID, 1, rejectStampID, 1),
+		NewThreshold(groups, ownerGroupID, "GitHub_org_Owner", acceptStampID, 1, rejectStampID, 1),
+	)
+	in := &Input{Stamps: stamps(adminIDs[0], acceptStampID)}
+	result, err := p.Evaluate(context.Background(), in)
+	require.NoError(t, err)
+
+	assert.Equal(t, Accept, result.Decision)
+	// 両方の条件で数えたスタンプも、票としては1つにまとめる
+	assert.Equal(t, in.Stamps, result.Votes)
+	assert.Len(t, result.Accepts, 1)
+}
+
+func TestParse(t *testing.T) {
+	t.Parallel()
+
+	config := `{"type": "exclude_requester", "policy": {"type": "all_of", "policies": [
+		{"type": "threshold", "group_id": "` + adminGroupID + `", "group_name": "GitHub_org_Admin", "accept": 2, "reject": 2},
+		{"type": "veto", "group_id": "` + ownerGroupID + `", "group_name": "GitHub_org_Owner"}
+	]}}`
+
+	tests := map[string]struct {
+		in          *Input
+		decision    Decision
+		acceptCount int
+		rejectCount int
+	}{
+		"adminの承認が閾値に達したら承認": {
+			in:          &Input{Stamps: stamps(adminIDs[0], acceptStampID, adminIDs[1], acceptStampID)},
+			decision:    Accept,
+			acceptCount: 2,
+		},
+		"ownerが却下したら承認より優先して却下": {
+			in:          &Input{Stamps: stamps(adminIDs[0], acceptStampID, adminIDs[1], acceptStampID, ownerIDs[0], rejectStampID)},
+			decision:    Reject,
+			acceptCount: 2,
+			rejectCount: 1,
+		},
+		"申請者のスタンプは数えない": {
+			in:          &Input{Stamps: stamps(adminIDs[0], acceptStampID, adminIDs[1], acceptStampID), RequesterID: adminIDs[0]},
+			decision:    Pending,
+			acceptCount: 1,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			p, err := Parse([]byte(config), newGroupsMock(), Stamps{AcceptStampID: acceptStampID, RejectStampID: rejectStampID})
+			require.NoError(t, err)
+
+			result, err := p.Evaluate(context.Background(), test.in)
+			require.NoError(t, err)
+
+			assert.Equal(t, test.decision, result.Decision)
+			assert.Len(t, result.Accepts, test.acceptCount)
+			assert.Len(t, result.Rejects, test.rejectCount)
+		})
+	}
+}
+
+func TestParseInvalid(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]string{
+		"不明な種類":                   `{"type": "unknown"}`,
+		"閾値がない":                   `{"type": "threshold", "group_id": "a"}`,
+		"all_ofの条件がない":            `{"type": "all_of"}`,
+		"JSONではない":                `threshold`,
+		"exclude_requesterの条件がない": `{"type": "exclude_requester"}`,
+		"vetoのスタンプの名前がない":         `{"type": "veto", "group_id": "a", "stamp_id": "b"}`,
+	}
+
+	for name, config := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			_, err := Parse([]byte(config), newGroupsMock(), Stamps{})
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestParseDescription(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		config      string
+		description string
+	}{
+		"vetoのスタンプを省略すると却下スタンプ": {
+			config:      `{"type": "veto", "group_id": "` + ownerGroupID + `", "group_name": "GitHub_org_Owner"}`,
+			description: "@GitHub_org_Owner のメンバーが1人でも却下スタンプを押したら却下",
+		},
+		"vetoのスタンプを指定するとその名前で説明する": {
+			config:      `{"type": "veto", "group_id": "` + ownerGroupID + `", "group_name": "GitHub_org_Owner", "stamp_id": "stop", "stamp_name": "no_entry"}`,
+			description: "@GitHub_org_Owner のメンバーが1人でも:no_entry:を押したら却下",
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			p, err := Parse([]byte(test.config), newGroupsMock(), Stamps{AcceptStampID: acceptStampID, RejectStampID: rejectStampID})
+			require.NoError(t, err)
+
+			assert.Equal(t, test.description, p.Description())
+		})
+	}
+}
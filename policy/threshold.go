@@ -0,0 +1,72 @@
+package policy
+
+import (
+	"context"
+	"fmt"
+	"slices"
+)
+
+var _ Policy = &Threshold{}
+
+// Threshold は、グループのメンバーのスタンプを押された順に数え、
+// 先に閾値に達した方に判定する
+type Threshold struct {
+	groups          GroupMemberGetter
+	groupID         string
+	groupName       string
+	acceptStampID   string
+	acceptThreshold int
+	rejectStampID   string
+	rejectThreshold int
+}
+
+func NewThreshold(groups GroupMemberGetter, groupID, groupName, acceptStampID string, acceptThreshold int, rejectStampID string, rejectThreshold int) *Threshold {
+	return &Threshold{
+		groups:          groups,
+		groupID:         groupID,
+		groupName:       groupName,
+		acceptStampID:   acceptStampID,
+		acceptThreshold: acceptThreshold,
+		rejectStampID:   rejectStampID,
+		rejectThreshold: rejectThreshold,
+	}
+}
+
+func (t *Threshold) Evaluate(ctx context.Context, in *Input) (*Result, error) {
+	memberIDs, err := t.groups.GetGroupMemberIDs(ctx, t.groupID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get group member IDs: %w", err)
+	}
+
+	result := &Result{Decision: Pending}
+
+	for _, stamp := range sortedStamps(in.Stamps) {
+		if !slices.Contains(memberIDs, stamp.UserID) {
+			continue
+		}
+		switch stamp.StampID {
+		case t.acceptStampID:
+			result.Accepts = append(result.Accepts, stamp)
+		case t.rejectStampID:
+			result.Rejects = append(result.Rejects, stamp)
+		default:
+			continue
+		}
+		if result.Decision != Pending {
+			continue // 判定した後に押されたスタンプは、判定に数えない
+		}
+		result.Votes = append(result.Votes, stamp)
+
+		if len(result.Accepts) >= t.acceptThreshold {
+			result.Decision = Accept
+		} else if len(result.Rejects) >= t.rejectThreshold {
+			result.Decision = Reject
+		}
+	}
+
+	return result, nil
+}
+
+func (t *Threshold) Description() string {
+	return fmt.Sprintf("@%s のメンバーの承認スタンプが%d個で承認、却下スタンプが%d個で却下", t.groupName, t.acceptThreshold, t.rejectThreshold)
+}
@@ -0,0 +1,62 @@
+package policy
+
+import (
+	"context"
+	"fmt"
+	"slices"
+
+	"github.com/traPtitech/traq-ws-bot/payload"
+)
+
+var _ Policy = &Veto{}
+
+// Veto は、グループのメンバーが1人でもスタンプを押したら却下する。
+// それ以外の場合は判定に関わらない
+type Veto struct {
+	groups    GroupMemberGetter
+	groupID   string
+	groupName string
+	stampID   string
+	// 説明に使うスタンプの名前。空文字列の場合は却下スタンプとして説明する
+	stampName string
+}
+
+func NewVeto(groups GroupMemberGetter, groupID, groupName, stampID, stampName string) *Veto {
+	return &Veto{
+		groups:    groups,
+		groupID:   groupID,
+		groupName: groupName,
+		stampID:   stampID,
+		stampName: stampName,
+	}
+}
+
+func (v *Veto) Evaluate(ctx context.Context, in *Input) (*Result, error) {
+	memberIDs, err := v.groups.GetGroupMemberIDs(ctx, v.groupID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get group member IDs: %w", err)
+	}
+
+	result := &Result{Decision: Abstain}
+	for _, stamp := range sortedStamps(in.Stamps) {
+		if stamp.StampID != v.stampID || !slices.Contains(memberIDs, stamp.UserID) {
+			continue
+		}
+		if result.Decision == Abstain {
+			result.Decision = Reject
+			result.Votes = []payload.MessageStamp{stamp}
+		}
+		result.Rejects = append(result.Rejects, stamp)
+	}
+
+	return result, nil
+}
+
+func (v *Veto) Description() string {
+	stamp := "却下スタンプ"
+	if v.stampName != "" {
+		stamp = fmt.Sprintf(":%s:", v.stampName)
+	}
+
+	return fmt.Sprintf("@%s のメンバーが1人でも%sを押したら却下", v.groupName, stamp)
+}
@@ -0,0 +1,21 @@
+package repository
+
+//go:generate go run github.com/matryer/moq -pkg mock -out mock/${GOFILE} . AccountLink
+
+import (
+	"context"
+
+	"github.com/traP-jp/members_bot/model"
+)
+
+type AccountLink interface {
+	// SaveAccountLink は、traQとGitHubのアカウントの対応を記録する。
+	// 同じGitHubユーザーIDの対応が既にある場合は、上書きする
+	SaveAccountLink(ctx context.Context, link *model.AccountLink) error
+	// UpdateAccountLinkGitHubLogin は、GitHubのユーザーIDがgitHubUserIDの対応の、GitHubのユーザー名を更新する
+	UpdateAccountLinkGitHubLogin(ctx context.Context, gitHubUserID int64, login string) error
+	// GetAccountLinkByGitHubLogin は、GitHubのユーザー名の対応のうち、最後に記録されたものを返す。ない場合は ErrRecordNotFound を返す
+	GetAccountLinkByGitHubLogin(ctx context.Context, login string) (*model.AccountLink, error)
+	// GetAccountLinksByTraqName は、traQのユーザー名の対応を、新しい順に返す
+	GetAccountLinksByTraqName(ctx context.Context, traqName string) ([]*model.AccountLink, error)
+}
@@ -0,0 +1,15 @@
+package repository
+
+//go:generate go run github.com/matryer/moq -pkg mock -out mock/${GOFILE} . Decision
+
+import (
+	"context"
+
+	"github.com/traP-jp/members_bot/model"
+)
+
+type Decision interface {
+	CreateDecision(ctx context.Context, decision *model.Decision) error
+	// GetDecision は、kindの申請のメッセージの判定を返す
+	GetDecision(ctx context.Context, kind model.DecisionKind, messageID string) (*model.Decision, error)
+}
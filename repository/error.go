@@ -3,5 +3,6 @@ package repository
 import "errors"
 
 var (
-	ErrRecordNotFound = errors.New("record not found")
+	ErrRecordNotFound          = errors.New("record not found")
+	ErrInvalidStatusTransition = errors.New("invalid status transition")
 )
@@ -0,0 +1,125 @@
+package impl
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/traP-jp/members_bot/model"
+	"github.com/traP-jp/members_bot/repository"
+	"github.com/traP-jp/members_bot/repository/impl/schema"
+	"github.com/uptrace/bun"
+)
+
+var _ repository.AccountLink = &AccountLink{}
+
+type AccountLink struct {
+	db *bun.DB
+}
+
+func NewAccountLink(db *bun.DB) *AccountLink {
+	return &AccountLink{db: db}
+}
+
+// SaveAccountLink は、GitHubのユーザーIDで対応を上書きする。
+// ユーザーIDが分からない場合は、同じユーザー名でユーザーIDが分からない対応を上書きする
+func (a *AccountLink) SaveAccountLink(ctx context.Context, link *model.AccountLink) error {
+	linkSchema := schema.AccountLink{
+		TraqUserID:   link.TraqUserID(),
+		TraqName:     strings.TrimPrefix(link.TraqName(), "@"),
+		GitHubUserID: link.GitHubUserID(),
+		GitHubLogin:  link.GitHubLogin(),
+		Source:       string(link.Source()),
+		LinkedAt:     link.LinkedAt(),
+	}
+
+	return runInTx(ctx, a.db, func(ctx context.Context, tx bun.IDB) error {
+		// ユーザーIDが記録される前の対応は、同じユーザー名のものを引き継ぐ
+		var current schema.AccountLink
+		q := tx.NewSelect().Model(&current).For("UPDATE")
+		if linkSchema.GitHubUserID != 0 {
+			q = q.Where("git_hub_user_id = ?", linkSchema.GitHubUserID).
+				WhereOr("git_hub_login = ? AND git_hub_user_id IS NULL", linkSchema.GitHubLogin).
+				OrderExpr("git_hub_user_id IS NULL")
+		} else {
+			q = q.Where("git_hub_login = ? AND git_hub_user_id IS NULL", linkSchema.GitHubLogin)
+		}
+		err := q.Limit(1).Scan(ctx)
+		if errors.Is(err, sql.ErrNoRows) {
+			_, err := tx.NewInsert().Model(&linkSchema).Exec(ctx)
+			if err != nil {
+				return fmt.Errorf("failed to create account link: %w", err)
+			}
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("failed to get account link: %w", err)
+		}
+
+		linkSchema.ID = current.ID
+		_, err = tx.NewUpdate().Model(&linkSchema).ExcludeColumn("id").WherePK().Exec(ctx)
+		if err != nil {
+			return fmt.Errorf("failed to update account link: %w", err)
+		}
+
+		return nil
+	})
+}
+
+func (a *AccountLink) UpdateAccountLinkGitHubLogin(ctx context.Context, gitHubUserID int64, login string) error {
+	_, err := a.db.NewUpdate().
+		Model((*schema.AccountLink)(nil)).
+		Set("git_hub_login = ?", login).
+		Where("git_hub_user_id = ?", gitHubUserID).
+		Exec(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to update account link GitHub login: %w", err)
+	}
+
+	return nil
+}
+
+func (a *AccountLink) GetAccountLinkByGitHubLogin(ctx context.Context, login string) (*model.AccountLink, error) {
+	var link schema.AccountLink
+	// ユーザー名が変わって他の人に使われた場合は、同じユーザー名の対応が複数あるので、最後に記録されたものを返す
+	err := a.db.NewSelect().
+		Model(&link).
+		Where("git_hub_login = ?", login).
+		Order("linked_at DESC", "id DESC").
+		Limit(1).
+		Scan(ctx)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, repository.ErrRecordNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get account link: %w", err)
+	}
+
+	return toAccountLinkModel(&link), nil
+}
+
+func (a *AccountLink) GetAccountLinksByTraqName(ctx context.Context, traqName string) ([]*model.AccountLink, error) {
+	var links []schema.AccountLink
+	err := a.db.NewSelect().
+		Model(&links).
+		Where("traq_name = ?", strings.TrimPrefix(traqName, "@")).
+		Order("linked_at DESC", "id DESC").
+		Scan(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get account links: %w", err)
+	}
+
+	linksModel := make([]*model.AccountLink, 0, len(links))
+	for _, link := range links {
+		linksModel = append(linksModel, toAccountLinkModel(&link))
+	}
+
+	return linksModel, nil
+}
+
+func toAccountLinkModel(link *schema.AccountLink) *model.AccountLink {
+	return model.NewAccountLink(link.TraqUserID, link.TraqName, link.GitHubUserID, link.GitHubLogin,
+		model.AccountLinkSource(link.Source), link.LinkedAt)
+}
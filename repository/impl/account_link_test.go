@@ -0,0 +1,161 @@
+package impl
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/traP-jp/members_bot/model"
+	"github.com/traP-jp/members_bot/repository"
+	"github.com/traP-jp/members_bot/repository/impl/schema"
+)
+
+func TestSaveAccountLink(t *testing.T) {
+	ctx := context.Background()
+
+	t.Cleanup(func() {
+		_, err := testDB.NewTruncateTable().Model(&schema.AccountLink{}).Exec(ctx)
+		require.NoError(t, err)
+	})
+
+	ar := NewAccountLink(testDB)
+
+	traqUserID := uuid.NewString()
+	linkedAt := time.Now().Add(-time.Hour).Truncate(time.Second)
+
+	t.Run("新しく記録する", func(t *testing.T) {
+		err := ar.SaveAccountLink(ctx, model.NewAccountLink(traqUserID, "@ikura-hamu", 12345, "ikura-hamu", model.AccountLinkSourceInvitation, linkedAt))
+		require.NoError(t, err)
+
+		link, err := ar.GetAccountLinkByGitHubLogin(ctx, "ikura-hamu")
+		require.NoError(t, err)
+		assert.Equal(t, traqUserID, link.TraqUserID())
+		assert.Equal(t, "ikura-hamu", link.TraqName())
+		assert.Equal(t, int64(12345), link.GitHubUserID())
+		assert.Equal(t, "ikura-hamu", link.GitHubLogin())
+		assert.Equal(t, model.AccountLinkSourceInvitation, link.Source())
+		assert.WithinDuration(t, linkedAt, link.LinkedAt(), time.Second)
+	})
+
+	t.Run("同じユーザーIDは、ユーザー名が変わっても上書きする", func(t *testing.T) {
+		err := ar.SaveAccountLink(ctx, model.NewAccountLink(traqUserID, "ikura-hamu2", 12345, "ikura-hamu-new", model.AccountLinkSourceInvitation, time.Now()))
+		require.NoError(t, err)
+
+		link, err := ar.GetAccountLinkByGitHubLogin(ctx, "ikura-hamu-new")
+		require.NoError(t, err)
+		assert.Equal(t, "ikura-hamu2", link.TraqName())
+		assert.Equal(t, int64(12345), link.GitHubUserID())
+
+		count, err := ar.db.NewSelect().Model((*schema.AccountLink)(nil)).Count(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, 1, count)
+	})
+
+	t.Run("使われなくなったユーザー名を別の人が使っても、上書きしない", func(t *testing.T) {
+		err := ar.SaveAccountLink(ctx, model.NewAccountLink("", "pikachu", 67890, "ikura-hamu", model.AccountLinkSourceInvitation, time.Now()))
+		require.NoError(t, err)
+
+		link, err := ar.GetAccountLinkByGitHubLogin(ctx, "ikura-hamu")
+		require.NoError(t, err)
+		assert.Equal(t, "pikachu", link.TraqName())
+		assert.Equal(t, int64(67890), link.GitHubUserID())
+
+		link, err = ar.GetAccountLinkByGitHubLogin(ctx, "ikura-hamu-new")
+		require.NoError(t, err)
+		assert.Equal(t, "ikura-hamu2", link.TraqName())
+	})
+
+	t.Run("ユーザーIDが分からない対応は、同じユーザー名のものを上書きする", func(t *testing.T) {
+		for _, traqName := range []string{"H1rono", "H1rono2"} {
+			err := ar.SaveAccountLink(ctx, model.NewAccountLink("", traqName, 0, "H1rono", model.AccountLinkSourceInvitation, time.Now()))
+			require.NoError(t, err)
+		}
+
+		link, err := ar.GetAccountLinkByGitHubLogin(ctx, "H1rono")
+		require.NoError(t, err)
+		assert.Equal(t, "H1rono2", link.TraqName())
+		assert.Zero(t, link.GitHubUserID())
+
+		count, err := ar.db.NewSelect().Model((*schema.AccountLink)(nil)).Where("git_hub_login = ?", "H1rono").Count(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, 1, count)
+	})
+
+	t.Run("ユーザーIDが分かったら、ユーザーIDが分からない対応を引き継ぐ", func(t *testing.T) {
+		err := ar.SaveAccountLink(ctx, model.NewAccountLink("", "H1rono", 11111, "H1rono", model.AccountLinkSourceInvitation, time.Now()))
+		require.NoError(t, err)
+
+		link, err := ar.GetAccountLinkByGitHubLogin(ctx, "H1rono")
+		require.NoError(t, err)
+		assert.Equal(t, "H1rono", link.TraqName())
+		assert.Equal(t, int64(11111), link.GitHubUserID())
+
+		count, err := ar.db.NewSelect().Model((*schema.AccountLink)(nil)).Where("git_hub_login = ?", "H1rono").Count(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, 1, count)
+	})
+}
+
+func TestGetAccountLinkByGitHubLogin(t *testing.T) {
+	ar := NewAccountLink(testDB)
+
+	_, err := ar.GetAccountLinkByGitHubLogin(context.Background(), "not-found")
+	assert.ErrorIs(t, err, repository.ErrRecordNotFound)
+}
+
+func TestGetAccountLinksByTraqName(t *testing.T) {
+	ctx := context.Background()
+
+	t.Cleanup(func() {
+		_, err := testDB.NewTruncateTable().Model(&schema.AccountLink{}).Exec(ctx)
+		require.NoError(t, err)
+	})
+
+	ar := NewAccountLink(testDB)
+
+	fixture := []schema.AccountLink{
+		{TraqName: "ikura-hamu", GitHubLogin: "ikura-hamu-old", Source: "invitation", LinkedAt: time.Now().Add(-time.Hour)},
+		{TraqName: "ikura-hamu", GitHubLogin: "ikura-hamu", Source: "invitation", LinkedAt: time.Now()},
+		{TraqName: "H1rono", GitHubLogin: "H1rono", Source: "invitation", LinkedAt: time.Now()},
+	}
+	_, err := ar.db.NewInsert().Model(&fixture).Exec(ctx)
+	require.NoError(t, err)
+
+	links, err := ar.GetAccountLinksByTraqName(ctx, "@ikura-hamu")
+	require.NoError(t, err)
+	require.Len(t, links, 2)
+	assert.Equal(t, "ikura-hamu", links[0].GitHubLogin())
+	assert.Equal(t, "ikura-hamu-old", links[1].GitHubLogin())
+}
+
+func TestUpdateAccountLinkGitHubLogin(t *testing.T) {
+	ctx := context.Background()
+
+	t.Cleanup(func() {
+		_, err := testDB.NewTruncateTable().Model(&schema.AccountLink{}).Exec(ctx)
+		require.NoError(t, err)
+	})
+
+	ar := NewAccountLink(testDB)
+
+	fixture := []schema.AccountLink{
+		{TraqName: "ikura-hamu", GitHubUserID: 12345, GitHubLogin: "old-name", Source: "invitation", LinkedAt: time.Now()},
+		{TraqName: "H1rono", GitHubUserID: 67890, GitHubLogin: "H1rono", Source: "invitation", LinkedAt: time.Now()},
+	}
+	_, err := ar.db.NewInsert().Model(&fixture).Exec(ctx)
+	require.NoError(t, err)
+
+	err = ar.UpdateAccountLinkGitHubLogin(ctx, 12345, "new-name")
+	require.NoError(t, err)
+
+	link, err := ar.GetAccountLinkByGitHubLogin(ctx, "new-name")
+	require.NoError(t, err)
+	assert.Equal(t, int64(12345), link.GitHubUserID())
+
+	link, err = ar.GetAccountLinkByGitHubLogin(ctx, "H1rono")
+	require.NoError(t, err)
+	assert.Equal(t, int64(67890), link.GitHubUserID())
+}
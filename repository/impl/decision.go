@@ -0,0 +1,98 @@
+package impl
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/traP-jp/members_bot/model"
+	"github.com/traP-jp/members_bot/repository"
+	"github.com/traP-jp/members_bot/repository/impl/schema"
+	"github.com/uptrace/bun"
+)
+
+var _ repository.Decision = &Decision{}
+
+type Decision struct {
+	db *bun.DB
+}
+
+func NewDecision(db *bun.DB) *Decision {
+	return &Decision{db: db}
+}
+
+// CreateDecision は、判定とそれに数えたスタンプを記録する。
+// ctxにトランザクションがある場合は、その中で記録する
+func (d *Decision) CreateDecision(ctx context.Context, decision *model.Decision) error {
+	return runInTx(ctx, d.db, func(ctx context.Context, tx bun.IDB) error {
+		return insertDecision(ctx, tx, decision)
+	})
+}
+
+func insertDecision(ctx context.Context, db bun.IDB, decision *model.Decision) error {
+	decisionSchema := schema.Decision{
+		MessageID: decision.MessageID(),
+		Kind:      string(decision.Kind()),
+		Decision:  string(decision.Result()),
+		DecidedAt: decision.DecidedAt(),
+	}
+
+	_, err := db.NewInsert().Model(&decisionSchema).Exec(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to create decision: %w", err)
+	}
+
+	if len(decision.Votes()) == 0 {
+		return nil
+	}
+
+	voteSchemes := make([]schema.DecisionVote, 0, len(decision.Votes()))
+	for _, vote := range decision.Votes() {
+		voteSchemes = append(voteSchemes, schema.DecisionVote{
+			MessageID: decision.MessageID(),
+			UserID:    vote.UserID(),
+			StampID:   vote.StampID(),
+			StampedAt: vote.StampedAt(),
+		})
+	}
+
+	_, err = db.NewInsert().Model(&voteSchemes).Exec(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to create decision votes: %w", err)
+	}
+
+	return nil
+}
+
+func (d *Decision) GetDecision(ctx context.Context, kind model.DecisionKind, id string) (*model.Decision, error) {
+	var decisionSchema schema.Decision
+	err := d.db.NewSelect().
+		Model(&decisionSchema).
+		Where("message_id = ?", id).
+		Where("kind = ?", kind).
+		Scan(ctx)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, repository.ErrRecordNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get decision: %w", err)
+	}
+
+	var voteSchemes []schema.DecisionVote
+	err = d.db.NewSelect().
+		Model(&voteSchemes).
+		Where("message_id = ?", id).
+		Order("stamped_at", "id").
+		Scan(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get decision votes: %w", err)
+	}
+
+	votes := make([]*model.Vote, 0, len(voteSchemes))
+	for _, vote := range voteSchemes {
+		votes = append(votes, model.NewVote(vote.UserID, vote.StampID, vote.StampedAt))
+	}
+
+	return model.NewDecision(decisionSchema.MessageID, model.DecisionKind(decisionSchema.Kind), model.DecisionResult(decisionSchema.Decision), votes, decisionSchema.DecidedAt), nil
+}
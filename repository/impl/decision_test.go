@@ -0,0 +1,136 @@
+package impl
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/traP-jp/members_bot/model"
+	"github.com/traP-jp/members_bot/repository"
+	"github.com/traP-jp/members_bot/repository/impl/schema"
+)
+
+func TestCreateDecision(t *testing.T) {
+	testCases := map[string]struct {
+		decision *model.Decision
+	}{
+		"承認": {
+			decision: model.NewDecision(uuid.NewString(), model.DecisionKindInvitation, model.DecisionResultApproved, []*model.Vote{
+				model.NewVote(uuid.NewString(), "accept_stamp_id", time.Now().Add(-time.Minute).Truncate(time.Second)),
+				model.NewVote(uuid.NewString(), "accept_stamp_id", time.Now().Truncate(time.Second)),
+			}, time.Now().Truncate(time.Second)),
+		},
+		"メンバーから外す申請": {
+			decision: model.NewDecision(uuid.NewString(), model.DecisionKindRemoval, model.DecisionResultApproved, []*model.Vote{
+				model.NewVote(uuid.NewString(), "accept_stamp_id", time.Now().Truncate(time.Second)),
+			}, time.Now().Truncate(time.Second)),
+		},
+		"スタンプなし": {
+			decision: model.NewDecision(uuid.NewString(), model.DecisionKindInvitation, model.DecisionResultRejected, []*model.Vote{}, time.Now().Truncate(time.Second)),
+		},
+	}
+
+	for name, test := range testCases {
+		t.Run(name, func(t *testing.T) {
+			ctx := context.Background()
+
+			t.Cleanup(func() {
+				_, err := testDB.NewTruncateTable().Model(&schema.Decision{}).Exec(ctx)
+				require.NoError(t, err)
+				_, err = testDB.NewTruncateTable().Model(&schema.DecisionVote{}).Exec(ctx)
+				require.NoError(t, err)
+			})
+
+			dr := NewDecision(testDB)
+
+			err := dr.CreateDecision(ctx, test.decision)
+			assert.NoError(t, err)
+
+			var decisionsTable []schema.Decision
+			err = dr.db.NewSelect().Model(&decisionsTable).Scan(ctx)
+			require.NoError(t, err)
+
+			require.Len(t, decisionsTable, 1)
+			assert.Equal(t, test.decision.MessageID(), decisionsTable[0].MessageID)
+			assert.Equal(t, string(test.decision.Kind()), decisionsTable[0].Kind)
+			assert.Equal(t, string(test.decision.Result()), decisionsTable[0].Decision)
+
+			var votesTable []schema.DecisionVote
+			err = dr.db.NewSelect().Model(&votesTable).Order("id").Scan(ctx)
+			require.NoError(t, err)
+
+			require.Len(t, votesTable, len(test.decision.Votes()))
+			for i, vote := range votesTable {
+				assert.Equal(t, test.decision.MessageID(), vote.MessageID)
+				assert.Equal(t, test.decision.Votes()[i].UserID(), vote.UserID)
+				assert.Equal(t, test.decision.Votes()[i].StampID(), vote.StampID)
+				assert.WithinDuration(t, test.decision.Votes()[i].StampedAt(), vote.StampedAt, time.Second)
+			}
+		})
+	}
+}
+
+func TestGetDecision(t *testing.T) {
+	ctx := context.Background()
+
+	t.Cleanup(func() {
+		_, err := testDB.NewTruncateTable().Model(&schema.Decision{}).Exec(ctx)
+		require.NoError(t, err)
+		_, err = testDB.NewTruncateTable().Model(&schema.DecisionVote{}).Exec(ctx)
+		require.NoError(t, err)
+	})
+
+	dr := NewDecision(testDB)
+
+	messageID := uuid.NewString()
+	adminID1 := uuid.NewString()
+	adminID2 := uuid.NewString()
+	removalMessageID := uuid.NewString()
+	{
+		decisions := []schema.Decision{
+			{MessageID: messageID, Kind: string(model.DecisionKindInvitation), Decision: string(model.DecisionResultApproved)},
+			{MessageID: removalMessageID, Kind: string(model.DecisionKindRemoval), Decision: string(model.DecisionResultRejected)},
+		}
+		_, err := dr.db.NewInsert().Model(&decisions).Exec(ctx)
+		require.NoError(t, err)
+
+		votes := []schema.DecisionVote{
+			{MessageID: messageID, UserID: adminID2, StampID: "accept_stamp_id", StampedAt: time.Now()},
+			{MessageID: messageID, UserID: adminID1, StampID: "accept_stamp_id", StampedAt: time.Now().Add(-time.Minute)},
+			{MessageID: uuid.NewString(), UserID: adminID1, StampID: "accept_stamp_id", StampedAt: time.Now()},
+		}
+		_, err = dr.db.NewInsert().Model(&votes).Exec(ctx)
+		require.NoError(t, err)
+	}
+
+	t.Run("特に問題なし", func(t *testing.T) {
+		decision, err := dr.GetDecision(ctx, model.DecisionKindInvitation, messageID)
+		require.NoError(t, err)
+
+		assert.Equal(t, messageID, decision.MessageID())
+		assert.Equal(t, model.DecisionKindInvitation, decision.Kind())
+		assert.Equal(t, model.DecisionResultApproved, decision.Result())
+		assert.WithinDuration(t, time.Now(), decision.DecidedAt(), 2*time.Second)
+		require.Len(t, decision.Votes(), 2)
+		assert.Equal(t, adminID1, decision.Votes()[0].UserID())
+		assert.Equal(t, adminID2, decision.Votes()[1].UserID())
+	})
+
+	t.Run("判定がない", func(t *testing.T) {
+		_, err := dr.GetDecision(ctx, model.DecisionKindInvitation, uuid.NewString())
+		assert.ErrorIs(t, err, repository.ErrRecordNotFound)
+	})
+
+	t.Run("メンバーから外す申請の判定は招待の判定として返さない", func(t *testing.T) {
+		_, err := dr.GetDecision(ctx, model.DecisionKindInvitation, removalMessageID)
+		assert.ErrorIs(t, err, repository.ErrRecordNotFound)
+
+		decision, err := dr.GetDecision(ctx, model.DecisionKindRemoval, removalMessageID)
+		require.NoError(t, err)
+		assert.Equal(t, model.DecisionKindRemoval, decision.Kind())
+		assert.Equal(t, model.DecisionResultRejected, decision.Result())
+	})
+}
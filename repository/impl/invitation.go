@@ -3,6 +3,9 @@ package impl
 import (
 	"context"
 	"fmt"
+	"slices"
+	"strings"
+	"time"
 
 	"github.com/traP-jp/members_bot/model"
 	"github.com/traP-jp/members_bot/repository"
@@ -20,15 +23,38 @@ func NewInvitation(db *bun.DB) *Invitation {
 	return &Invitation{db: db}
 }
 
+// 状態と、その状態に遷移した日時を記録するカラムの対応
+var statusTimeColumns = map[model.InvitationStatus]string{
+	model.InvitationStatusApproved:   "approved_at",
+	model.InvitationStatusRejected:   "rejected_at",
+	model.InvitationStatusSending:    "sending_at",
+	model.InvitationStatusSent:       "sent_at",
+	model.InvitationStatusSendFailed: "send_failed_at",
+	model.InvitationStatusAccepted:   "accepted_at",
+	model.InvitationStatusExpired:    "expired_at",
+	model.InvitationStatusCancelled:  "cancelled_at",
+}
+
 func (i *Invitation) CreateInvitation(ctx context.Context, invitations []*model.Invitation) error {
 	invitationSchemes := make([]schema.Invitation, 0, len(invitations))
 	for _, invitation := range invitations {
-		invitationSchemes = append(invitationSchemes,
-			schema.Invitation{
-				MessageID: invitation.MessageID(),
-				GitHubID:  invitation.GitHubID(),
-				TraqID:    invitation.TraqID(),
-			})
+		invitationScheme := schema.Invitation{
+			MessageID:       invitation.MessageID(),
+			GitHubID:        invitation.GitHubID(),
+			GitHubUserID:    invitation.GitHubUserID(),
+			TraqID:          invitation.TraqID(),
+			TraqUserID:      invitation.TraqUserID(),
+			TraqDisplayName: invitation.TraqDisplayName(),
+			Status:          string(model.InvitationStatusPending),
+			OriginChannelID: invitation.OriginChannelID(),
+			OriginMessageID: invitation.OriginMessageID(),
+		}
+		if requester := invitation.Requester(); requester != nil {
+			invitationScheme.RequesterID = requester.ID()
+			invitationScheme.RequesterName = requester.Name()
+		}
+
+		invitationSchemes = append(invitationSchemes, invitationScheme)
 	}
 
 	_, err := i.db.NewInsert().Model(&invitationSchemes).Exec(ctx)
@@ -52,34 +78,347 @@ func (i *Invitation) GetInvitations(ctx context.Context, id string) ([]*model.In
 
 	invitations := make([]*model.Invitation, 0, len(invitationSchemes))
 	for _, invitationScheme := range invitationSchemes {
-		invitations = append(invitations,
-			model.NewInvitation(invitationScheme.MessageID, invitationScheme.TraqID, invitationScheme.GitHubID))
+		invitations = append(invitations, toInvitationModel(invitationScheme))
 	}
 
 	return invitations, nil
 }
 
-func (i *Invitation) DeleteInvitations(ctx context.Context, id string) error {
-	_, err := i.db.NewDelete().Model(&schema.Invitation{}).Where("message_id = ?", id).Exec(ctx)
+func (i *Invitation) GetInvitationsByStatus(ctx context.Context, statuses ...model.InvitationStatus) ([]*model.Invitation, error) {
+	if len(statuses) == 0 {
+		return []*model.Invitation{}, nil
+	}
+
+	var invitations []schema.Invitation
+	err := i.db.NewSelect().
+		Model(&invitations).
+		Where("status IN (?)", bun.In(statuses)).
+		Order("id").
+		Scan(ctx)
 	if err != nil {
-		return fmt.Errorf("failed to delete invitations: %w", err)
+		return nil, fmt.Errorf("failed to get invitations: %w", err)
 	}
 
-	return nil
+	invitationsModel := make([]*model.Invitation, 0, len(invitations))
+	for _, invitation := range invitations {
+		invitationsModel = append(invitationsModel, toInvitationModel(&invitation))
+	}
+
+	return invitationsModel, nil
 }
 
-func (i *Invitation) GetAllInvitations(ctx context.Context) ([]*model.Invitation, error) {
+func (i *Invitation) GetInvitationsCreatedBefore(ctx context.Context, status model.InvitationStatus, cutoff time.Time) ([]*model.Invitation, error) {
 	var invitations []schema.Invitation
-	err := i.db.NewSelect().Model(&invitations).Scan(ctx)
+	err := i.db.NewSelect().
+		Model(&invitations).
+		Where("status = ?", status).
+		Where("created_at < ?", cutoff).
+		Order("created_at", "id").
+		Scan(ctx)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get invitations: %w", err)
 	}
 
 	invitationsModel := make([]*model.Invitation, 0, len(invitations))
 	for _, invitation := range invitations {
-		invitationsModel = append(invitationsModel,
-			model.NewInvitation(invitation.MessageID, invitation.TraqID, invitation.GitHubID))
+		invitationsModel = append(invitationsModel, toInvitationModel(&invitation))
+	}
+
+	return invitationsModel, nil
+}
+
+func (i *Invitation) UpdateInvitationStatus(ctx context.Context, id string, status model.InvitationStatus) error {
+	return i.updateInvitationStatus(ctx, status, func(q bun.QueryBuilder) bun.QueryBuilder {
+		return q.Where("message_id = ?", id)
+	})
+}
+
+func (i *Invitation) UpdateInvitationStatusByGitHubID(ctx context.Context, id string, gitHubID string, status model.InvitationStatus) error {
+	return i.updateInvitationStatus(ctx, status, func(q bun.QueryBuilder) bun.QueryBuilder {
+		return q.Where("message_id = ?", id).Where("git_hub_id = ?", gitHubID)
+	})
+}
+
+// updateInvitationStatus は、whereで絞り込んだ招待の状態をstatusに遷移させる
+func (i *Invitation) updateInvitationStatus(ctx context.Context, status model.InvitationStatus, where func(bun.QueryBuilder) bun.QueryBuilder) error {
+	previousStatuses := status.PreviousStatuses()
+	if len(previousStatuses) == 0 {
+		return repository.ErrInvalidStatusTransition
+	}
+
+	// 遷移元の状態を条件に含めることで、遷移できない状態からの更新を防ぐ
+	q := i.db.NewUpdate().
+		Model((*schema.Invitation)(nil)).
+		Set("status = ?", status).
+		ApplyQueryBuilder(where).
+		Where("status IN (?)", bun.In(previousStatuses))
+	if column, ok := statusTimeColumns[status]; ok {
+		q = q.Set("? = CURRENT_TIMESTAMP", bun.Ident(column))
+	}
+
+	res, err := q.Exec(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to update invitation status: %w", err)
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	if affected > 0 {
+		return nil
+	}
+
+	exists, err := i.db.NewSelect().Model((*schema.Invitation)(nil)).ApplyQueryBuilder(where).Exists(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to check invitation existence: %w", err)
+	}
+	if !exists {
+		return repository.ErrRecordNotFound
+	}
+
+	return repository.ErrInvalidStatusTransition
+}
+
+func (i *Invitation) DecideInvitations(ctx context.Context, id string, status model.InvitationStatus) error {
+	if status != model.InvitationStatusApproved && status != model.InvitationStatusRejected {
+		return repository.ErrInvalidStatusTransition
+	}
+
+	return runInTx(ctx, i.db, func(ctx context.Context, tx bun.IDB) error {
+		// 同じメッセージへのイベントが同時に届いても、1つだけが判定できるように行をロックする
+		var invitations []schema.Invitation
+		err := tx.NewSelect().
+			Model(&invitations).
+			Where("message_id = ?", id).
+			For("UPDATE").
+			Scan(ctx)
+		if err != nil {
+			return fmt.Errorf("failed to get invitations: %w", err)
+		}
+		if len(invitations) == 0 {
+			return repository.ErrRecordNotFound
+		}
+
+		pending := slices.ContainsFunc(invitations, func(invitation schema.Invitation) bool {
+			return invitation.Status == string(model.InvitationStatusPending)
+		})
+		if !pending {
+			return repository.ErrInvalidStatusTransition
+		}
+
+		_, err = tx.NewUpdate().
+			Model((*schema.Invitation)(nil)).
+			Set("status = ?", status).
+			Set("? = CURRENT_TIMESTAMP", bun.Ident(statusTimeColumns[status])).
+			Where("message_id = ?", id).
+			Where("status = ?", model.InvitationStatusPending).
+			Exec(ctx)
+		if err != nil {
+			return fmt.Errorf("failed to update invitation status: %w", err)
+		}
+
+		return nil
+	})
+}
+
+func (i *Invitation) ReleaseStaleInvitations(ctx context.Context, before time.Time) ([]*model.Invitation, error) {
+	var invitations []schema.Invitation
+	err := runInTx(ctx, i.db, func(ctx context.Context, tx bun.IDB) error {
+		// 送信中の状態を確保した処理が今終わっても、状態を上書きしないように行をロックする
+		err := tx.NewSelect().
+			Model(&invitations).
+			WhereGroup(" AND ", func(q *bun.SelectQuery) *bun.SelectQuery {
+				return q.Where("status = ? AND sending_at < ?", model.InvitationStatusSending, before).
+					WhereOr("status = ? AND approved_at < ?", model.InvitationStatusApproved, before)
+			}).
+			Order("id").
+			For("UPDATE").
+			Scan(ctx)
+		if err != nil {
+			return fmt.Errorf("failed to get stale invitations: %w", err)
+		}
+		if len(invitations) == 0 {
+			return nil
+		}
+
+		ids := make([]int, 0, len(invitations))
+		for _, invitation := range invitations {
+			ids = append(ids, invitation.ID)
+		}
+		_, err = tx.NewUpdate().
+			Model((*schema.Invitation)(nil)).
+			Set("status = ?", model.InvitationStatusSendFailed).
+			Set("? = CURRENT_TIMESTAMP", bun.Ident(statusTimeColumns[model.InvitationStatusSendFailed])).
+			Where("id IN (?)", bun.In(ids)).
+			Exec(ctx)
+		if err != nil {
+			return fmt.Errorf("failed to release stale invitations: %w", err)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	invitationsModel := make([]*model.Invitation, 0, len(invitations))
+	for _, invitation := range invitations {
+		invitationsModel = append(invitationsModel, toInvitationModel(&invitation))
 	}
 
 	return invitationsModel, nil
 }
+
+func (i *Invitation) GetLatestGitHubLogins(ctx context.Context) ([]*repository.GitHubLogin, error) {
+	// ユーザーIDごとに、最後に記録された招待のユーザー名を使う
+	latest := i.db.NewSelect().
+		Model((*schema.Invitation)(nil)).
+		ColumnExpr("MAX(id) AS id").
+		Where("git_hub_user_id IS NOT NULL").
+		Group("git_hub_user_id")
+
+	var invitations []schema.Invitation
+	err := i.db.NewSelect().
+		Model(&invitations).
+		Column("git_hub_user_id", "git_hub_id").
+		Where("id IN (?)", latest).
+		Order("git_hub_user_id").
+		Scan(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get latest GitHub logins: %w", err)
+	}
+
+	logins := make([]*repository.GitHubLogin, 0, len(invitations))
+	for _, invitation := range invitations {
+		logins = append(logins, &repository.GitHubLogin{GitHubUserID: invitation.GitHubUserID, Login: invitation.GitHubID})
+	}
+
+	return logins, nil
+}
+
+func (i *Invitation) UpdateInvitationGitHubID(ctx context.Context, gitHubUserID int64, gitHubID string) error {
+	_, err := i.db.NewUpdate().
+		Model((*schema.Invitation)(nil)).
+		Set("git_hub_id = ?", gitHubID).
+		Where("git_hub_user_id = ?", gitHubUserID).
+		Exec(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to update invitation GitHub ID: %w", err)
+	}
+
+	return nil
+}
+
+func (i *Invitation) UpdateInvitationReason(ctx context.Context, id string, reason string) error {
+	res, err := i.db.NewUpdate().
+		Model((*schema.Invitation)(nil)).
+		Set("reason = ?", reason).
+		Where("message_id = ?", id).
+		Exec(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to update invitation reason: %w", err)
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	if affected > 0 {
+		return nil
+	}
+
+	// 同じ理由で更新した場合も影響を受けた行は0になるので、存在を確認する
+	exists, err := i.db.NewSelect().Model((*schema.Invitation)(nil)).Where("message_id = ?", id).Exists(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to check invitation existence: %w", err)
+	}
+	if !exists {
+		return repository.ErrRecordNotFound
+	}
+
+	return nil
+}
+
+func (i *Invitation) UpdateInvitationRemindedAt(ctx context.Context, id string, remindedAt time.Time) error {
+	_, err := i.db.NewUpdate().
+		Model((*schema.Invitation)(nil)).
+		Set("reminded_at = ?", remindedAt).
+		Where("message_id = ?", id).
+		Exec(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to update invitation reminded at: %w", err)
+	}
+
+	return nil
+}
+
+// 判定された日時。承認・却下されずに終わったものは、期限切れ・取り消しの日時とする
+const decidedAtExpr = "COALESCE(approved_at, rejected_at, expired_at, cancelled_at)"
+
+func (i *Invitation) GetInvitationHistory(ctx context.Context, query repository.InvitationHistoryQuery) ([]*model.Invitation, error) {
+	var invitations []schema.Invitation
+	q := i.db.NewSelect().
+		Model(&invitations).
+		Where("status <> ?", model.InvitationStatusPending)
+
+	if query.TraqID != "" {
+		q = q.Where("TRIM(LEADING '@' FROM traq_id) = ?", strings.TrimPrefix(query.TraqID, "@"))
+	}
+	if query.GitHubID != "" {
+		q = q.Where("git_hub_id = ?", query.GitHubID)
+	}
+	if len(query.Statuses) > 0 {
+		q = q.Where("status IN (?)", bun.In(query.Statuses))
+	}
+	if !query.Since.IsZero() {
+		q = q.Where(decidedAtExpr+" >= ?", query.Since)
+	}
+	if !query.Until.IsZero() {
+		q = q.Where(decidedAtExpr+" < ?", query.Until)
+	}
+	if query.Limit > 0 {
+		q = q.Limit(query.Limit)
+	}
+	if query.Offset > 0 {
+		q = q.Offset(query.Offset)
+	}
+
+	err := q.OrderExpr(decidedAtExpr + " DESC").Order("id").Scan(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get invitation history: %w", err)
+	}
+
+	invitationsModel := make([]*model.Invitation, 0, len(invitations))
+	for _, invitation := range invitations {
+		invitationsModel = append(invitationsModel, toInvitationModel(&invitation))
+	}
+
+	return invitationsModel, nil
+}
+
+func toInvitationModel(invitation *schema.Invitation) *model.Invitation {
+	var requester *model.User
+	if invitation.RequesterID != "" {
+		requester = model.NewUser(invitation.RequesterID, invitation.RequesterName)
+	}
+
+	return model.NewInvitation(invitation.MessageID, invitation.TraqID, invitation.GitHubID,
+		model.WithStatus(model.InvitationStatus(invitation.Status)),
+		model.WithOrigin(requester, invitation.OriginChannelID, invitation.OriginMessageID),
+		model.WithTraqUserID(invitation.TraqUserID),
+		model.WithTraqDisplayName(invitation.TraqDisplayName),
+		model.WithGitHubUserID(invitation.GitHubUserID),
+		model.WithReason(invitation.Reason),
+		model.WithCreatedAt(invitation.CreatedAt),
+		model.WithRemindedAt(invitation.RemindedAt),
+		model.WithTransitionedAt(model.InvitationStatusApproved, invitation.ApprovedAt),
+		model.WithTransitionedAt(model.InvitationStatusRejected, invitation.RejectedAt),
+		model.WithTransitionedAt(model.InvitationStatusSending, invitation.SendingAt),
+		model.WithTransitionedAt(model.InvitationStatusSent, invitation.SentAt),
+		model.WithTransitionedAt(model.InvitationStatusSendFailed, invitation.SendFailedAt),
+		model.WithTransitionedAt(model.InvitationStatusAccepted, invitation.AcceptedAt),
+		model.WithTransitionedAt(model.InvitationStatusExpired, invitation.ExpiredAt),
+		model.WithTransitionedAt(model.InvitationStatusCancelled, invitation.CancelledAt),
+	)
+}
@@ -2,6 +2,7 @@ package impl
 
 import (
 	"context"
+	"sync"
 	"testing"
 	"time"
 
@@ -28,6 +29,18 @@ func TestCreateInvitation(t *testing.T) {
 				model.NewInvitation("same_id", "github_id2", "traq_id2"),
 			},
 		},
+		"申請元あり": {
+			invitations: []*model.Invitation{
+				model.NewInvitation(uuid.NewString(), "github_id", "traq_id",
+					model.WithOrigin(model.NewUser(uuid.NewString(), "requester"), uuid.NewString(), uuid.NewString()),
+					model.WithTraqUserID(uuid.NewString()), model.WithTraqDisplayName("いくらはむ")),
+			},
+		},
+		"GitHubのユーザーIDあり": {
+			invitations: []*model.Invitation{
+				model.NewInvitation(uuid.NewString(), "github_id", "traq_id", model.WithGitHubUserID(12345)),
+			},
+		},
 	}
 
 	for name, test := range testCases {
@@ -52,7 +65,18 @@ func TestCreateInvitation(t *testing.T) {
 			for i, invitation := range invitationsTable {
 				assert.Equal(t, test.invitations[i].MessageID(), invitation.MessageID)
 				assert.Equal(t, test.invitations[i].GitHubID(), invitation.GitHubID)
+				assert.Equal(t, test.invitations[i].GitHubUserID(), invitation.GitHubUserID)
 				assert.Equal(t, test.invitations[i].TraqID(), invitation.TraqID)
+				assert.Equal(t, test.invitations[i].TraqUserID(), invitation.TraqUserID)
+				assert.Equal(t, test.invitations[i].TraqDisplayName(), invitation.TraqDisplayName)
+				assert.Equal(t, test.invitations[i].OriginChannelID(), invitation.OriginChannelID)
+				assert.Equal(t, test.invitations[i].OriginMessageID(), invitation.OriginMessageID)
+				if requester := test.invitations[i].Requester(); requester != nil {
+					assert.Equal(t, requester.ID(), invitation.RequesterID)
+					assert.Equal(t, requester.Name(), invitation.RequesterName)
+				} else {
+					assert.Empty(t, invitation.RequesterID)
+				}
 				assert.WithinDuration(t, time.Now(), invitation.CreatedAt, time.Second)
 			}
 		})
@@ -131,7 +155,7 @@ func TestGetInvitations(t *testing.T) {
 	}
 }
 
-func TestDeleteInvitations(t *testing.T) {
+func TestGetInvitationsByStatus(t *testing.T) {
 	t.Run("success", func(t *testing.T) {
 		ctx := context.Background()
 
@@ -142,24 +166,31 @@ func TestDeleteInvitations(t *testing.T) {
 
 		ir := NewInvitation(testDB)
 
-		invitationID := uuid.NewString()
+		pendingID := uuid.NewString()
+		sentID := uuid.NewString()
+		rejectedID := uuid.NewString()
 		{
-			_, err := ir.db.NewInsert().Model(&schema.Invitation{MessageID: invitationID}).Exec(ctx)
+			fixture := []schema.Invitation{
+				{MessageID: pendingID, GitHubID: "github_id", TraqID: "traq_id", Status: string(model.InvitationStatusPending)},
+				{MessageID: sentID, GitHubID: "github_id2", TraqID: "traq_id2", Status: string(model.InvitationStatusSent)},
+				{MessageID: rejectedID, GitHubID: "github_id3", TraqID: "traq_id3", Status: string(model.InvitationStatusRejected)},
+			}
+			_, err := ir.db.NewInsert().Model(&fixture).Exec(ctx)
 			require.NoError(t, err)
 		}
 
-		err := ir.DeleteInvitations(ctx, invitationID)
-		assert.NoError(t, err)
-
-		var invitations []schema.Invitation
-		err = ir.db.NewSelect().Model(&invitations).Scan(ctx)
+		result, err := ir.GetInvitationsByStatus(ctx, model.InvitationStatusPending, model.InvitationStatusSent)
 		require.NoError(t, err)
 
-		assert.Len(t, invitations, 0)
+		require.Len(t, result, 2)
+		assert.Equal(t, pendingID, result[0].MessageID())
+		assert.Equal(t, model.InvitationStatusPending, result[0].Status())
+		assert.Equal(t, sentID, result[1].MessageID())
+		assert.Equal(t, model.InvitationStatusSent, result[1].Status())
 	})
 }
 
-func TestGetAllInvitations(t *testing.T) {
+func TestGetInvitationsCreatedBefore(t *testing.T) {
 	t.Run("success", func(t *testing.T) {
 		ctx := context.Background()
 
@@ -170,33 +201,583 @@ func TestGetAllInvitations(t *testing.T) {
 
 		ir := NewInvitation(testDB)
 
-		invitations := []*model.Invitation{
-			model.NewInvitation(uuid.NewString(), "github_id", "traq_id"),
-			model.NewInvitation(uuid.NewString(), "github_id2", "traq_id2"),
-		}
+		now := time.Now().Truncate(time.Second)
+		oldID := uuid.NewString()
+		olderID := uuid.NewString()
 		{
-			invitationsTable := make([]schema.Invitation, 0, len(invitations))
+			fixture := []schema.Invitation{
+				{MessageID: oldID, GitHubID: "github_id", TraqID: "traq_id", Status: string(model.InvitationStatusPending), CreatedAt: now.Add(-48 * time.Hour)},
+				{MessageID: olderID, GitHubID: "github_id2", TraqID: "traq_id2", Status: string(model.InvitationStatusPending), CreatedAt: now.Add(-72 * time.Hour)},
+				{MessageID: uuid.NewString(), GitHubID: "github_id3", TraqID: "traq_id3", Status: string(model.InvitationStatusPending), CreatedAt: now},
+				{MessageID: uuid.NewString(), GitHubID: "github_id4", TraqID: "traq_id4", Status: string(model.InvitationStatusRejected), CreatedAt: now.Add(-72 * time.Hour)},
+			}
+			_, err := ir.db.NewInsert().Model(&fixture).Exec(ctx)
+			require.NoError(t, err)
+		}
+
+		result, err := ir.GetInvitationsCreatedBefore(ctx, model.InvitationStatusPending, now.Add(-24*time.Hour))
+		require.NoError(t, err)
+
+		require.Len(t, result, 2)
+		assert.Equal(t, olderID, result[0].MessageID())
+		assert.Equal(t, oldID, result[1].MessageID())
+	})
+}
+
+func TestUpdateInvitationStatus(t *testing.T) {
+	testCases := map[string]struct {
+		current     model.InvitationStatus
+		next        model.InvitationStatus
+		noRecord    bool
+		expectedErr error
+	}{
+		"承認": {
+			current: model.InvitationStatusPending,
+			next:    model.InvitationStatusApproved,
+		},
+		"送信": {
+			current: model.InvitationStatusApproved,
+			next:    model.InvitationStatusSent,
+		},
+		"送信失敗から再送信": {
+			current: model.InvitationStatusSendFailed,
+			next:    model.InvitationStatusSent,
+		},
+		"期限切れから再送信": {
+			current: model.InvitationStatusExpired,
+			next:    model.InvitationStatusSent,
+		},
+		"判定済みなので承認できない": {
+			current:     model.InvitationStatusRejected,
+			next:        model.InvitationStatusApproved,
+			expectedErr: repository.ErrInvalidStatusTransition,
+		},
+		"承認されていないので送信できない": {
+			current:     model.InvitationStatusPending,
+			next:        model.InvitationStatusSent,
+			expectedErr: repository.ErrInvalidStatusTransition,
+		},
+		"招待がない": {
+			next:        model.InvitationStatusApproved,
+			noRecord:    true,
+			expectedErr: repository.ErrRecordNotFound,
+		},
+	}
+
+	for name, test := range testCases {
+		t.Run(name, func(t *testing.T) {
+			ctx := context.Background()
+
+			t.Cleanup(func() {
+				_, err := testDB.NewTruncateTable().Model(&schema.Invitation{}).Exec(ctx)
+				require.NoError(t, err)
+			})
+
+			ir := NewInvitation(testDB)
+
+			invitationID := uuid.NewString()
+			if !test.noRecord {
+				fixture := []schema.Invitation{
+					{MessageID: invitationID, GitHubID: "github_id", TraqID: "traq_id", Status: string(test.current)},
+					{MessageID: invitationID, GitHubID: "github_id2", TraqID: "traq_id2", Status: string(test.current)},
+				}
+				_, err := ir.db.NewInsert().Model(&fixture).Exec(ctx)
+				require.NoError(t, err)
+			}
+
+			err := ir.UpdateInvitationStatus(ctx, invitationID, test.next)
+			assert.ErrorIs(t, err, test.expectedErr)
+
+			if test.noRecord {
+				return
+			}
+
+			invitations, err := ir.GetInvitations(ctx, invitationID)
+			require.NoError(t, err)
+
 			for _, invitation := range invitations {
-				invitationsTable = append(invitationsTable, schema.Invitation{
-					MessageID: invitation.MessageID(),
-					GitHubID:  invitation.GitHubID(),
-					TraqID:    invitation.TraqID(),
-				})
+				if test.expectedErr != nil {
+					assert.Equal(t, test.current, invitation.Status())
+					assert.True(t, invitation.TransitionedAt(test.next).IsZero())
+					continue
+				}
+
+				assert.Equal(t, test.next, invitation.Status())
+				assert.WithinDuration(t, time.Now(), invitation.TransitionedAt(test.next), 2*time.Second)
 			}
+		})
+	}
+}
+
+func TestGetInvitationHistory(t *testing.T) {
+	ctx := context.Background()
+
+	t.Cleanup(func() {
+		_, err := testDB.NewTruncateTable().Model(&schema.Invitation{}).Exec(ctx)
+		require.NoError(t, err)
+	})
+
+	ir := NewInvitation(testDB)
+
+	now := time.Now().Truncate(time.Second)
+	pendingID := uuid.NewString()
+	approvedID := uuid.NewString()
+	rejectedID := uuid.NewString()
+	oldID := uuid.NewString()
+	{
+		fixture := []schema.Invitation{
+			{MessageID: pendingID, GitHubID: "github_id", TraqID: "@traq_id", Status: string(model.InvitationStatusPending)},
+			{MessageID: approvedID, GitHubID: "github_id", TraqID: "@traq_id", Status: string(model.InvitationStatusSent), ApprovedAt: now.Add(-time.Hour), SentAt: now.Add(-time.Hour)},
+			{MessageID: rejectedID, GitHubID: "github_id2", TraqID: "traq_id2", Status: string(model.InvitationStatusRejected), RejectedAt: now},
+			{MessageID: oldID, GitHubID: "github_id3", TraqID: "traq_id3", Status: string(model.InvitationStatusRejected), RejectedAt: now.AddDate(0, 0, -10)},
+		}
+		_, err := ir.db.NewInsert().Model(&fixture).Exec(ctx)
+		require.NoError(t, err)
+	}
+
+	testCases := map[string]struct {
+		query    repository.InvitationHistoryQuery
+		expected []string
+	}{
+		"条件なし": {
+			query:    repository.InvitationHistoryQuery{},
+			expected: []string{rejectedID, approvedID, oldID},
+		},
+		"traQ IDで絞り込み": {
+			query:    repository.InvitationHistoryQuery{TraqID: "traq_id"},
+			expected: []string{approvedID},
+		},
+		"GitHub IDで絞り込み": {
+			query:    repository.InvitationHistoryQuery{GitHubID: "github_id2"},
+			expected: []string{rejectedID},
+		},
+		"状態で絞り込み": {
+			query:    repository.InvitationHistoryQuery{Statuses: []model.InvitationStatus{model.InvitationStatusApproved, model.InvitationStatusSent, model.InvitationStatusAccepted}},
+			expected: []string{approvedID},
+		},
+		"期間で絞り込み": {
+			query:    repository.InvitationHistoryQuery{Since: now.AddDate(0, 0, -1), Until: now},
+			expected: []string{approvedID},
+		},
+		"ページング": {
+			query:    repository.InvitationHistoryQuery{Limit: 1, Offset: 1},
+			expected: []string{approvedID},
+		},
+	}
 
-			_, err := ir.db.NewInsert().Model(&invitationsTable).Exec(ctx)
+	for name, test := range testCases {
+		t.Run(name, func(t *testing.T) {
+			invitations, err := ir.GetInvitationHistory(ctx, test.query)
 			require.NoError(t, err)
+
+			messageIDs := make([]string, 0, len(invitations))
+			for _, invitation := range invitations {
+				messageIDs = append(messageIDs, invitation.MessageID())
+			}
+			assert.Equal(t, test.expected, messageIDs)
+		})
+	}
+}
+
+func TestUpdateInvitationReason(t *testing.T) {
+	ctx := context.Background()
+
+	t.Cleanup(func() {
+		_, err := testDB.NewTruncateTable().Model(&schema.Invitation{}).Exec(ctx)
+		require.NoError(t, err)
+	})
+
+	ir := NewInvitation(testDB)
+
+	invitationID := uuid.NewString()
+	{
+		_, err := ir.db.NewInsert().Model(&schema.Invitation{MessageID: invitationID}).Exec(ctx)
+		require.NoError(t, err)
+	}
+
+	t.Run("success", func(t *testing.T) {
+		err := ir.UpdateInvitationReason(ctx, invitationID, "reason")
+		require.NoError(t, err)
+
+		invitations, err := ir.GetInvitations(ctx, invitationID)
+		require.NoError(t, err)
+		require.Len(t, invitations, 1)
+		assert.Equal(t, "reason", invitations[0].Reason())
+	})
+
+	t.Run("同じ理由で更新しても問題なし", func(t *testing.T) {
+		err := ir.UpdateInvitationReason(ctx, invitationID, "reason")
+		assert.NoError(t, err)
+	})
+
+	t.Run("招待がない", func(t *testing.T) {
+		err := ir.UpdateInvitationReason(ctx, uuid.NewString(), "reason")
+		assert.ErrorIs(t, err, repository.ErrRecordNotFound)
+	})
+}
+
+func TestGetLatestGitHubLogins(t *testing.T) {
+	ctx := context.Background()
+
+	t.Cleanup(func() {
+		_, err := testDB.NewTruncateTable().Model(&schema.Invitation{}).Exec(ctx)
+		require.NoError(t, err)
+	})
+
+	ir := NewInvitation(testDB)
+
+	fixture := []schema.Invitation{
+		{MessageID: uuid.NewString(), GitHubID: "new-name", GitHubUserID: 12345},
+		{MessageID: uuid.NewString(), GitHubID: "other", GitHubUserID: 67890},
+		// 同じユーザーは、後に記録された招待のユーザー名を使う
+		{MessageID: uuid.NewString(), GitHubID: "newest-name", GitHubUserID: 12345},
+		// ユーザーIDが記録されていない招待は含まない
+		{MessageID: uuid.NewString(), GitHubID: "legacy"},
+	}
+	_, err := ir.db.NewInsert().Model(&fixture).Exec(ctx)
+	require.NoError(t, err)
+
+	logins, err := ir.GetLatestGitHubLogins(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, []*repository.GitHubLogin{
+		{GitHubUserID: 12345, Login: "newest-name"},
+		{GitHubUserID: 67890, Login: "other"},
+	}, logins)
+}
+
+func TestUpdateInvitationGitHubID(t *testing.T) {
+	ctx := context.Background()
+
+	t.Cleanup(func() {
+		_, err := testDB.NewTruncateTable().Model(&schema.Invitation{}).Exec(ctx)
+		require.NoError(t, err)
+	})
+
+	ir := NewInvitation(testDB)
+
+	invitationIDs := []string{uuid.NewString(), uuid.NewString(), uuid.NewString()}
+	{
+		fixture := []schema.Invitation{
+			{MessageID: invitationIDs[0], GitHubID: "old-name", GitHubUserID: 12345},
+			{MessageID: invitationIDs[1], GitHubID: "old-name", GitHubUserID: 12345},
+			{MessageID: invitationIDs[2], GitHubID: "other", GitHubUserID: 67890},
+		}
+		_, err := ir.db.NewInsert().Model(&fixture).Exec(ctx)
+		require.NoError(t, err)
+	}
+
+	err := ir.UpdateInvitationGitHubID(ctx, 12345, "new-name")
+	require.NoError(t, err)
+
+	expected := []string{"new-name", "new-name", "other"}
+	for i, invitationID := range invitationIDs {
+		invitations, err := ir.GetInvitations(ctx, invitationID)
+		require.NoError(t, err)
+		require.Len(t, invitations, 1)
+		assert.Equal(t, expected[i], invitations[0].GitHubID())
+	}
+}
+
+func TestUpdateInvitationRemindedAt(t *testing.T) {
+	ctx := context.Background()
+
+	t.Cleanup(func() {
+		_, err := testDB.NewTruncateTable().Model(&schema.Invitation{}).Exec(ctx)
+		require.NoError(t, err)
+	})
+
+	ir := NewInvitation(testDB)
+
+	invitationID := uuid.NewString()
+	{
+		_, err := ir.db.NewInsert().Model(&schema.Invitation{MessageID: invitationID}).Exec(ctx)
+		require.NoError(t, err)
+	}
+
+	remindedAt := time.Now().Truncate(time.Second)
+	err := ir.UpdateInvitationRemindedAt(ctx, invitationID, remindedAt)
+	require.NoError(t, err)
+
+	invitations, err := ir.GetInvitations(ctx, invitationID)
+	require.NoError(t, err)
+	require.Len(t, invitations, 1)
+	assert.WithinDuration(t, remindedAt, invitations[0].RemindedAt(), time.Second)
+}
+
+func TestUpdateInvitationStatusByGitHubID(t *testing.T) {
+	ctx := context.Background()
+
+	t.Cleanup(func() {
+		_, err := testDB.NewTruncateTable().Model(&schema.Invitation{}).Exec(ctx)
+		require.NoError(t, err)
+	})
+
+	ir := NewInvitation(testDB)
+
+	invitationID := uuid.NewString()
+	{
+		fixture := []schema.Invitation{
+			{MessageID: invitationID, GitHubID: "github_id", TraqID: "traq_id", Status: string(model.InvitationStatusApproved)},
+			{MessageID: invitationID, GitHubID: "github_id2", TraqID: "traq_id2", Status: string(model.InvitationStatusApproved)},
 		}
+		_, err := ir.db.NewInsert().Model(&fixture).Exec(ctx)
+		require.NoError(t, err)
+	}
+
+	t.Run("1人だけ更新", func(t *testing.T) {
+		err := ir.UpdateInvitationStatusByGitHubID(ctx, invitationID, "github_id2", model.InvitationStatusSendFailed)
+		require.NoError(t, err)
 
-		result, err := ir.GetAllInvitations(ctx)
+		invitations, err := ir.GetInvitations(ctx, invitationID)
 		require.NoError(t, err)
+		require.Len(t, invitations, 2)
+		assert.Equal(t, model.InvitationStatusApproved, invitations[0].Status())
+		assert.Equal(t, model.InvitationStatusSendFailed, invitations[1].Status())
+		assert.False(t, invitations[1].TransitionedAt(model.InvitationStatusSendFailed).IsZero())
+	})
 
-		assert.Len(t, result, len(invitations))
+	t.Run("遷移できない", func(t *testing.T) {
+		err := ir.UpdateInvitationStatusByGitHubID(ctx, invitationID, "github_id2", model.InvitationStatusApproved)
+		assert.ErrorIs(t, err, repository.ErrInvalidStatusTransition)
+	})
+
+	t.Run("招待がない", func(t *testing.T) {
+		err := ir.UpdateInvitationStatusByGitHubID(ctx, invitationID, "github_id3", model.InvitationStatusSent)
+		assert.ErrorIs(t, err, repository.ErrRecordNotFound)
+	})
+}
 
-		for i, invitation := range result {
-			assert.Equal(t, invitations[i].MessageID(), invitation.MessageID())
-			assert.Equal(t, invitations[i].GitHubID(), invitation.GitHubID())
-			assert.Equal(t, invitations[i].TraqID(), invitation.TraqID())
+func TestReleaseStaleInvitations(t *testing.T) {
+	ctx := context.Background()
+
+	t.Cleanup(func() {
+		_, err := testDB.NewTruncateTable().Model(&schema.Invitation{}).Exec(ctx)
+		require.NoError(t, err)
+	})
+
+	ir := NewInvitation(testDB)
+
+	now := time.Now().Truncate(time.Second)
+	staleID := uuid.NewString()
+	sendingID := uuid.NewString()
+	failedID := uuid.NewString()
+	// 承認された後に送信されないまま止まった
+	unsentID := uuid.NewString()
+	approvedID := uuid.NewString()
+	{
+		fixture := []schema.Invitation{
+			{MessageID: staleID, GitHubID: "github_id", TraqID: "traq_id", Status: string(model.InvitationStatusSending), SendingAt: now.Add(-time.Hour)},
+			{MessageID: unsentID, GitHubID: "github_id4", TraqID: "traq_id4", Status: string(model.InvitationStatusApproved), ApprovedAt: now.Add(-time.Hour)},
+			{MessageID: approvedID, GitHubID: "github_id5", TraqID: "traq_id5", Status: string(model.InvitationStatusApproved), ApprovedAt: now},
+			{MessageID: sendingID, GitHubID: "github_id2", TraqID: "traq_id2", Status: string(model.InvitationStatusSending), SendingAt: now},
+			{MessageID: failedID, GitHubID: "github_id3", TraqID: "traq_id3", Status: string(model.InvitationStatusSendFailed), SendFailedAt: now.Add(-time.Hour)},
 		}
+		_, err := ir.db.NewInsert().Model(&fixture).Exec(ctx)
+		require.NoError(t, err)
+	}
+
+	released, err := ir.ReleaseStaleInvitations(ctx, now.Add(-10*time.Minute))
+	require.NoError(t, err)
+
+	require.Len(t, released, 2)
+	assert.Equal(t, staleID, released[0].MessageID())
+	assert.Equal(t, unsentID, released[1].MessageID())
+
+	statuses := map[string]model.InvitationStatus{
+		staleID:    model.InvitationStatusSendFailed,
+		sendingID:  model.InvitationStatusSending,
+		failedID:   model.InvitationStatusSendFailed,
+		unsentID:   model.InvitationStatusSendFailed,
+		approvedID: model.InvitationStatusApproved,
+	}
+	for messageID, status := range statuses {
+		invitations, err := ir.GetInvitations(ctx, messageID)
+		require.NoError(t, err)
+		require.Len(t, invitations, 1)
+		assert.Equal(t, status, invitations[0].Status())
+	}
+}
+
+func TestDecideInvitations(t *testing.T) {
+	testCases := map[string]struct {
+		current  model.InvitationStatus
+		result   model.InvitationStatus
+		noRecord bool
+		// 既に判定が記録されていて、記録に失敗するか
+		recorded    bool
+		expectedErr error
+	}{
+		"承認": {
+			current: model.InvitationStatusPending,
+			result:  model.InvitationStatusApproved,
+		},
+		"却下": {
+			current: model.InvitationStatusPending,
+			result:  model.InvitationStatusRejected,
+		},
+		"判定済み": {
+			current:     model.InvitationStatusApproved,
+			result:      model.InvitationStatusRejected,
+			expectedErr: repository.ErrInvalidStatusTransition,
+		},
+		"判定結果ではない": {
+			current:     model.InvitationStatusPending,
+			result:      model.InvitationStatusSent,
+			expectedErr: repository.ErrInvalidStatusTransition,
+		},
+		"招待がない": {
+			result:      model.InvitationStatusApproved,
+			noRecord:    true,
+			expectedErr: repository.ErrRecordNotFound,
+		},
+		"判定を記録できなかったら状態も戻す": {
+			current:  model.InvitationStatusPending,
+			result:   model.InvitationStatusApproved,
+			recorded: true,
+		},
+	}
+
+	for name, test := range testCases {
+		t.Run(name, func(t *testing.T) {
+			ctx := context.Background()
+
+			t.Cleanup(func() {
+				_, err := testDB.NewTruncateTable().Model(&schema.Invitation{}).Exec(ctx)
+				require.NoError(t, err)
+				_, err = testDB.NewTruncateTable().Model(&schema.Decision{}).Exec(ctx)
+				require.NoError(t, err)
+				_, err = testDB.NewTruncateTable().Model(&schema.DecisionVote{}).Exec(ctx)
+				require.NoError(t, err)
+			})
+
+			ir := NewInvitation(testDB)
+
+			invitationID := uuid.NewString()
+			if !test.noRecord {
+				fixture := []schema.Invitation{
+					{MessageID: invitationID, GitHubID: "github_id", TraqID: "traq_id", Status: string(test.current)},
+					{MessageID: invitationID, GitHubID: "github_id2", TraqID: "traq_id2", Status: string(test.current)},
+				}
+				_, err := ir.db.NewInsert().Model(&fixture).Exec(ctx)
+				require.NoError(t, err)
+			}
+
+			dr := NewDecision(testDB)
+			if test.recorded {
+				_, err := ir.db.NewInsert().Model(&schema.Decision{MessageID: invitationID, Decision: string(test.result)}).Exec(ctx)
+				require.NoError(t, err)
+			}
+
+			decision := model.NewDecision(invitationID, model.DecisionKindInvitation, model.DecisionResult(test.result), []*model.Vote{
+				model.NewVote(uuid.NewString(), "stamp_id", time.Now().Truncate(time.Second)),
+			}, time.Now().Truncate(time.Second))
+			err := NewTransaction(testDB).RunInTx(ctx, func(ctx context.Context) error {
+				err := ir.DecideInvitations(ctx, invitationID, test.result)
+				if err != nil {
+					return err
+				}
+				return dr.CreateDecision(ctx, decision)
+			})
+			if test.recorded {
+				assert.Error(t, err)
+			} else {
+				assert.ErrorIs(t, err, test.expectedErr)
+			}
+
+			_, err = dr.GetDecision(ctx, model.DecisionKindInvitation, invitationID)
+			if test.expectedErr != nil {
+				assert.ErrorIs(t, err, repository.ErrRecordNotFound)
+			} else {
+				assert.NoError(t, err)
+			}
+
+			if test.noRecord {
+				return
+			}
+
+			invitations, err := ir.GetInvitations(ctx, invitationID)
+			require.NoError(t, err)
+
+			for _, invitation := range invitations {
+				if test.expectedErr != nil || test.recorded {
+					assert.Equal(t, test.current, invitation.Status())
+					continue
+				}
+
+				assert.Equal(t, test.result, invitation.Status())
+				assert.WithinDuration(t, time.Now(), invitation.TransitionedAt(test.result), 2*time.Second)
+			}
+		})
+	}
+}
+
+// 同じメッセージへのイベントが同時に届いても、判定されるのは1度だけ
+func TestDecideInvitationsConcurrently(t *testing.T) {
+	ctx := context.Background()
+
+	t.Cleanup(func() {
+		_, err := testDB.NewTruncateTable().Model(&schema.Invitation{}).Exec(ctx)
+		require.NoError(t, err)
+		_, err = testDB.NewTruncateTable().Model(&schema.Decision{}).Exec(ctx)
+		require.NoError(t, err)
+		_, err = testDB.NewTruncateTable().Model(&schema.DecisionVote{}).Exec(ctx)
+		require.NoError(t, err)
 	})
+
+	ir := NewInvitation(testDB)
+	dr := NewDecision(testDB)
+	tx := NewTransaction(testDB)
+
+	invitationID := uuid.NewString()
+	fixture := []schema.Invitation{
+		{MessageID: invitationID, GitHubID: "github_id", TraqID: "traq_id", Status: string(model.InvitationStatusPending)},
+		{MessageID: invitationID, GitHubID: "github_id2", TraqID: "traq_id2", Status: string(model.InvitationStatusPending)},
+	}
+	_, err := ir.db.NewInsert().Model(&fixture).Exec(ctx)
+	require.NoError(t, err)
+
+	const n = 10
+	errs := make([]error, n)
+	var wg sync.WaitGroup
+	for i := range n {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			decision := model.NewDecision(invitationID, model.DecisionKindInvitation, model.DecisionResultApproved, []*model.Vote{
+				model.NewVote(uuid.NewString(), "stamp_id", time.Now().Truncate(time.Second)),
+			}, time.Now().Truncate(time.Second))
+			errs[i] = tx.RunInTx(ctx, func(ctx context.Context) error {
+				err := ir.DecideInvitations(ctx, invitationID, model.InvitationStatusApproved)
+				if err != nil {
+					return err
+				}
+				return dr.CreateDecision(ctx, decision)
+			})
+		}()
+	}
+	wg.Wait()
+
+	succeeded := 0
+	for _, err := range errs {
+		if err == nil {
+			succeeded++
+			continue
+		}
+		assert.ErrorIs(t, err, repository.ErrInvalidStatusTransition)
+	}
+	assert.Equal(t, 1, succeeded)
+
+	decisionCount, err := ir.db.NewSelect().Model((*schema.Decision)(nil)).Where("message_id = ?", invitationID).Count(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 1, decisionCount)
+
+	voteCount, err := ir.db.NewSelect().Model((*schema.DecisionVote)(nil)).Where("message_id = ?", invitationID).Count(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 1, voteCount)
+
+	invitations, err := ir.GetInvitations(ctx, invitationID)
+	require.NoError(t, err)
+	for _, invitation := range invitations {
+		assert.Equal(t, model.InvitationStatusApproved, invitation.Status())
+	}
 }
@@ -0,0 +1,142 @@
+package impl
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/traP-jp/members_bot/model"
+	"github.com/traP-jp/members_bot/repository"
+	"github.com/traP-jp/members_bot/repository/impl/schema"
+	"github.com/uptrace/bun"
+)
+
+var _ repository.Removal = &Removal{}
+
+type Removal struct {
+	db *bun.DB
+}
+
+func NewRemoval(db *bun.DB) *Removal {
+	return &Removal{db: db}
+}
+
+// 状態と、その状態に遷移した日時を記録するカラムの対応
+var removalStatusTimeColumns = map[model.RemovalStatus]string{
+	model.RemovalStatusApproved:     "approved_at",
+	model.RemovalStatusRejected:     "rejected_at",
+	model.RemovalStatusRemoved:      "removed_at",
+	model.RemovalStatusRemoveFailed: "remove_failed_at",
+}
+
+func (r *Removal) CreateRemoval(ctx context.Context, removal *model.Removal) error {
+	removalSchema := schema.Removal{
+		MessageID:       removal.MessageID(),
+		GitHubID:        removal.GitHubID(),
+		Status:          string(model.RemovalStatusPending),
+		Reason:          removal.Reason(),
+		OriginChannelID: removal.OriginChannelID(),
+		OriginMessageID: removal.OriginMessageID(),
+	}
+	if requester := removal.Requester(); requester != nil {
+		removalSchema.RequesterID = requester.ID()
+		removalSchema.RequesterName = requester.Name()
+	}
+
+	_, err := r.db.NewInsert().Model(&removalSchema).Exec(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to create removal: %w", err)
+	}
+
+	return nil
+}
+
+func (r *Removal) GetRemoval(ctx context.Context, messageID string) (*model.Removal, error) {
+	var removal schema.Removal
+	err := r.db.NewSelect().Model(&removal).Where("message_id = ?", messageID).Scan(ctx)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, repository.ErrRecordNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get removal: %w", err)
+	}
+
+	return toRemovalModel(&removal), nil
+}
+
+func (r *Removal) GetRemovalsByStatus(ctx context.Context, status model.RemovalStatus) ([]*model.Removal, error) {
+	var removals []schema.Removal
+	err := r.db.NewSelect().Model(&removals).Where("status = ?", status).Order("id").Scan(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get removals: %w", err)
+	}
+
+	result := make([]*model.Removal, 0, len(removals))
+	for _, removal := range removals {
+		result = append(result, toRemovalModel(&removal))
+	}
+
+	return result, nil
+}
+
+// UpdateRemovalStatus は、ctxにトランザクションがある場合は、その中で状態を遷移させる
+func (r *Removal) UpdateRemovalStatus(ctx context.Context, messageID string, status model.RemovalStatus) error {
+	previousStatuses := status.PreviousStatuses()
+	if len(previousStatuses) == 0 {
+		return repository.ErrInvalidStatusTransition
+	}
+
+	return runInTx(ctx, r.db, func(ctx context.Context, tx bun.IDB) error {
+		return updateRemovalStatus(ctx, tx, messageID, status, previousStatuses)
+	})
+}
+
+func updateRemovalStatus(ctx context.Context, db bun.IDB, messageID string, status model.RemovalStatus, previousStatuses []model.RemovalStatus) error {
+	// 遷移元の状態を条件に含めることで、遷移できない状態からの更新を防ぐ
+	q := db.NewUpdate().
+		Model((*schema.Removal)(nil)).
+		Set("status = ?", status).
+		Where("message_id = ?", messageID).
+		Where("status IN (?)", bun.In(previousStatuses))
+	if column, ok := removalStatusTimeColumns[status]; ok {
+		q = q.Set("? = CURRENT_TIMESTAMP", bun.Ident(column))
+	}
+
+	res, err := q.Exec(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to update removal status: %w", err)
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	if affected > 0 {
+		return nil
+	}
+
+	exists, err := db.NewSelect().Model((*schema.Removal)(nil)).Where("message_id = ?", messageID).Exists(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to check removal existence: %w", err)
+	}
+	if !exists {
+		return repository.ErrRecordNotFound
+	}
+
+	return repository.ErrInvalidStatusTransition
+}
+
+func toRemovalModel(removal *schema.Removal) *model.Removal {
+	var requester *model.User
+	if removal.RequesterID != "" {
+		requester = model.NewUser(removal.RequesterID, removal.RequesterName)
+	}
+
+	return model.NewRemoval(removal.MessageID, removal.GitHubID,
+		model.WithRemovalStatus(model.RemovalStatus(removal.Status)),
+		model.WithRemovalReason(removal.Reason),
+		model.WithRemovalOrigin(requester, removal.OriginChannelID, removal.OriginMessageID),
+		model.WithRemovalCreatedAt(removal.CreatedAt),
+	)
+}
@@ -0,0 +1,164 @@
+package impl
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/traP-jp/members_bot/model"
+	"github.com/traP-jp/members_bot/repository"
+	"github.com/traP-jp/members_bot/repository/impl/schema"
+)
+
+func TestCreateRemoval(t *testing.T) {
+	ctx := context.Background()
+
+	t.Cleanup(func() {
+		_, err := testDB.NewTruncateTable().Model(&schema.Removal{}).Exec(ctx)
+		require.NoError(t, err)
+	})
+
+	rr := NewRemoval(testDB)
+
+	messageID := uuid.NewString()
+	requester := model.NewUser(uuid.NewString(), "requester")
+	removal := model.NewRemoval(messageID, "ikura-hamu",
+		model.WithRemovalReason("卒業"),
+		model.WithRemovalOrigin(requester, "origin_channel_id", "origin_message_id"))
+
+	err := rr.CreateRemoval(ctx, removal)
+	require.NoError(t, err)
+
+	got, err := rr.GetRemoval(ctx, messageID)
+	require.NoError(t, err)
+
+	assert.Equal(t, messageID, got.MessageID())
+	assert.Equal(t, "ikura-hamu", got.GitHubID())
+	assert.Equal(t, model.RemovalStatusPending, got.Status())
+	assert.Equal(t, "卒業", got.Reason())
+	assert.Equal(t, requester, got.Requester())
+	assert.Equal(t, "origin_channel_id", got.OriginChannelID())
+	assert.Equal(t, "origin_message_id", got.OriginMessageID())
+	assert.WithinDuration(t, time.Now(), got.CreatedAt(), 2*time.Second)
+}
+
+func TestGetRemoval(t *testing.T) {
+	rr := NewRemoval(testDB)
+
+	_, err := rr.GetRemoval(context.Background(), uuid.NewString())
+	assert.ErrorIs(t, err, repository.ErrRecordNotFound)
+}
+
+func TestGetRemovalsByStatus(t *testing.T) {
+	ctx := context.Background()
+
+	t.Cleanup(func() {
+		_, err := testDB.NewTruncateTable().Model(&schema.Removal{}).Exec(ctx)
+		require.NoError(t, err)
+	})
+
+	rr := NewRemoval(testDB)
+
+	fixture := []schema.Removal{
+		{MessageID: uuid.NewString(), GitHubID: "github_id1", Status: string(model.RemovalStatusRemoveFailed)},
+		{MessageID: uuid.NewString(), GitHubID: "github_id2", Status: string(model.RemovalStatusRemoved)},
+		{MessageID: uuid.NewString(), GitHubID: "github_id3", Status: string(model.RemovalStatusRemoveFailed)},
+	}
+	_, err := rr.db.NewInsert().Model(&fixture).Exec(ctx)
+	require.NoError(t, err)
+
+	removals, err := rr.GetRemovalsByStatus(ctx, model.RemovalStatusRemoveFailed)
+	require.NoError(t, err)
+
+	require.Len(t, removals, 2)
+	assert.Equal(t, "github_id1", removals[0].GitHubID())
+	assert.Equal(t, "github_id3", removals[1].GitHubID())
+}
+
+func TestUpdateRemovalStatus(t *testing.T) {
+	testCases := map[string]struct {
+		current     model.RemovalStatus
+		next        model.RemovalStatus
+		noRecord    bool
+		expectedErr error
+	}{
+		"承認": {
+			current: model.RemovalStatusPending,
+			next:    model.RemovalStatusApproved,
+		},
+		"外した": {
+			current: model.RemovalStatusApproved,
+			next:    model.RemovalStatusRemoved,
+		},
+		"外せなかったメンバーを外し直す": {
+			current: model.RemovalStatusRemoveFailed,
+			next:    model.RemovalStatusRemoving,
+		},
+		"外し直した": {
+			current: model.RemovalStatusRemoving,
+			next:    model.RemovalStatusRemoved,
+		},
+		"外し直している途中なので、もう一度は外し直せない": {
+			current:     model.RemovalStatusRemoving,
+			next:        model.RemovalStatusRemoving,
+			expectedErr: repository.ErrInvalidStatusTransition,
+		},
+		"判定済みなので却下できない": {
+			current:     model.RemovalStatusApproved,
+			next:        model.RemovalStatusRejected,
+			expectedErr: repository.ErrInvalidStatusTransition,
+		},
+		"承認されていないので外せない": {
+			current:     model.RemovalStatusPending,
+			next:        model.RemovalStatusRemoved,
+			expectedErr: repository.ErrInvalidStatusTransition,
+		},
+		"申請がない": {
+			next:        model.RemovalStatusApproved,
+			noRecord:    true,
+			expectedErr: repository.ErrRecordNotFound,
+		},
+	}
+
+	for name, test := range testCases {
+		t.Run(name, func(t *testing.T) {
+			ctx := context.Background()
+
+			t.Cleanup(func() {
+				_, err := testDB.NewTruncateTable().Model(&schema.Removal{}).Exec(ctx)
+				require.NoError(t, err)
+			})
+
+			rr := NewRemoval(testDB)
+
+			messageID := uuid.NewString()
+			if !test.noRecord {
+				_, err := rr.db.NewInsert().Model(&schema.Removal{
+					MessageID: messageID,
+					GitHubID:  "github_id",
+					Status:    string(test.current),
+				}).Exec(ctx)
+				require.NoError(t, err)
+			}
+
+			err := rr.UpdateRemovalStatus(ctx, messageID, test.next)
+			assert.ErrorIs(t, err, test.expectedErr)
+
+			if test.noRecord {
+				return
+			}
+
+			removal, err := rr.GetRemoval(ctx, messageID)
+			require.NoError(t, err)
+
+			if test.expectedErr != nil {
+				assert.Equal(t, test.current, removal.Status())
+			} else {
+				assert.Equal(t, test.next, removal.Status())
+			}
+		})
+	}
+}
@@ -0,0 +1,9 @@
+package schema
+
+import (
+	"github.com/traP-jp/members_bot/repository/impl/schema/internal/migrate"
+)
+
+type Decision migrate.InvitationDecisionV2
+
+type DecisionVote migrate.InvitationDecisionVoteV1
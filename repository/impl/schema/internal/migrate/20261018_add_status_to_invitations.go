@@ -0,0 +1,67 @@
+package migrate
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/uptrace/bun"
+	"github.com/uptrace/bun/migrate"
+)
+
+type InvitationV3 struct {
+	bun.BaseModel `bun:"table:invitations"`
+	ID            int `bun:",pk,autoincrement"`
+	MessageID     string
+	TraqID        string
+	GitHubID      string
+	Status        string    `bun:",notnull,default:'pending'"`
+	CreatedAt     time.Time `bun:",nullzero,notnull,default:current_timestamp"`
+	ApprovedAt    time.Time `bun:",nullzero"`
+	RejectedAt    time.Time `bun:",nullzero"`
+	SentAt        time.Time `bun:",nullzero"`
+	SendFailedAt  time.Time `bun:",nullzero"`
+	AcceptedAt    time.Time `bun:",nullzero"`
+	ExpiredAt     time.Time `bun:",nullzero"`
+	CancelledAt   time.Time `bun:",nullzero"`
+}
+
+func v3(m *migrate.Migrations) {
+	m.MustRegister(
+		func(ctx context.Context, db *bun.DB) (err error) {
+			// 既存の行は削除されていない = 未判定なので、pendingとする
+			_, err = db.NewRaw(`ALTER TABLE invitations
+				ADD COLUMN status VARCHAR(32) NOT NULL DEFAULT 'pending',
+				ADD COLUMN approved_at DATETIME NULL,
+				ADD COLUMN rejected_at DATETIME NULL,
+				ADD COLUMN sent_at DATETIME NULL,
+				ADD COLUMN send_failed_at DATETIME NULL,
+				ADD COLUMN accepted_at DATETIME NULL,
+				ADD COLUMN expired_at DATETIME NULL,
+				ADD COLUMN cancelled_at DATETIME NULL,
+				ADD INDEX idx_invitations_status (status)`).Exec(ctx)
+			if err != nil {
+				return fmt.Errorf("failed to add columns: %w", err)
+			}
+
+			return nil
+		},
+		func(ctx context.Context, db *bun.DB) (err error) {
+			_, err = db.NewRaw(`ALTER TABLE invitations
+				DROP INDEX idx_invitations_status,
+				DROP COLUMN status,
+				DROP COLUMN approved_at,
+				DROP COLUMN rejected_at,
+				DROP COLUMN sent_at,
+				DROP COLUMN send_failed_at,
+				DROP COLUMN accepted_at,
+				DROP COLUMN expired_at,
+				DROP COLUMN cancelled_at`).Exec(ctx)
+			if err != nil {
+				return fmt.Errorf("failed to drop columns: %w", err)
+			}
+
+			return nil
+		},
+	)
+}
@@ -0,0 +1,81 @@
+package migrate
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/uptrace/bun"
+	"github.com/uptrace/bun/migrate"
+)
+
+type InvitationDecisionV1 struct {
+	bun.BaseModel `bun:"table:invitation_decisions"`
+	ID            int       `bun:",pk,autoincrement"`
+	MessageID     string    `bun:",notnull,unique"`
+	Decision      string    `bun:",notnull"`
+	DecidedAt     time.Time `bun:",nullzero,notnull,default:current_timestamp"`
+}
+
+type InvitationDecisionVoteV1 struct {
+	bun.BaseModel `bun:"table:invitation_decision_votes"`
+	ID            int       `bun:",pk,autoincrement"`
+	MessageID     string    `bun:",notnull"`
+	UserID        string    `bun:",notnull"`
+	StampID       string    `bun:",notnull"`
+	StampedAt     time.Time `bun:",notnull"`
+}
+
+func v4(m *migrate.Migrations) {
+	m.MustRegister(
+		func(ctx context.Context, db *bun.DB) error {
+			return db.RunInTx(ctx, nil, func(ctx context.Context, tx bun.Tx) error {
+				_, err := tx.NewCreateTable().
+					Model(&InvitationDecisionV1{}).
+					Exec(ctx)
+				if err != nil {
+					return fmt.Errorf("failed to create invitation_decisions table: %w", err)
+				}
+
+				_, err = tx.NewCreateTable().
+					Model(&InvitationDecisionVoteV1{}).
+					Exec(ctx)
+				if err != nil {
+					return fmt.Errorf("failed to create invitation_decision_votes table: %w", err)
+				}
+
+				_, err = tx.NewCreateIndex().
+					Model(&InvitationDecisionVoteV1{}).
+					Index("idx_invitation_decision_votes_message_id").
+					Column("message_id").
+					Exec(ctx)
+				if err != nil {
+					return fmt.Errorf("failed to create index: %w", err)
+				}
+
+				return nil
+			})
+		},
+		func(ctx context.Context, db *bun.DB) error {
+			return db.RunInTx(ctx, nil, func(ctx context.Context, tx bun.Tx) error {
+				_, err := tx.NewDropTable().
+					Model(&InvitationDecisionVoteV1{}).
+					IfExists().
+					Exec(ctx)
+				if err != nil {
+					return fmt.Errorf("failed to drop invitation_decision_votes table: %w", err)
+				}
+
+				_, err = tx.NewDropTable().
+					Model(&InvitationDecisionV1{}).
+					IfExists().
+					Exec(ctx)
+				if err != nil {
+					return fmt.Errorf("failed to drop invitation_decisions table: %w", err)
+				}
+
+				return nil
+			})
+		},
+	)
+}
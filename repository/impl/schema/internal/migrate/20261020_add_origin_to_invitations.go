@@ -0,0 +1,61 @@
+package migrate
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/uptrace/bun"
+	"github.com/uptrace/bun/migrate"
+)
+
+type InvitationV4 struct {
+	bun.BaseModel   `bun:"table:invitations"`
+	ID              int `bun:",pk,autoincrement"`
+	MessageID       string
+	TraqID          string
+	GitHubID        string
+	Status          string `bun:",notnull,default:'pending'"`
+	RequesterID     string
+	RequesterName   string
+	OriginChannelID string
+	OriginMessageID string
+	CreatedAt       time.Time `bun:",nullzero,notnull,default:current_timestamp"`
+	ApprovedAt      time.Time `bun:",nullzero"`
+	RejectedAt      time.Time `bun:",nullzero"`
+	SentAt          time.Time `bun:",nullzero"`
+	SendFailedAt    time.Time `bun:",nullzero"`
+	AcceptedAt      time.Time `bun:",nullzero"`
+	ExpiredAt       time.Time `bun:",nullzero"`
+	CancelledAt     time.Time `bun:",nullzero"`
+}
+
+func v5(m *migrate.Migrations) {
+	m.MustRegister(
+		func(ctx context.Context, db *bun.DB) (err error) {
+			// 既存の行は申請者が分からないので空文字列とする
+			_, err = db.NewRaw(`ALTER TABLE invitations
+				ADD COLUMN requester_id VARCHAR(36) NOT NULL DEFAULT '' AFTER status,
+				ADD COLUMN requester_name VARCHAR(32) NOT NULL DEFAULT '' AFTER requester_id,
+				ADD COLUMN origin_channel_id VARCHAR(36) NOT NULL DEFAULT '' AFTER requester_name,
+				ADD COLUMN origin_message_id VARCHAR(36) NOT NULL DEFAULT '' AFTER origin_channel_id`).Exec(ctx)
+			if err != nil {
+				return fmt.Errorf("failed to add columns: %w", err)
+			}
+
+			return nil
+		},
+		func(ctx context.Context, db *bun.DB) (err error) {
+			_, err = db.NewRaw(`ALTER TABLE invitations
+				DROP COLUMN requester_id,
+				DROP COLUMN requester_name,
+				DROP COLUMN origin_channel_id,
+				DROP COLUMN origin_message_id`).Exec(ctx)
+			if err != nil {
+				return fmt.Errorf("failed to drop columns: %w", err)
+			}
+
+			return nil
+		},
+	)
+}
@@ -0,0 +1,54 @@
+package migrate
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/uptrace/bun"
+	"github.com/uptrace/bun/migrate"
+)
+
+type InvitationV5 struct {
+	bun.BaseModel   `bun:"table:invitations"`
+	ID              int `bun:",pk,autoincrement"`
+	MessageID       string
+	TraqID          string
+	GitHubID        string
+	Status          string `bun:",notnull,default:'pending'"`
+	RequesterID     string
+	RequesterName   string
+	OriginChannelID string
+	OriginMessageID string
+	Reason          string
+	CreatedAt       time.Time `bun:",nullzero,notnull,default:current_timestamp"`
+	ApprovedAt      time.Time `bun:",nullzero"`
+	RejectedAt      time.Time `bun:",nullzero"`
+	SentAt          time.Time `bun:",nullzero"`
+	SendFailedAt    time.Time `bun:",nullzero"`
+	AcceptedAt      time.Time `bun:",nullzero"`
+	ExpiredAt       time.Time `bun:",nullzero"`
+	CancelledAt     time.Time `bun:",nullzero"`
+}
+
+func v6(m *migrate.Migrations) {
+	m.MustRegister(
+		func(ctx context.Context, db *bun.DB) (err error) {
+			_, err = db.NewRaw(`ALTER TABLE invitations
+				ADD COLUMN reason TEXT NOT NULL DEFAULT '' AFTER origin_message_id`).Exec(ctx)
+			if err != nil {
+				return fmt.Errorf("failed to add column: %w", err)
+			}
+
+			return nil
+		},
+		func(ctx context.Context, db *bun.DB) (err error) {
+			_, err = db.NewRaw(`ALTER TABLE invitations DROP COLUMN reason`).Exec(ctx)
+			if err != nil {
+				return fmt.Errorf("failed to drop column: %w", err)
+			}
+
+			return nil
+		},
+	)
+}
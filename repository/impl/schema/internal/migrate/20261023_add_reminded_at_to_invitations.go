@@ -0,0 +1,56 @@
+package migrate
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/uptrace/bun"
+	"github.com/uptrace/bun/migrate"
+)
+
+type InvitationV7 struct {
+	bun.BaseModel   `bun:"table:invitations"`
+	ID              int `bun:",pk,autoincrement"`
+	MessageID       string
+	TraqID          string
+	TraqUserID      string
+	GitHubID        string
+	Status          string `bun:",notnull,default:'pending'"`
+	RequesterID     string
+	RequesterName   string
+	OriginChannelID string
+	OriginMessageID string
+	Reason          string
+	RemindedAt      time.Time `bun:",nullzero"`
+	CreatedAt       time.Time `bun:",nullzero,notnull,default:current_timestamp"`
+	ApprovedAt      time.Time `bun:",nullzero"`
+	RejectedAt      time.Time `bun:",nullzero"`
+	SentAt          time.Time `bun:",nullzero"`
+	SendFailedAt    time.Time `bun:",nullzero"`
+	AcceptedAt      time.Time `bun:",nullzero"`
+	ExpiredAt       time.Time `bun:",nullzero"`
+	CancelledAt     time.Time `bun:",nullzero"`
+}
+
+func v8(m *migrate.Migrations) {
+	m.MustRegister(
+		func(ctx context.Context, db *bun.DB) (err error) {
+			_, err = db.NewRaw(`ALTER TABLE invitations
+				ADD COLUMN reminded_at DATETIME NULL AFTER reason`).Exec(ctx)
+			if err != nil {
+				return fmt.Errorf("failed to add column: %w", err)
+			}
+
+			return nil
+		},
+		func(ctx context.Context, db *bun.DB) (err error) {
+			_, err = db.NewRaw(`ALTER TABLE invitations DROP COLUMN reminded_at`).Exec(ctx)
+			if err != nil {
+				return fmt.Errorf("failed to drop column: %w", err)
+			}
+
+			return nil
+		},
+	)
+}
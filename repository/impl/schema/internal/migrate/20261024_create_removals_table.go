@@ -0,0 +1,54 @@
+package migrate
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/uptrace/bun"
+	"github.com/uptrace/bun/migrate"
+)
+
+type RemovalV1 struct {
+	bun.BaseModel   `bun:"table:removals"`
+	ID              int    `bun:",pk,autoincrement"`
+	MessageID       string `bun:",notnull,unique"`
+	GitHubID        string `bun:",notnull"`
+	Status          string `bun:",notnull,default:'pending'"`
+	Reason          string
+	RequesterID     string
+	RequesterName   string
+	OriginChannelID string
+	OriginMessageID string
+	CreatedAt       time.Time `bun:",nullzero,notnull,default:current_timestamp"`
+	ApprovedAt      time.Time `bun:",nullzero"`
+	RejectedAt      time.Time `bun:",nullzero"`
+	RemovedAt       time.Time `bun:",nullzero"`
+	RemoveFailedAt  time.Time `bun:",nullzero"`
+}
+
+func v9(m *migrate.Migrations) {
+	m.MustRegister(
+		func(ctx context.Context, db *bun.DB) error {
+			_, err := db.NewCreateTable().
+				Model(&RemovalV1{}).
+				Exec(ctx)
+			if err != nil {
+				return fmt.Errorf("failed to create removals table: %w", err)
+			}
+
+			return nil
+		},
+		func(ctx context.Context, db *bun.DB) error {
+			_, err := db.NewDropTable().
+				Model(&RemovalV1{}).
+				IfExists().
+				Exec(ctx)
+			if err != nil {
+				return fmt.Errorf("failed to drop removals table: %w", err)
+			}
+
+			return nil
+		},
+	)
+}
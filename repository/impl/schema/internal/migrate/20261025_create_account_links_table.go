@@ -0,0 +1,72 @@
+package migrate
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/uptrace/bun"
+	"github.com/uptrace/bun/migrate"
+)
+
+type AccountLinkV1 struct {
+	bun.BaseModel `bun:"table:account_links"`
+	ID            int    `bun:",pk,autoincrement"`
+	TraqUserID    string `bun:"type:CHAR(36)"`
+	TraqName      string `bun:",notnull"`
+	GitHubUserID  int64
+	GitHubLogin   string    `bun:",notnull,unique"`
+	Source        string    `bun:",notnull"`
+	LinkedAt      time.Time `bun:",nullzero,notnull,default:current_timestamp"`
+}
+
+func v10(m *migrate.Migrations) {
+	m.MustRegister(
+		func(ctx context.Context, db *bun.DB) error {
+			return db.RunInTx(ctx, nil, func(ctx context.Context, tx bun.Tx) error {
+				_, err := tx.NewCreateTable().
+					Model(&AccountLinkV1{}).
+					Exec(ctx)
+				if err != nil {
+					return fmt.Errorf("failed to create account_links table: %w", err)
+				}
+
+				_, err = tx.NewCreateIndex().
+					Model(&AccountLinkV1{}).
+					Index("idx_account_links_traq_name").
+					Column("traq_name").
+					Exec(ctx)
+				if err != nil {
+					return fmt.Errorf("failed to create index: %w", err)
+				}
+
+				// 既に承認された招待から、対応を埋めておく。同じGitHubユーザーは最後に承認されたものを使う
+				_, err = tx.NewRaw(`INSERT INTO account_links (traq_user_id, traq_name, git_hub_user_id, git_hub_login, source, linked_at)
+					SELECT traq_user_id, TRIM(LEADING '@' FROM traq_id), 0, git_hub_id, 'invitation', approved_at
+					FROM invitations
+					WHERE approved_at IS NOT NULL
+					ORDER BY approved_at
+					ON DUPLICATE KEY UPDATE
+						traq_user_id = VALUES(traq_user_id),
+						traq_name = VALUES(traq_name),
+						linked_at = VALUES(linked_at)`).Exec(ctx)
+				if err != nil {
+					return fmt.Errorf("failed to fill account_links: %w", err)
+				}
+
+				return nil
+			})
+		},
+		func(ctx context.Context, db *bun.DB) error {
+			_, err := db.NewDropTable().
+				Model(&AccountLinkV1{}).
+				IfExists().
+				Exec(ctx)
+			if err != nil {
+				return fmt.Errorf("failed to drop account_links table: %w", err)
+			}
+
+			return nil
+		},
+	)
+}
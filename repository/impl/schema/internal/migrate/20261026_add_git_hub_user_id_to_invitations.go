@@ -0,0 +1,57 @@
+package migrate
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/uptrace/bun"
+	"github.com/uptrace/bun/migrate"
+)
+
+type InvitationV8 struct {
+	bun.BaseModel   `bun:"table:invitations"`
+	ID              int `bun:",pk,autoincrement"`
+	MessageID       string
+	TraqID          string
+	TraqUserID      string
+	GitHubID        string
+	GitHubUserID    int64  `bun:",nullzero"`
+	Status          string `bun:",notnull,default:'pending'"`
+	RequesterID     string
+	RequesterName   string
+	OriginChannelID string
+	OriginMessageID string
+	Reason          string
+	RemindedAt      time.Time `bun:",nullzero"`
+	CreatedAt       time.Time `bun:",nullzero,notnull,default:current_timestamp"`
+	ApprovedAt      time.Time `bun:",nullzero"`
+	RejectedAt      time.Time `bun:",nullzero"`
+	SentAt          time.Time `bun:",nullzero"`
+	SendFailedAt    time.Time `bun:",nullzero"`
+	AcceptedAt      time.Time `bun:",nullzero"`
+	ExpiredAt       time.Time `bun:",nullzero"`
+	CancelledAt     time.Time `bun:",nullzero"`
+}
+
+func v11(m *migrate.Migrations) {
+	m.MustRegister(
+		func(ctx context.Context, db *bun.DB) (err error) {
+			_, err = db.NewRaw(`ALTER TABLE invitations
+				ADD COLUMN git_hub_user_id BIGINT NULL AFTER git_hub_id`).Exec(ctx)
+			if err != nil {
+				return fmt.Errorf("failed to add column: %w", err)
+			}
+
+			return nil
+		},
+		func(ctx context.Context, db *bun.DB) (err error) {
+			_, err = db.NewRaw(`ALTER TABLE invitations DROP COLUMN git_hub_user_id`).Exec(ctx)
+			if err != nil {
+				return fmt.Errorf("failed to drop column: %w", err)
+			}
+
+			return nil
+		},
+	)
+}
@@ -0,0 +1,60 @@
+package migrate
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/uptrace/bun"
+	"github.com/uptrace/bun/migrate"
+)
+
+// AccountLinkV2 は、変わることがあるGitHubのユーザー名ではなく、ユーザーIDで対応を一意にする。
+// ユーザーIDが分からない対応はNULLにする
+type AccountLinkV2 struct {
+	bun.BaseModel `bun:"table:account_links"`
+	ID            int       `bun:",pk,autoincrement"`
+	TraqUserID    string    `bun:"type:CHAR(36)"`
+	TraqName      string    `bun:",notnull"`
+	GitHubUserID  int64     `bun:",nullzero,unique"`
+	GitHubLogin   string    `bun:",notnull"`
+	Source        string    `bun:",notnull"`
+	LinkedAt      time.Time `bun:",nullzero,notnull,default:current_timestamp"`
+}
+
+func v13(m *migrate.Migrations) {
+	m.MustRegister(
+		func(ctx context.Context, db *bun.DB) (err error) {
+			_, err = db.NewRaw(`UPDATE account_links SET git_hub_user_id = NULL WHERE git_hub_user_id = 0`).Exec(ctx)
+			if err != nil {
+				return fmt.Errorf("failed to clear unknown GitHub user IDs: %w", err)
+			}
+
+			_, err = db.NewRaw(`ALTER TABLE account_links
+				DROP INDEX git_hub_login,
+				ADD INDEX idx_account_links_git_hub_login (git_hub_login),
+				ADD UNIQUE INDEX idx_account_links_git_hub_user_id (git_hub_user_id)`).Exec(ctx)
+			if err != nil {
+				return fmt.Errorf("failed to change indexes: %w", err)
+			}
+
+			return nil
+		},
+		func(ctx context.Context, db *bun.DB) (err error) {
+			_, err = db.NewRaw(`ALTER TABLE account_links
+				DROP INDEX idx_account_links_git_hub_user_id,
+				DROP INDEX idx_account_links_git_hub_login,
+				ADD UNIQUE INDEX git_hub_login (git_hub_login)`).Exec(ctx)
+			if err != nil {
+				return fmt.Errorf("failed to change indexes: %w", err)
+			}
+
+			_, err = db.NewRaw(`UPDATE account_links SET git_hub_user_id = 0 WHERE git_hub_user_id IS NULL`).Exec(ctx)
+			if err != nil {
+				return fmt.Errorf("failed to restore unknown GitHub user IDs: %w", err)
+			}
+
+			return nil
+		},
+	)
+}
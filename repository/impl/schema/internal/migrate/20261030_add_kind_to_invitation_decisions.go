@@ -0,0 +1,48 @@
+package migrate
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/uptrace/bun"
+	"github.com/uptrace/bun/migrate"
+)
+
+type InvitationDecisionV2 struct {
+	bun.BaseModel `bun:"table:invitation_decisions"`
+	ID            int       `bun:",pk,autoincrement"`
+	MessageID     string    `bun:",notnull,unique"`
+	Kind          string    `bun:",notnull,default:'invitation'"`
+	Decision      string    `bun:",notnull"`
+	DecidedAt     time.Time `bun:",nullzero,notnull,default:current_timestamp"`
+}
+
+func v15(m *migrate.Migrations) {
+	m.MustRegister(
+		func(ctx context.Context, db *bun.DB) (err error) {
+			_, err = db.NewRaw(`ALTER TABLE invitation_decisions
+				ADD COLUMN kind VARCHAR(255) NOT NULL DEFAULT 'invitation' AFTER message_id`).Exec(ctx)
+			if err != nil {
+				return fmt.Errorf("failed to add column: %w", err)
+			}
+
+			// 既に記録したメンバーから外す申請の判定を、招待の判定と分ける
+			_, err = db.NewRaw(`UPDATE invitation_decisions SET kind = 'removal'
+				WHERE message_id IN (SELECT message_id FROM removals)`).Exec(ctx)
+			if err != nil {
+				return fmt.Errorf("failed to set kind: %w", err)
+			}
+
+			return nil
+		},
+		func(ctx context.Context, db *bun.DB) (err error) {
+			_, err = db.NewRaw(`ALTER TABLE invitation_decisions DROP COLUMN kind`).Exec(ctx)
+			if err != nil {
+				return fmt.Errorf("failed to drop column: %w", err)
+			}
+
+			return nil
+		},
+	)
+}
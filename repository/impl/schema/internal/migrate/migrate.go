@@ -12,6 +12,19 @@ import (
 var m = []func(*migrate.Migrations){
 	v1,
 	v2,
+	v3,
+	v4,
+	v5,
+	v6,
+	v7,
+	v8,
+	v9,
+	v10,
+	v11,
+	v12,
+	v13,
+	v14,
+	v15,
 }
 
 func Migrate(db *bun.DB) error {
@@ -4,4 +4,4 @@ import (
 	"github.com/traP-jp/members_bot/repository/impl/schema/internal/migrate"
 )
 
-type Invitation migrate.InvitationV2
+type Invitation migrate.InvitationV10
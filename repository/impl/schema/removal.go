@@ -0,0 +1,7 @@
+package schema
+
+import (
+	"github.com/traP-jp/members_bot/repository/impl/schema/internal/migrate"
+)
+
+type Removal migrate.RemovalV1
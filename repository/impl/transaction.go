@@ -0,0 +1,38 @@
+package impl
+
+import (
+	"context"
+
+	"github.com/traP-jp/members_bot/repository"
+	"github.com/uptrace/bun"
+)
+
+var _ repository.Transaction = &Transaction{}
+
+type Transaction struct {
+	db *bun.DB
+}
+
+func NewTransaction(db *bun.DB) *Transaction {
+	return &Transaction{db: db}
+}
+
+// トランザクションをctxに入れるためのキー
+type txKey struct{}
+
+func (t *Transaction) RunInTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	return runInTx(ctx, t.db, func(ctx context.Context, _ bun.IDB) error {
+		return fn(ctx)
+	})
+}
+
+// runInTx は、ctxにトランザクションがあればその中で、なければ新しいトランザクションを始めてfnを実行する
+func runInTx(ctx context.Context, db *bun.DB, fn func(ctx context.Context, tx bun.IDB) error) error {
+	if tx, ok := ctx.Value(txKey{}).(bun.Tx); ok {
+		return fn(ctx, tx)
+	}
+
+	return db.RunInTx(ctx, nil, func(ctx context.Context, tx bun.Tx) error {
+		return fn(context.WithValue(ctx, txKey{}, tx), tx)
+	})
+}
@@ -4,6 +4,7 @@ package repository
 
 import (
 	"context"
+	"time"
 
 	"github.com/traP-jp/members_bot/model"
 )
@@ -11,6 +12,48 @@ import (
 type Invitation interface {
 	CreateInvitation(ctx context.Context, invitations []*model.Invitation) error
 	GetInvitations(ctx context.Context, invitationID string) ([]*model.Invitation, error)
-	DeleteInvitations(ctx context.Context, invitationID string) error
-	GetAllInvitations(ctx context.Context) ([]*model.Invitation, error)
+	GetInvitationsByStatus(ctx context.Context, statuses ...model.InvitationStatus) ([]*model.Invitation, error)
+	// GetInvitationsCreatedBefore は、状態がstatusで、cutoffより前に申請された招待を返す
+	GetInvitationsCreatedBefore(ctx context.Context, status model.InvitationStatus, cutoff time.Time) ([]*model.Invitation, error)
+	// UpdateInvitationStatus は、invitationIDの招待の状態をstatusに遷移させる。
+	// statusに遷移できない状態の場合は ErrInvalidStatusTransition を返す。
+	UpdateInvitationStatus(ctx context.Context, invitationID string, status model.InvitationStatus) error
+	// UpdateInvitationStatusByGitHubID は、invitationIDの招待のうち、gitHubIDのユーザーの招待の状態をstatusに遷移させる
+	UpdateInvitationStatusByGitHubID(ctx context.Context, invitationID string, gitHubID string, status model.InvitationStatus) error
+	// DecideInvitations は、invitationIDの申請中の招待を、判定結果のstatusに遷移させる。
+	// 同時に呼ばれても、判定されるのは1度だけで、既に判定済みの場合は ErrInvalidStatusTransition を返す。
+	// 判定は Decision.CreateDecision で記録するので、両方を Transaction.RunInTx の中で呼ぶ
+	DecideInvitations(ctx context.Context, invitationID string, status model.InvitationStatus) error
+	// ReleaseStaleInvitations は、before より前から送信中のままの招待と、before より前に承認されたまま送信されていない招待を、
+	// 送信失敗の状態にし、その招待を返す。送信の途中で処理が止まった招待を、送り直せるようにする
+	ReleaseStaleInvitations(ctx context.Context, before time.Time) ([]*model.Invitation, error)
+	// GetLatestGitHubLogins は、招待を記録したGitHubのユーザーIDごとに、最後に申請されたときのユーザー名を返す。
+	// ユーザーIDが記録されていない招待は含まない
+	GetLatestGitHubLogins(ctx context.Context) ([]*GitHubLogin, error)
+	// UpdateInvitationGitHubID は、GitHubのユーザーIDがgitHubUserIDの招待の、GitHubのユーザー名を更新する
+	UpdateInvitationGitHubID(ctx context.Context, gitHubUserID int64, gitHubID string) error
+	UpdateInvitationReason(ctx context.Context, invitationID string, reason string) error
+	UpdateInvitationRemindedAt(ctx context.Context, invitationID string, remindedAt time.Time) error
+	// GetInvitationHistory は、判定済みの招待を判定日時の新しい順に返す
+	GetInvitationHistory(ctx context.Context, query InvitationHistoryQuery) ([]*model.Invitation, error)
+}
+
+// GitHubLogin は、GitHubのユーザーIDと、そのユーザーとして記録しているユーザー名の組
+type GitHubLogin struct {
+	GitHubUserID int64
+	Login        string
+}
+
+// InvitationHistoryQuery は、判定済みの招待を絞り込むための条件。ゼロ値の条件は無視される
+type InvitationHistoryQuery struct {
+	TraqID   string
+	GitHubID string
+	// 状態がStatusesのいずれか
+	Statuses []model.InvitationStatus
+	// 判定日時がSince以降
+	Since time.Time
+	// 判定日時がUntilより前
+	Until  time.Time
+	Limit  int
+	Offset int
 }
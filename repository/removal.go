@@ -0,0 +1,20 @@
+package repository
+
+//go:generate go run github.com/matryer/moq -pkg mock -out mock/${GOFILE} . Removal
+
+import (
+	"context"
+
+	"github.com/traP-jp/members_bot/model"
+)
+
+type Removal interface {
+	CreateRemoval(ctx context.Context, removal *model.Removal) error
+	// GetRemoval は、申請メッセージがmessageIDの申請を返す。ない場合は ErrRecordNotFound を返す
+	GetRemoval(ctx context.Context, messageID string) (*model.Removal, error)
+	// GetRemovalsByStatus は、状態がstatusの申請を、申請された順に返す
+	GetRemovalsByStatus(ctx context.Context, status model.RemovalStatus) ([]*model.Removal, error)
+	// UpdateRemovalStatus は、messageIDの申請の状態をstatusに遷移させる。
+	// statusに遷移できない状態の場合は ErrInvalidStatusTransition を返すので、同時に呼ばれても遷移するのは1度だけになる
+	UpdateRemovalStatus(ctx context.Context, messageID string, status model.RemovalStatus) error
+}
@@ -0,0 +1,12 @@
+package repository
+
+//go:generate go run github.com/matryer/moq -pkg mock -out mock/${GOFILE} . Transaction
+
+import "context"
+
+// Transaction は、複数のリポジトリへの書き込みを、1つのトランザクションで行う
+type Transaction interface {
+	// RunInTx は、fnを1つのトランザクションの中で実行し、fnがエラーを返した場合はロールバックする。
+	// fnに渡されるctxを使ったリポジトリの操作は、全てそのトランザクションの中で行われる
+	RunInTx(ctx context.Context, fn func(ctx context.Context) error) error
+}
@@ -9,8 +9,27 @@ import (
 )
 
 type GitHub interface {
-	SendInvitations(ctx context.Context, invitations []*model.Invitation) error
+	// SendInvitations は、Organizationへの招待を送信し、1人ずつの結果を返す。
+	// 途中で失敗しても、残りの招待の送信を続ける
+	SendInvitations(ctx context.Context, invitations []*model.Invitation) []*model.SendResult
 	CheckUserExist(ctx context.Context, userID string) (bool, error)
+	// GetUserID は、GitHubのユーザー名からユーザーIDを返す。ユーザーIDはユーザー名を変えても変わらない。
+	// ユーザーが存在しない場合は0を返す
+	GetUserID(ctx context.Context, userID string) (int64, error)
+	// GetUserLogin は、GitHubのユーザーIDから現在のユーザー名を返す
+	GetUserLogin(ctx context.Context, gitHubUserID int64) (string, error)
 	CheckUserInOrg(ctx context.Context, userID string) (bool, error)
+	// CheckUserIsMember は、ユーザーがOrganizationのメンバーかを返す。招待を承諾していない場合はfalse
+	CheckUserIsMember(ctx context.Context, userID string) (bool, error)
+	// ListPendingInvitations は、承諾されていないOrganizationへの招待を返す
+	ListPendingInvitations(ctx context.Context) ([]*model.GitHubInvitation, error)
+	// ListFailedInvitations は、期限切れなどで失敗したOrganizationへの招待を返す
+	ListFailedInvitations(ctx context.Context) ([]*model.GitHubInvitation, error)
+	// CancelInvitation は、承諾されていないOrganizationへの招待を取り消す
+	CancelInvitation(ctx context.Context, invitationID int64) error
+	// GetOrgMembership は、ユーザーのOrganizationでの状態と所属しているチームを返す。所属も招待もされていない場合はnil
+	GetOrgMembership(ctx context.Context, userID string) (*model.GitHubMembership, error)
+	// RemoveOrgMember は、ユーザーをOrganizationのメンバーから外す
+	RemoveOrgMember(ctx context.Context, userID string) error
 	OrgName() string
 }
@@ -56,28 +56,72 @@ func NewGitHub(orgName string) (*GitHub, error) {
 	}, nil
 }
 
-func (g *GitHub) SendInvitations(ctx context.Context, invitations []*model.Invitation) error {
-	gitHubUserIDs := make([]int64, 0, len(invitations))
-
+func (g *GitHub) SendInvitations(ctx context.Context, invitations []*model.Invitation) []*model.SendResult {
+	results := make([]*model.SendResult, 0, len(invitations))
 	for _, invitation := range invitations {
+		status, err := g.sendInvitation(ctx, invitation)
+		results = append(results, model.NewSendResult(invitation, status, err))
+	}
+
+	return results
+}
+
+func (g *GitHub) sendInvitation(ctx context.Context, invitation *model.Invitation) (model.SendResultStatus, error) {
+	// 申請時にユーザーIDが分かっている場合は、ユーザー名が変わっていても同じユーザーに送る
+	gitHubUserID := invitation.GitHubUserID()
+	if gitHubUserID == 0 {
 		user, _, err := g.cl.Users.Get(ctx, invitation.GitHubID())
 		if err != nil {
-			return fmt.Errorf("failed to get GitHub user: %w", err)
+			return sendErrorStatus(err), fmt.Errorf("failed to get GitHub user: %w", err)
 		}
+		gitHubUserID = user.GetID()
+	}
+
+	_, _, err := g.cl.Organizations.CreateOrgInvitation(ctx, g.orgName, &github.CreateOrgInvitationOptions{
+		InviteeID: &gitHubUserID,
+	})
+	if err != nil {
+		return sendErrorStatus(err), fmt.Errorf("failed to create GitHub invitation: %w", err)
+	}
+
+	return model.SendResultStatusSent, nil
+}
 
-		gitHubUserIDs = append(gitHubUserIDs, user.GetID())
+// sendErrorStatus は、GitHub APIのエラーから送信結果を判断する
+func sendErrorStatus(err error) model.SendResultStatus {
+	var (
+		rateLimitErr      *github.RateLimitError
+		abuseRateLimitErr *github.AbuseRateLimitError
+		gitHubErr         *github.ErrorResponse
+	)
+	if errors.As(err, &rateLimitErr) || errors.As(err, &abuseRateLimitErr) {
+		return model.SendResultStatusRateLimited
+	}
+	if !errors.As(err, &gitHubErr) {
+		return model.SendResultStatusError
 	}
 
-	for _, gitHubUserID := range gitHubUserIDs {
-		_, _, err := g.cl.Organizations.CreateOrgInvitation(ctx, g.orgName, &github.CreateOrgInvitationOptions{
-			InviteeID: &gitHubUserID,
-		})
-		if err != nil {
-			return fmt.Errorf("failed to create GitHub invitation: %w", err)
+	if gitHubErr.Response.StatusCode == http.StatusNotFound {
+		return model.SendResultStatusUserNotFound
+	}
+
+	// 422のときは、エラーメッセージで理由を判断する
+	messages := []string{strings.ToLower(gitHubErr.Message)}
+	for _, e := range gitHubErr.Errors {
+		messages = append(messages, strings.ToLower(e.Message))
+	}
+	for _, message := range messages {
+		switch {
+		case strings.Contains(message, "rate limit"):
+			return model.SendResultStatusRateLimited
+		case strings.Contains(message, "already a part of"), strings.Contains(message, "already a member"):
+			return model.SendResultStatusAlreadyMember
+		case strings.Contains(message, "already invited"), strings.Contains(message, "already been invited"), strings.Contains(message, "pending invitation"):
+			return model.SendResultStatusAlreadyInvited
 		}
 	}
 
-	return nil
+	return model.SendResultStatusError
 }
 
 func (g *GitHub) CheckUserExist(ctx context.Context, userID string) (bool, error) {
@@ -97,6 +141,32 @@ func (g *GitHub) CheckUserExist(ctx context.Context, userID string) (bool, error
 	return true, nil
 }
 
+func (g *GitHub) GetUserID(ctx context.Context, userID string) (int64, error) {
+	user, _, err := g.cl.Users.Get(ctx, userID)
+	var gitHubErr *github.ErrorResponse
+	if errors.As(err, &gitHubErr) && gitHubErr.Response.StatusCode == 404 {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, fmt.Errorf("failed to get GitHub user: %w", err)
+	}
+
+	if user.GetType() != "User" {
+		return 0, nil
+	}
+
+	return user.GetID(), nil
+}
+
+func (g *GitHub) GetUserLogin(ctx context.Context, gitHubUserID int64) (string, error) {
+	user, _, err := g.cl.Users.GetByID(ctx, gitHubUserID)
+	if err != nil {
+		return "", fmt.Errorf("failed to get GitHub user by ID: %w", err)
+	}
+
+	return user.GetLogin(), nil
+}
+
 func (g *GitHub) CheckUserInOrg(ctx context.Context, userID string) (bool, error) {
 	_, _, err := g.cl.Organizations.GetOrgMembership(ctx, userID, g.orgName)
 	var gitHubErr *github.ErrorResponse
@@ -110,6 +180,177 @@ func (g *GitHub) CheckUserInOrg(ctx context.Context, userID string) (bool, error
 	return true, nil
 }
 
+func (g *GitHub) CheckUserIsMember(ctx context.Context, userID string) (bool, error) {
+	isMember, _, err := g.cl.Organizations.IsMember(ctx, g.orgName, userID)
+	if err != nil {
+		return false, fmt.Errorf("failed to check GitHub org member: %w", err)
+	}
+
+	return isMember, nil
+}
+
+func (g *GitHub) ListPendingInvitations(ctx context.Context) ([]*model.GitHubInvitation, error) {
+	invitations, err := listInvitations(func(opts *github.ListOptions) ([]*github.Invitation, *github.Response, error) {
+		return g.cl.Organizations.ListPendingOrgInvitations(ctx, g.orgName, opts)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list pending GitHub invitations: %w", err)
+	}
+
+	return invitations, nil
+}
+
+func (g *GitHub) ListFailedInvitations(ctx context.Context) ([]*model.GitHubInvitation, error) {
+	invitations, err := listInvitations(func(opts *github.ListOptions) ([]*github.Invitation, *github.Response, error) {
+		return g.cl.Organizations.ListFailedOrgInvitations(ctx, g.orgName, opts)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list failed GitHub invitations: %w", err)
+	}
+
+	return invitations, nil
+}
+
+func (g *GitHub) CancelInvitation(ctx context.Context, invitationID int64) error {
+	// go-githubに招待を取り消すメソッドがないので、直接リクエストする
+	req, err := g.cl.NewRequest(http.MethodDelete, fmt.Sprintf("orgs/%v/invitations/%v", g.orgName, invitationID), nil)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+
+	_, err = g.cl.Do(ctx, req, nil)
+	if err != nil {
+		return fmt.Errorf("failed to cancel GitHub invitation: %w", err)
+	}
+
+	return nil
+}
+
+func (g *GitHub) GetOrgMembership(ctx context.Context, userID string) (*model.GitHubMembership, error) {
+	membership, _, err := g.cl.Organizations.GetOrgMembership(ctx, userID, g.orgName)
+	var gitHubErr *github.ErrorResponse
+	if errors.As(err, &gitHubErr) && gitHubErr.Response.StatusCode == http.StatusNotFound {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get GitHub org membership: %w", err)
+	}
+
+	teams, err := g.userTeams(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	return model.NewGitHubMembership(model.GitHubMembershipState(membership.GetState()), membership.GetRole(), teams), nil
+}
+
+// userTeamsQuery は、ユーザーが所属しているOrganizationのチームを取得するGraphQLのクエリ。
+// REST APIではチームごとに確認する必要があるので、チームが増えても1回のリクエストで済むGraphQLを使う
+const userTeamsQuery = `query($org: String!, $login: String!, $cursor: String) {
+  organization(login: $org) {
+    teams(first: 100, userLogins: [$login], after: $cursor) {
+      nodes { name }
+      pageInfo { hasNextPage endCursor }
+    }
+  }
+}`
+
+type graphQLRequest struct {
+	Query     string         `json:"query"`
+	Variables map[string]any `json:"variables"`
+}
+
+type userTeamsResponse struct {
+	Data struct {
+		Organization struct {
+			Teams struct {
+				Nodes []struct {
+					Name string `json:"name"`
+				} `json:"nodes"`
+				PageInfo struct {
+					HasNextPage bool   `json:"hasNextPage"`
+					EndCursor   string `json:"endCursor"`
+				} `json:"pageInfo"`
+			} `json:"teams"`
+		} `json:"organization"`
+	} `json:"data"`
+	Errors []struct {
+		Message string `json:"message"`
+	} `json:"errors"`
+}
+
+// userTeams は、ユーザーが所属しているチームの名前を返す
+func (g *GitHub) userTeams(ctx context.Context, login string) ([]string, error) {
+	teams := make([]string, 0)
+	var cursor *string
+	for {
+		req, err := g.cl.NewRequest(http.MethodPost, "graphql", graphQLRequest{
+			Query:     userTeamsQuery,
+			Variables: map[string]any{"org": g.orgName, "login": login, "cursor": cursor},
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to create GitHub GraphQL request: %w", err)
+		}
+
+		var res userTeamsResponse
+		_, err = g.cl.Do(ctx, req, &res)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get GitHub teams: %w", err)
+		}
+		if len(res.Errors) > 0 {
+			return nil, fmt.Errorf("failed to get GitHub teams: %s", res.Errors[0].Message)
+		}
+
+		for _, team := range res.Data.Organization.Teams.Nodes {
+			teams = append(teams, team.Name)
+		}
+
+		pageInfo := res.Data.Organization.Teams.PageInfo
+		if !pageInfo.HasNextPage {
+			break
+		}
+		cursor = &pageInfo.EndCursor
+	}
+
+	return teams, nil
+}
+
+func (g *GitHub) RemoveOrgMember(ctx context.Context, userID string) error {
+	_, err := g.cl.Organizations.RemoveMember(ctx, g.orgName, userID)
+	if err != nil {
+		return fmt.Errorf("failed to remove GitHub org member: %w", err)
+	}
+
+	return nil
+}
+
+// listInvitations は、listで全てのページの招待を取得する。メールアドレスへの招待は除く
+func listInvitations(list func(opts *github.ListOptions) ([]*github.Invitation, *github.Response, error)) ([]*model.GitHubInvitation, error) {
+	invitations := make([]*model.GitHubInvitation, 0)
+	opts := &github.ListOptions{PerPage: 100}
+	for {
+		page, res, err := list(opts)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, inv := range page {
+			if inv.GetLogin() == "" {
+				continue
+			}
+			invitations = append(invitations, model.NewGitHubInvitation(
+				inv.GetID(), inv.GetLogin(), inv.GetCreatedAt().Time, inv.GetFailedAt().Time, inv.GetFailedReason()))
+		}
+
+		if res.NextPage == 0 {
+			break
+		}
+		opts.Page = res.NextPage
+	}
+
+	return invitations, nil
+}
+
 func (g *GitHub) OrgName() string {
 	return g.orgName
 }
@@ -0,0 +1,86 @@
+package impl
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/google/go-github/v63/github"
+	"github.com/stretchr/testify/assert"
+	"github.com/traP-jp/members_bot/model"
+)
+
+func TestSendErrorStatus(t *testing.T) {
+	t.Parallel()
+
+	errorResponse := func(statusCode int, message string, errs ...github.Error) error {
+		return &github.ErrorResponse{
+			Response: &http.Response{StatusCode: statusCode},
+			Message:  message,
+			Errors:   errs,
+		}
+	}
+
+	testCases := map[string]struct {
+		err    error
+		status model.SendResultStatus
+	}{
+		"ユーザーが見つからない": {
+			err:    errorResponse(http.StatusNotFound, "Not Found"),
+			status: model.SendResultStatusUserNotFound,
+		},
+		"既にメンバー": {
+			err:    errorResponse(http.StatusUnprocessableEntity, "Validation Failed", github.Error{Message: "Invitee is already a part of this organization"}),
+			status: model.SendResultStatusAlreadyMember,
+		},
+		"既にメンバー(メッセージの大文字小文字が違う)": {
+			err:    errorResponse(http.StatusUnprocessableEntity, "User is Already a Member of this organization"),
+			status: model.SendResultStatusAlreadyMember,
+		},
+		"既に招待されている": {
+			err:    errorResponse(http.StatusUnprocessableEntity, "Validation Failed", github.Error{Message: "Invitee has already been invited"}),
+			status: model.SendResultStatusAlreadyInvited,
+		},
+		"承諾されていない招待がある": {
+			err:    errorResponse(http.StatusUnprocessableEntity, "Validation Failed", github.Error{Message: "Invitee has a pending invitation"}),
+			status: model.SendResultStatusAlreadyInvited,
+		},
+		"招待の上限に達した": {
+			err:    errorResponse(http.StatusUnprocessableEntity, "Over invitation rate limit"),
+			status: model.SendResultStatusRateLimited,
+		},
+		"理由が分からない422": {
+			err:    errorResponse(http.StatusUnprocessableEntity, "Validation Failed", github.Error{Message: "something went wrong"}),
+			status: model.SendResultStatusError,
+		},
+		"その他のHTTPエラー": {
+			err:    errorResponse(http.StatusInternalServerError, "Server Error"),
+			status: model.SendResultStatusError,
+		},
+		"APIの利用制限": {
+			err:    &github.RateLimitError{Response: &http.Response{StatusCode: http.StatusForbidden}, Message: "API rate limit exceeded"},
+			status: model.SendResultStatusRateLimited,
+		},
+		"二次的な利用制限": {
+			err:    &github.AbuseRateLimitError{Response: &http.Response{StatusCode: http.StatusForbidden}, Message: "secondary rate limit"},
+			status: model.SendResultStatusRateLimited,
+		},
+		"ラップされたエラー": {
+			err:    fmt.Errorf("failed to create invitation: %w", errorResponse(http.StatusNotFound, "Not Found")),
+			status: model.SendResultStatusUserNotFound,
+		},
+		"GitHubのエラーではない": {
+			err:    errors.New("connection refused"),
+			status: model.SendResultStatusError,
+		},
+	}
+
+	for name, test := range testCases {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			assert.Equal(t, test.status, sendErrorStatus(test.err))
+		})
+	}
+}
@@ -0,0 +1,116 @@
+package impl
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/google/go-github/v63/github"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUserTeams(t *testing.T) {
+	t.Parallel()
+
+	type page struct {
+		teams       []string
+		hasNextPage bool
+		endCursor   string
+	}
+
+	testCases := map[string]struct {
+		pages   []page
+		errors  []string
+		teams   []string
+		cursors []any
+		isError bool
+	}{
+		"チームに所属している": {
+			pages:   []page{{teams: []string{"SysAd", "Algorithm"}}},
+			teams:   []string{"SysAd", "Algorithm"},
+			cursors: []any{nil},
+		},
+		"チームに所属していない": {
+			pages:   []page{{}},
+			teams:   []string{},
+			cursors: []any{nil},
+		},
+		"複数ページに分かれている": {
+			pages: []page{
+				{teams: []string{"SysAd"}, hasNextPage: true, endCursor: "cursor1"},
+				{teams: []string{"Algorithm"}},
+			},
+			teams:   []string{"SysAd", "Algorithm"},
+			cursors: []any{nil, "cursor1"},
+		},
+		"GraphQLのエラー": {
+			pages:   []page{{}},
+			errors:  []string{"Could not resolve to an Organization"},
+			cursors: []any{nil},
+			isError: true,
+		},
+	}
+
+	for name, test := range testCases {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			var cursors []any
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				assert.Equal(t, http.MethodPost, r.Method)
+				assert.Equal(t, "/graphql", r.URL.Path)
+
+				var req graphQLRequest
+				require.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+				assert.Equal(t, "traP-jp", req.Variables["org"])
+				assert.Equal(t, "ikura-hamu", req.Variables["login"])
+				cursors = append(cursors, req.Variables["cursor"])
+
+				p := test.pages[len(cursors)-1]
+				nodes := make([]map[string]string, 0, len(p.teams))
+				for _, team := range p.teams {
+					nodes = append(nodes, map[string]string{"name": team})
+				}
+				res := map[string]any{
+					"data": map[string]any{
+						"organization": map[string]any{
+							"teams": map[string]any{
+								"nodes":    nodes,
+								"pageInfo": map[string]any{"hasNextPage": p.hasNextPage, "endCursor": p.endCursor},
+							},
+						},
+					},
+				}
+				if len(test.errors) > 0 {
+					errs := make([]map[string]string, 0, len(test.errors))
+					for _, message := range test.errors {
+						errs = append(errs, map[string]string{"message": message})
+					}
+					res["errors"] = errs
+				}
+				require.NoError(t, json.NewEncoder(w).Encode(res))
+			}))
+			t.Cleanup(server.Close)
+
+			cl := github.NewClient(nil)
+			baseURL, err := url.Parse(server.URL + "/")
+			require.NoError(t, err)
+			cl.BaseURL = baseURL
+			g := &GitHub{cl: cl, orgName: "traP-jp"}
+
+			teams, err := g.userTeams(context.Background(), "ikura-hamu")
+
+			assert.Equal(t, test.cursors, cursors)
+			if test.isError {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, test.teams, teams)
+		})
+	}
+}
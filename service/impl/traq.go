@@ -4,10 +4,12 @@ import (
 	"context"
 	"fmt"
 	"io"
+	"net/http"
 
 	"github.com/traP-jp/members_bot/model"
 	"github.com/traP-jp/members_bot/service"
 	"github.com/traPtitech/go-traq"
+	"github.com/traPtitech/traq-ws-bot/payload"
 )
 
 type Traq struct {
@@ -29,6 +31,52 @@ func (t *Traq) GetBotUser(ctx context.Context) (*model.User, error) {
 	return model.NewUser(me.Sub, me.Name), nil
 }
 
+func (t *Traq) GetUser(ctx context.Context, userID string) (*model.User, error) {
+	user, _, err := t.traqClient.UserApi.GetUser(ctx, userID).Execute()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user: %w", err)
+	}
+
+	return model.NewUser(user.Id, user.Name), nil
+}
+
+func (t *Traq) GetTraqUser(ctx context.Context, userID string) (*model.TraqUser, error) {
+	user, res, err := t.traqClient.UserApi.GetUser(ctx, userID).Execute()
+	if res != nil && res.StatusCode == http.StatusNotFound {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user: %w", err)
+	}
+
+	return model.NewTraqUser(user.Id, user.Name, user.DisplayName, user.Bot, traqUserState(user.State)), nil
+}
+
+func (t *Traq) GetTraqUserByName(ctx context.Context, name string) (*model.TraqUser, error) {
+	// 利用できないアカウントだと分かるように、一時停止・凍結されたユーザーも含めて探す
+	users, _, err := t.traqClient.UserApi.GetUsers(ctx).Name(name).IncludeSuspended(true).Execute()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get users: %w", err)
+	}
+	if len(users) == 0 {
+		return nil, nil
+	}
+
+	user := users[0]
+	return model.NewTraqUser(user.Id, user.Name, user.DisplayName, user.Bot, traqUserState(user.State)), nil
+}
+
+func traqUserState(state traq.UserAccountState) model.TraqUserState {
+	switch state {
+	case traq.USERACCOUNTSTATE_active:
+		return model.TraqUserStateActive
+	case traq.USERACCOUNTSTATE_suspended:
+		return model.TraqUserStateSuspended
+	default:
+		return model.TraqUserStateDeactivated
+	}
+}
+
 func (t *Traq) PostMessage(ctx context.Context, channelID, text string) (string, error) {
 	tr := true
 	mes, _, err := t.traqClient.
@@ -38,6 +86,28 @@ func (t *Traq) PostMessage(ctx context.Context, channelID, text string) (string,
 	return mes.Id, err
 }
 
+func (t *Traq) GetMessage(ctx context.Context, messageID string) (string, error) {
+	mes, _, err := t.traqClient.MessageApi.GetMessage(ctx, messageID).Execute()
+	if err != nil {
+		return "", fmt.Errorf("failed to get message: %w", err)
+	}
+
+	return mes.Content, nil
+}
+
+func (t *Traq) EditMessage(ctx context.Context, messageID, text string) error {
+	tr := true
+	_, err := t.traqClient.MessageApi.
+		EditMessage(ctx, messageID).
+		PostMessageRequest(traq.PostMessageRequest{Content: text, Embed: &tr}).
+		Execute()
+	if err != nil {
+		return fmt.Errorf("failed to edit message: %w", err)
+	}
+
+	return nil
+}
+
 func (t *Traq) AddStamp(ctx context.Context, messageID, stampID string, count int) error {
 	for range count {
 		_, err := t.traqClient.MessageApi.AddMessageStamp(ctx, messageID, stampID).Execute()
@@ -48,6 +118,26 @@ func (t *Traq) AddStamp(ctx context.Context, messageID, stampID string, count in
 	return nil
 }
 
+func (t *Traq) GetMessageStamps(ctx context.Context, messageID string) ([]payload.MessageStamp, error) {
+	stamps, _, err := t.traqClient.MessageApi.GetMessageStamps(ctx, messageID).Execute()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get message stamps: %w", err)
+	}
+
+	messageStamps := make([]payload.MessageStamp, 0, len(stamps))
+	for _, stamp := range stamps {
+		messageStamps = append(messageStamps, payload.MessageStamp{
+			StampID:   stamp.StampId,
+			UserID:    stamp.UserId,
+			Count:     int(stamp.Count),
+			CreatedAt: stamp.CreatedAt,
+			UpdatedAt: stamp.UpdatedAt,
+		})
+	}
+
+	return messageStamps, nil
+}
+
 func (t *Traq) GetGroupMemberIDs(ctx context.Context, groupID string) ([]string, error) {
 	members, _, err := t.traqClient.GroupApi.GetUserGroupMembers(ctx, groupID).Execute()
 	if err != nil {
@@ -7,12 +7,22 @@ import (
 	"io"
 
 	"github.com/traP-jp/members_bot/model"
+	"github.com/traPtitech/traq-ws-bot/payload"
 )
 
 type Traq interface {
 	GetBotUser(context.Context) (*model.User, error)
+	GetUser(ctx context.Context, userID string) (*model.User, error)
+	// GetTraqUser は、ユーザーのUUIDからbotかどうかやアカウントの状態を含めたユーザーの情報を返す。ユーザーがいない場合はnil
+	GetTraqUser(ctx context.Context, userID string) (*model.TraqUser, error)
+	// GetTraqUserByName は、@を除いたtraQ IDからユーザーの情報を返す。ユーザーがいない場合はnil
+	GetTraqUserByName(ctx context.Context, name string) (*model.TraqUser, error)
 	PostMessage(ctx context.Context, channelID, text string) (string, error)
+	// GetMessage は、メッセージの本文を返す
+	GetMessage(ctx context.Context, messageID string) (string, error)
+	EditMessage(ctx context.Context, messageID, text string) error
 	AddStamp(ctx context.Context, messageID, stampID string, count int) error
+	GetMessageStamps(ctx context.Context, messageID string) ([]payload.MessageStamp, error)
 	GetGroupMemberIDs(ctx context.Context, groupID string) ([]string, error)
 	UpdateUserBio(ctx context.Context, bio string) error
 